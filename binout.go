@@ -3,13 +3,21 @@ package dynareadout
 /*
 #cgo CFLAGS: -ansi
 #include "dynareadout/src/binout.h"
+#include "dynareadout/src/binout_defines.h"
+
+static const char *binout_file_name(const binout_file *bin_file, size_t index) {
+  return bin_file->file_states[index].file_name;
+}
 */
 import "C"
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"math"
+	"runtime"
+	"strings"
+	"sync"
 	"unsafe"
 )
 
@@ -52,39 +60,222 @@ func carrToSlice[Tc cType, Ts goType](carr *Tc, csize C.size_t) []Ts {
 	return slice
 }
 
+// Binout is an opened binout family. Copies of a Binout share the opened
+// files, so closing or refreshing one of them affects all of them. A Binout
+// can be used from multiple goroutines, but the calls are executed one after
+// another.
 type Binout struct {
+	h        *binoutHandle
+	fileName string
+}
+
+// binoutHandle is shared by all copies of a Binout. Every call into the C
+// library changes the handle (error string, file positions, ...), so all of
+// them are serialized with mtx.
+type binoutHandle struct {
+	mtx    sync.Mutex
 	handle C.binout_file
+	closed bool
+}
+
+// BinoutFileErrorKind categorizes a BinoutFileError
+type BinoutFileErrorKind uint8
+
+const (
+	BinoutFileErrorOther     BinoutFileErrorKind = C.BINOUT_FILE_ERROR_OTHER
+	BinoutFileErrorNotFound  BinoutFileErrorKind = C.BINOUT_FILE_ERROR_NOT_FOUND
+	BinoutFileErrorIO        BinoutFileErrorKind = C.BINOUT_FILE_ERROR_IO
+	BinoutFileErrorHeader    BinoutFileErrorKind = C.BINOUT_FILE_ERROR_HEADER
+	BinoutFileErrorTruncated BinoutFileErrorKind = C.BINOUT_FILE_ERROR_TRUNCATED
+	BinoutFileErrorRecord    BinoutFileErrorKind = C.BINOUT_FILE_ERROR_RECORD
+)
+
+// BinoutFileError is the reason why one file of a binout family failed to open
+// or to parse (e.g. a bad header, an unsupported endianess or a truncated
+// record)
+type BinoutFileError struct {
+	FileName string
+	Kind     BinoutFileErrorKind
+	Message  string
+}
+
+// BinoutOpenError is returned by BinoutOpen if at least one file failed. If
+// Fatal is false the files which did not fail have been opened and the binout
+// can still be used. Files which failed while parsing their records stay open,
+// so that the records in front of the failed one can be read.
+type BinoutOpenError struct {
+	FileErrors []BinoutFileError
+	Fatal      bool
 }
 
 func BinoutOpen(fileName string) (bin_file Binout, err error) {
 	fileNameC := C.CString(fileName)
 
-	bin_file.handle = C.binout_open(fileNameC)
+	bin_file.h = &binoutHandle{handle: C.binout_open(fileNameC)}
+	bin_file.fileName = fileName
 	C.free(unsafe.Pointer(fileNameC))
+	setLeakFinalizer(bin_file.h, fmt.Sprintf("Binout \"%s\"", fileName), (*binoutHandle).close)
+
+	err = bin_file.openError()
+	return
+}
 
-	openErrorC := C.binout_open_error(&bin_file.handle)
-	if openErrorC != nil {
-		err = errors.New(C.GoString(openErrorC))
-		C.free(unsafe.Pointer(openErrorC))
+// Refresh parses the records which have been appended to the files since they
+// have been opened (or since the last Refresh) and opens files matching the
+// file name given to BinoutOpen which did not exist before. This way
+// GetNumTimesteps and the timed reads grow while LS-Dyna is still writing the
+// binout. Incomplete records at the end of a file are not an error, they are
+// picked up by the next Refresh. The returned error only contains the errors
+// of this refresh.
+func (bin_file Binout) Refresh() error {
+	handle, err := bin_file.lock()
+	if err != nil {
+		return err
 	}
+	defer bin_file.unlock()
 
-	return
+	fileNameC := C.CString(bin_file.fileName)
+	C.binout_refresh(handle, fileNameC)
+	C.free(unsafe.Pointer(fileNameC))
+
+	return bin_file.openError()
+}
+
+// openError returns the file errors of the last open or refresh as a
+// *BinoutOpenError or nil if there are none
+func (bin_file Binout) openError() error {
+	handle := &bin_file.h.handle
+	if handle.num_file_errors == 0 {
+		return nil
+	}
+
+	openErr := &BinoutOpenError{
+		FileErrors: make([]BinoutFileError, handle.num_file_errors),
+		Fatal:      handle.num_files == 0,
+	}
+
+	for i := range openErr.FileErrors {
+		fileErrorC := (*C.binout_file_error)(unsafe.Pointer(uintptr(unsafe.Pointer(handle.file_errors)) + uintptr(i)*unsafe.Sizeof(*handle.file_errors)))
+		openErr.FileErrors[i] = BinoutFileError{
+			FileName: C.GoString(fileErrorC.file_name),
+			Kind:     BinoutFileErrorKind(fileErrorC.kind),
+			Message:  C.GoString(fileErrorC.message),
+		}
+	}
+
+	return openErr
 }
 
+// FileNames returns the names of all files of the family which have been
+// opened
+func (bin_file Binout) FileNames() []string {
+	handle, err := bin_file.lock()
+	if err != nil {
+		return []string{}
+	}
+	defer bin_file.unlock()
+
+	return binoutFileNames(handle)
+}
+
+func binoutFileNames(handle *C.binout_file) []string {
+	fileNames := make([]string, 0, handle.num_files)
+	for i := C.size_t(0); i < handle.num_files; i++ {
+		if fileNameC := C.binout_file_name(handle, i); fileNameC != nil {
+			fileNames = append(fileNames, C.GoString(fileNameC))
+		}
+	}
+	return fileNames
+}
+
+func (e BinoutFileError) Error() string {
+	if e.FileName == "" {
+		return e.Message
+	}
+	return e.FileName + ": " + e.Message
+}
+
+// Error returns all file errors separated by new lines
+func (e *BinoutOpenError) Error() string {
+	messages := make([]string, len(e.FileErrors))
+	for i, fileErr := range e.FileErrors {
+		messages[i] = fileErr.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns the file errors so that they can be inspected with errors.As
+func (e *BinoutOpenError) Unwrap() []error {
+	errs := make([]error, len(e.FileErrors))
+	for i, fileErr := range e.FileErrors {
+		errs[i] = fileErr
+	}
+	return errs
+}
+
+// Is reports whether one of the file errors matches target
+func (e *BinoutOpenError) Is(target error) bool {
+	return isAny(e.Unwrap(), target)
+}
+
+// As finds the first file error which matches target
+func (e *BinoutOpenError) As(target any) bool {
+	return asAny(e.Unwrap(), target)
+}
+
+// Close closes the files of bin_file and all of its copies. It can be called
+// multiple times. All other methods return ErrClosed afterwards.
 func (bin_file Binout) Close() {
-	C.binout_close(&bin_file.handle)
+	if bin_file.h != nil {
+		bin_file.h.close()
+	}
+}
+
+func (h *binoutHandle) close() {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	if !h.closed {
+		C.binout_close(&h.handle)
+		h.closed = true
+	}
+	runtime.SetFinalizer(h, nil)
+}
+
+// lock locks bin_file and returns the C handle or ErrClosed if bin_file has
+// already been closed. unlock needs to be called if no error is returned.
+func (bin_file Binout) lock() (*C.binout_file, error) {
+	if bin_file.h == nil {
+		return nil, ErrClosed
+	}
+
+	bin_file.h.mtx.Lock()
+	if bin_file.h.closed {
+		bin_file.h.mtx.Unlock()
+		return nil, ErrClosed
+	}
+	return &bin_file.h.handle, nil
+}
+
+func (bin_file Binout) unlock() {
+	bin_file.h.mtx.Unlock()
 }
 
 func (bin_file Binout) ReadInt8(path string) ([]int8, error) {
+	handle, err := bin_file.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer bin_file.unlock()
+
 	pathC := C.CString(path)
 
 	var dataSize C.size_t
-	dataC := C.binout_read_i8(&bin_file.handle, pathC, &dataSize)
+	dataC := C.binout_read_i8(handle, pathC, &dataSize)
 	C.free(unsafe.Pointer(pathC))
 
-	if bin_file.handle.error_string != nil {
-		err := errors.New(C.GoString(bin_file.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, binoutError(handle)
 	}
 
 	slice := carrToSlice[C.int8_t, int8](dataC, dataSize)
@@ -93,15 +284,20 @@ func (bin_file Binout) ReadInt8(path string) ([]int8, error) {
 }
 
 func (bin_file Binout) ReadInt16(path string) ([]int16, error) {
+	handle, err := bin_file.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer bin_file.unlock()
+
 	pathC := C.CString(path)
 
 	var dataSize C.size_t
-	dataC := C.binout_read_i16(&bin_file.handle, pathC, &dataSize)
+	dataC := C.binout_read_i16(handle, pathC, &dataSize)
 	C.free(unsafe.Pointer(pathC))
 
-	if bin_file.handle.error_string != nil {
-		err := errors.New(C.GoString(bin_file.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, binoutError(handle)
 	}
 
 	slice := carrToSlice[C.int16_t, int16](dataC, dataSize)
@@ -110,15 +306,20 @@ func (bin_file Binout) ReadInt16(path string) ([]int16, error) {
 }
 
 func (bin_file Binout) ReadInt32(path string) ([]int32, error) {
+	handle, err := bin_file.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer bin_file.unlock()
+
 	pathC := C.CString(path)
 
 	var dataSize C.size_t
-	dataC := C.binout_read_i32(&bin_file.handle, pathC, &dataSize)
+	dataC := C.binout_read_i32(handle, pathC, &dataSize)
 	C.free(unsafe.Pointer(pathC))
 
-	if bin_file.handle.error_string != nil {
-		err := errors.New(C.GoString(bin_file.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, binoutError(handle)
 	}
 
 	slice := carrToSlice[C.int32_t, int32](dataC, dataSize)
@@ -127,15 +328,20 @@ func (bin_file Binout) ReadInt32(path string) ([]int32, error) {
 }
 
 func (bin_file Binout) ReadInt64(path string) ([]int64, error) {
+	handle, err := bin_file.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer bin_file.unlock()
+
 	pathC := C.CString(path)
 
 	var dataSize C.size_t
-	dataC := C.binout_read_i64(&bin_file.handle, pathC, &dataSize)
+	dataC := C.binout_read_i64(handle, pathC, &dataSize)
 	C.free(unsafe.Pointer(pathC))
 
-	if bin_file.handle.error_string != nil {
-		err := errors.New(C.GoString(bin_file.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, binoutError(handle)
 	}
 
 	slice := carrToSlice[C.int64_t, int64](dataC, dataSize)
@@ -144,15 +350,20 @@ func (bin_file Binout) ReadInt64(path string) ([]int64, error) {
 }
 
 func (bin_file Binout) ReadUint8(path string) ([]uint8, error) {
+	handle, err := bin_file.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer bin_file.unlock()
+
 	pathC := C.CString(path)
 
 	var dataSize C.size_t
-	dataC := C.binout_read_u8(&bin_file.handle, pathC, &dataSize)
+	dataC := C.binout_read_u8(handle, pathC, &dataSize)
 	C.free(unsafe.Pointer(pathC))
 
-	if bin_file.handle.error_string != nil {
-		err := errors.New(C.GoString(bin_file.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, binoutError(handle)
 	}
 
 	slice := carrToSlice[C.uint8_t, uint8](dataC, dataSize)
@@ -161,15 +372,20 @@ func (bin_file Binout) ReadUint8(path string) ([]uint8, error) {
 }
 
 func (bin_file Binout) ReadUint16(path string) ([]uint16, error) {
+	handle, err := bin_file.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer bin_file.unlock()
+
 	pathC := C.CString(path)
 
 	var dataSize C.size_t
-	dataC := C.binout_read_u16(&bin_file.handle, pathC, &dataSize)
+	dataC := C.binout_read_u16(handle, pathC, &dataSize)
 	C.free(unsafe.Pointer(pathC))
 
-	if bin_file.handle.error_string != nil {
-		err := errors.New(C.GoString(bin_file.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, binoutError(handle)
 	}
 
 	slice := carrToSlice[C.uint16_t, uint16](dataC, dataSize)
@@ -178,15 +394,20 @@ func (bin_file Binout) ReadUint16(path string) ([]uint16, error) {
 }
 
 func (bin_file Binout) ReadUint32(path string) ([]uint32, error) {
+	handle, err := bin_file.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer bin_file.unlock()
+
 	pathC := C.CString(path)
 
 	var dataSize C.size_t
-	dataC := C.binout_read_u32(&bin_file.handle, pathC, &dataSize)
+	dataC := C.binout_read_u32(handle, pathC, &dataSize)
 	C.free(unsafe.Pointer(pathC))
 
-	if bin_file.handle.error_string != nil {
-		err := errors.New(C.GoString(bin_file.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, binoutError(handle)
 	}
 
 	slice := carrToSlice[C.uint32_t, uint32](dataC, dataSize)
@@ -195,15 +416,20 @@ func (bin_file Binout) ReadUint32(path string) ([]uint32, error) {
 }
 
 func (bin_file Binout) ReadUint64(path string) ([]uint64, error) {
+	handle, err := bin_file.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer bin_file.unlock()
+
 	pathC := C.CString(path)
 
 	var dataSize C.size_t
-	dataC := C.binout_read_u64(&bin_file.handle, pathC, &dataSize)
+	dataC := C.binout_read_u64(handle, pathC, &dataSize)
 	C.free(unsafe.Pointer(pathC))
 
-	if bin_file.handle.error_string != nil {
-		err := errors.New(C.GoString(bin_file.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, binoutError(handle)
 	}
 
 	slice := carrToSlice[C.uint64_t, uint64](dataC, dataSize)
@@ -212,15 +438,20 @@ func (bin_file Binout) ReadUint64(path string) ([]uint64, error) {
 }
 
 func (bin_file Binout) ReadFloat32(path string) ([]float32, error) {
+	handle, err := bin_file.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer bin_file.unlock()
+
 	pathC := C.CString(path)
 
 	var dataSize C.size_t
-	dataC := C.binout_read_f32(&bin_file.handle, pathC, &dataSize)
+	dataC := C.binout_read_f32(handle, pathC, &dataSize)
 	C.free(unsafe.Pointer(pathC))
 
-	if bin_file.handle.error_string != nil {
-		err := errors.New(C.GoString(bin_file.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, binoutError(handle)
 	}
 
 	slice := carrToSlice[C.float, float32](dataC, dataSize)
@@ -229,15 +460,20 @@ func (bin_file Binout) ReadFloat32(path string) ([]float32, error) {
 }
 
 func (bin_file Binout) ReadFloat64(path string) ([]float64, error) {
+	handle, err := bin_file.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer bin_file.unlock()
+
 	pathC := C.CString(path)
 
 	var dataSize C.size_t
-	dataC := C.binout_read_f64(&bin_file.handle, pathC, &dataSize)
+	dataC := C.binout_read_f64(handle, pathC, &dataSize)
 	C.free(unsafe.Pointer(pathC))
 
-	if bin_file.handle.error_string != nil {
-		err := errors.New(C.GoString(bin_file.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, binoutError(handle)
 	}
 
 	slice := carrToSlice[C.double, float64](dataC, dataSize)
@@ -246,26 +482,31 @@ func (bin_file Binout) ReadFloat64(path string) ([]float64, error) {
 }
 
 func (bin_file Binout) ReadString(path string) (string, error) {
-	typeID := bin_file.GetTypeID(path)
+	handle, err := bin_file.lock()
+	if err != nil {
+		return "", err
+	}
+	defer bin_file.unlock()
 
 	var dataC unsafe.Pointer
 	var dataSize C.size_t
 	pathC := C.CString(path)
 	defer C.free(unsafe.Pointer(pathC))
 
+	typeID := uint64(C.binout_get_type_id(handle, pathC))
+
 	switch typeID {
 	case BinoutTypeInt8:
-		dataC = unsafe.Pointer(C.binout_read_i8(&bin_file.handle, pathC, &dataSize))
+		dataC = unsafe.Pointer(C.binout_read_i8(handle, pathC, &dataSize))
 	case BinoutTypeUint8:
-		dataC = unsafe.Pointer(C.binout_read_u8(&bin_file.handle, pathC, &dataSize))
+		dataC = unsafe.Pointer(C.binout_read_u8(handle, pathC, &dataSize))
 	default:
-		typeName := C.GoString(C._binout_get_type_name(C.uint64_t(typeID)))
-		return "", fmt.Errorf("Type \"%s\" can not be converted to a string", typeName)
+		typeName := BinoutTypeName(typeID)
+		return "", newKindError(ErrTypeMismatch, "Type \"%s\" can not be converted to a string", typeName)
 	}
 
-	if bin_file.handle.error_string != nil {
-		err := errors.New(C.GoString(bin_file.handle.error_string))
-		return "", err
+	if handle.error_string != nil {
+		return "", binoutError(handle)
 	}
 
 	str := C.GoStringN((*C.char)(dataC), C.int(dataSize))
@@ -275,16 +516,21 @@ func (bin_file Binout) ReadString(path string) (string, error) {
 }
 
 func (bin_file Binout) ReadTimedFloat32(path string) ([][]float32, error) {
+	handle, err := bin_file.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer bin_file.unlock()
+
 	var numValues C.size_t
 	var numTimesteps C.size_t
 	pathC := C.CString(path)
 
-	dataC := C.binout_read_timed_f32(&bin_file.handle, pathC, &numValues, &numTimesteps)
+	dataC := C.binout_read_timed_f32(handle, pathC, &numValues, &numTimesteps)
 	C.free(unsafe.Pointer(pathC))
 
-	if bin_file.handle.error_string != nil {
-		err := errors.New(C.GoString(bin_file.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, binoutError(handle)
 	}
 
 	data := make([][]float32, numTimesteps)
@@ -300,16 +546,21 @@ func (bin_file Binout) ReadTimedFloat32(path string) ([][]float32, error) {
 }
 
 func (bin_file Binout) ReadTimedFloat64(path string) ([][]float64, error) {
+	handle, err := bin_file.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer bin_file.unlock()
+
 	var numValues C.size_t
 	var numTimesteps C.size_t
 	pathC := C.CString(path)
 
-	dataC := C.binout_read_timed_f64(&bin_file.handle, pathC, &numValues, &numTimesteps)
+	dataC := C.binout_read_timed_f64(handle, pathC, &numValues, &numTimesteps)
 	C.free(unsafe.Pointer(pathC))
 
-	if bin_file.handle.error_string != nil {
-		err := errors.New(C.GoString(bin_file.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, binoutError(handle)
 	}
 
 	data := make([][]float64, numTimesteps)
@@ -324,22 +575,85 @@ func (bin_file Binout) ReadTimedFloat64(path string) ([][]float64, error) {
 	return data, nil
 }
 
+// ReadTimedFloat32Context is the same as ReadTimedFloat32, but reads the
+// timesteps one after another and stops as soon as ctx is done
+func (bin_file Binout) ReadTimedFloat32Context(ctx context.Context, path string) ([][]float32, error) {
+	return readTimedContext(ctx, bin_file, path, bin_file.ReadFloat32)
+}
+
+// ReadTimedFloat64Context is the same as ReadTimedFloat64, but reads the
+// timesteps one after another and stops as soon as ctx is done
+func (bin_file Binout) ReadTimedFloat64Context(ctx context.Context, path string) ([][]float64, error) {
+	return readTimedContext(ctx, bin_file, path, bin_file.ReadFloat64)
+}
+
+func readTimedContext[T goType](ctx context.Context, bin_file Binout, path string, read func(string) ([]T, error)) ([][]T, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	index := strings.LastIndexByte(path, '/')
+	if index == -1 {
+		return nil, fmt.Errorf("The path \"%s\" does not contain a folder", path)
+	}
+	folder, variable := path[:index], path[index+1:]
+	if folder == "" {
+		folder = "/"
+	}
+
+	var data [][]T
+	for _, child := range bin_file.GetChildren(folder) {
+		if !isBinoutDString(child) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		values, err := read(folder + "/" + child + "/" + variable)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, values)
+	}
+
+	if len(data) == 0 {
+		return nil, newKindError(ErrPathNotFound, "The timed variable \"%s\" does not exist", path)
+	}
+
+	return data, nil
+}
+
 func (bin_file Binout) GetTypeID(path string) uint64 {
+	handle, err := bin_file.lock()
+	if err != nil {
+		return BinoutTypeInvalid
+	}
+	defer bin_file.unlock()
+
 	pathC := C.CString(path)
 
-	typeID := C.binout_get_type_id(&bin_file.handle, pathC)
+	typeID := C.binout_get_type_id(handle, pathC)
 	C.free(unsafe.Pointer(pathC))
 
 	return uint64(typeID)
 }
 
 func (bin_file Binout) GetChildren(path string) []string {
+	handle, err := bin_file.lock()
+	if err != nil {
+		return []string{}
+	}
+	defer bin_file.unlock()
+
 	pathC := C.CString(path)
 
 	var numChildren C.size_t
-	childrenC := C.binout_get_children(&bin_file.handle, pathC, &numChildren)
+	childrenC := C.binout_get_children(handle, pathC, &numChildren)
+	C.free(unsafe.Pointer(pathC))
 
-	if numChildren == 0 {
+	// childrenC is NULL if the path does not exist
+	if childrenC == nil || numChildren == 0 {
 		return []string{}
 	}
 
@@ -354,35 +668,53 @@ func (bin_file Binout) GetChildren(path string) []string {
 }
 
 func (bin_file Binout) VariableExists(path string) bool {
+	handle, err := bin_file.lock()
+	if err != nil {
+		return false
+	}
+	defer bin_file.unlock()
+
 	pathC := C.CString(path)
 	defer C.free(unsafe.Pointer(pathC))
 
-	return C.binout_variable_exists(&bin_file.handle, pathC) != 0
+	return C.binout_variable_exists(handle, pathC) != 0
 }
 
 func (bin_file Binout) GetNumTimesteps(path string) (uint64, error) {
+	handle, err := bin_file.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer bin_file.unlock()
+
 	pathC := C.CString(path)
 
-	timesteps := C.binout_get_num_timesteps(&bin_file.handle, pathC)
+	timesteps := C.binout_get_num_timesteps(handle, pathC)
 	C.free(unsafe.Pointer(pathC))
 
 	if timesteps == math.MaxUint64 {
-		return 0, errors.New("The path does not exist or contains files")
+		return 0, newKindError(ErrPathNotFound, "The path does not exist or contains files")
 	}
 
 	return uint64(timesteps), nil
 }
 
 func (bin_file Binout) SimplePathToReal(simple string) (string, int, bool, error) {
+	handle, err := bin_file.lock()
+	if err != nil {
+		return "", 0, false, err
+	}
+	defer bin_file.unlock()
+
 	var typeID C.uint8_t
 	var timed C.int
 	simpleC := C.CString(simple)
 
-	realC := C.binout_simple_path_to_real(&bin_file.handle, simpleC, &typeID, &timed)
+	realC := C.binout_simple_path_to_real(handle, simpleC, &typeID, &timed)
 	C.free(unsafe.Pointer(simpleC))
 
 	if realC == nil {
-		err := fmt.Errorf("The simple path \"%s\" does not exist", simple)
+		err = newKindError(ErrPathNotFound, "The simple path \"%s\" does not exist", simple)
 		return "", 0, false, err
 	}
 
@@ -391,3 +723,8 @@ func (bin_file Binout) SimplePathToReal(simple string) (string, int, bool, error
 
 	return real, int(typeID), timed != 0, nil
 }
+
+// BinoutTypeName returns the name of a type id (e.g. "FLOAT32")
+func BinoutTypeName(typeID uint64) string {
+	return C.GoString(C._binout_get_type_name(C.uint64_t(typeID)))
+}
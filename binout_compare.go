@@ -0,0 +1,353 @@
+package dynareadout
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strings"
+)
+
+// The kinds of differences found by CompareBinouts
+const (
+	// The path only exists in the first binout
+	BinoutDiffMissing = iota
+	// The path only exists in the second binout
+	BinoutDiffExtra
+	// The variables have different types or one path is a folder and the other
+	// a variable
+	BinoutDiffType
+	// The databases have a different number of dxxxxxx folders
+	BinoutDiffTimesteps
+	// The variables have a different number of values
+	BinoutDiffLength
+	// At least one value deviates more than the tolerances allow
+	BinoutDiffValues
+)
+
+// BinoutCompareOptions holds the tolerances of CompareBinouts. Two values a and
+// b are considered equal if |a-b| <= AbsoluteTolerance + RelativeTolerance *
+// max(|a|, |b|). The zero value only accepts exactly equal values.
+type BinoutCompareOptions struct {
+	AbsoluteTolerance float64
+	RelativeTolerance float64
+}
+
+// BinoutDifference describes one difference between two binouts. Timed
+// variables are reported once per database under their path without the
+// dxxxxxx folder (e.g. "/nodout/x_displacement").
+type BinoutDifference struct {
+	Kind int
+	Path string
+
+	// Set for BinoutDiffType
+	TypeA, TypeB uint64
+	// Set for BinoutDiffTimesteps
+	TimestepsA, TimestepsB int
+	// Set for BinoutDiffLength
+	LengthA, LengthB int
+
+	// Set for BinoutDiffValues. Timestep and Index locate the value with the
+	// largest absolute deviation. Timestep is -1 for variables which are not
+	// timed.
+	NumExceeded          int
+	MaxAbsoluteDeviation float64
+	MaxRelativeDeviation float64
+	Timestep             int
+	Index                int
+	ValueA, ValueB       float64
+}
+
+// BinoutCompareReport is the result of CompareBinouts
+type BinoutCompareReport struct {
+	Differences []BinoutDifference
+	// The number of variables which have been compared. Every timed variable
+	// counts once.
+	NumCompared int
+}
+
+type binoutDiffKey struct {
+	kind int
+	path string
+}
+
+type binoutComparer struct {
+	a, b     Binout
+	options  BinoutCompareOptions
+	report   BinoutCompareReport
+	reported map[binoutDiffKey]bool
+	compared map[string]bool
+	values   map[string]*BinoutDifference
+}
+
+// CompareBinouts compares the structure and the data of two binouts and
+// returns all differences sorted by path
+func CompareBinouts(a, b Binout, options BinoutCompareOptions) (BinoutCompareReport, error) {
+	c := binoutComparer{
+		a:        a,
+		b:        b,
+		options:  options,
+		reported: make(map[binoutDiffKey]bool),
+		compared: make(map[string]bool),
+		values:   make(map[string]*BinoutDifference),
+	}
+
+	if err := c.compareFolder("/"); err != nil {
+		return c.report, err
+	}
+
+	for _, diff := range c.values {
+		c.report.Differences = append(c.report.Differences, *diff)
+	}
+	sort.SliceStable(c.report.Differences, func(i, j int) bool {
+		di, dj := c.report.Differences[i], c.report.Differences[j]
+		if di.Path != dj.Path {
+			return di.Path < dj.Path
+		}
+		return di.Kind < dj.Kind
+	})
+
+	return c.report, nil
+}
+
+// Equal returns whether no differences have been found
+func (r BinoutCompareReport) Equal() bool {
+	return len(r.Differences) == 0
+}
+
+func (r BinoutCompareReport) String() string {
+	if r.Equal() {
+		return fmt.Sprintf("No differences in %d variables", r.NumCompared)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d differences in %d variables", len(r.Differences), r.NumCompared)
+	for _, diff := range r.Differences {
+		b.WriteString("\n")
+		b.WriteString(diff.String())
+	}
+	return b.String()
+}
+
+func (d BinoutDifference) String() string {
+	switch d.Kind {
+	case BinoutDiffMissing:
+		return fmt.Sprintf("%s: only exists in the first binout", d.Path)
+	case BinoutDiffExtra:
+		return fmt.Sprintf("%s: only exists in the second binout", d.Path)
+	case BinoutDiffType:
+		return fmt.Sprintf("%s: type %s differs from %s", d.Path, binoutCompareTypeName(d.TypeA), binoutCompareTypeName(d.TypeB))
+	case BinoutDiffTimesteps:
+		return fmt.Sprintf("%s: %d timesteps differ from %d", d.Path, d.TimestepsA, d.TimestepsB)
+	case BinoutDiffLength:
+		return fmt.Sprintf("%s: %d values differ from %d", d.Path, d.LengthA, d.LengthB)
+	case BinoutDiffValues:
+		location := fmt.Sprintf("[%d]", d.Index)
+		if d.Timestep != -1 {
+			location = fmt.Sprintf("[%d]%s", d.Timestep, location)
+		}
+		return fmt.Sprintf("%s: %d values exceed the tolerance, max deviation %g (relative %g) at %s: %g != %g",
+			d.Path, d.NumExceeded, d.MaxAbsoluteDeviation, d.MaxRelativeDeviation, location, d.ValueA, d.ValueB)
+	default:
+		return fmt.Sprintf("%s: unknown difference %d", d.Path, d.Kind)
+	}
+}
+
+func binoutCompareTypeName(typeID uint64) string {
+	if typeID == BinoutTypeInvalid {
+		return "FOLDER"
+	}
+	return BinoutTypeName(typeID)
+}
+
+func (c *binoutComparer) addDifference(diff BinoutDifference) {
+	key := binoutDiffKey{diff.Kind, diff.Path}
+	if c.reported[key] {
+		return
+	}
+	c.reported[key] = true
+	c.report.Differences = append(c.report.Differences, diff)
+}
+
+// children returns the sorted union of the children of folderA in the first
+// and folderB in the second binout and in which of the binouts they exist
+func (c *binoutComparer) children(folderA, folderB string) ([]string, map[string]bool, map[string]bool) {
+	inA, inB := make(map[string]bool), make(map[string]bool)
+	var names []string
+
+	for _, child := range c.a.GetChildren(folderA) {
+		inA[child] = true
+		names = append(names, child)
+	}
+	for _, child := range c.b.GetChildren(folderB) {
+		inB[child] = true
+		if !inA[child] {
+			names = append(names, child)
+		}
+	}
+
+	sort.Strings(names)
+	return names, inA, inB
+}
+
+func (c *binoutComparer) compareFolder(folder string) error {
+	names, inA, inB := c.children(folder, folder)
+
+	var timestepsA, timestepsB []string
+	for _, name := range names {
+		if isBinoutDString(name) {
+			if inA[name] {
+				timestepsA = append(timestepsA, name)
+			}
+			if inB[name] {
+				timestepsB = append(timestepsB, name)
+			}
+			continue
+		}
+
+		childPath := path.Join(folder, name)
+		if !c.existsInBoth(childPath, inA[name], inB[name]) {
+			continue
+		}
+
+		isVarA, isVarB := c.a.VariableExists(childPath), c.b.VariableExists(childPath)
+		var err error
+		switch {
+		case isVarA && isVarB:
+			err = c.compareVariable(childPath, childPath, childPath, -1)
+		case !isVarA && !isVarB:
+			err = c.compareFolder(childPath)
+		case isVarA:
+			c.addDifference(BinoutDifference{Kind: BinoutDiffType, Path: childPath, TypeA: c.a.GetTypeID(childPath), TypeB: BinoutTypeInvalid})
+		default:
+			c.addDifference(BinoutDifference{Kind: BinoutDiffType, Path: childPath, TypeA: BinoutTypeInvalid, TypeB: c.b.GetTypeID(childPath)})
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(timestepsA) != 0 || len(timestepsB) != 0 {
+		return c.compareTimesteps(folder, timestepsA, timestepsB)
+	}
+	return nil
+}
+
+// compareTimesteps compares the dxxxxxx folders of a database pairwise in the
+// order of their names
+func (c *binoutComparer) compareTimesteps(folder string, timestepsA, timestepsB []string) error {
+	if len(timestepsA) != len(timestepsB) {
+		c.addDifference(BinoutDifference{
+			Kind:       BinoutDiffTimesteps,
+			Path:       folder,
+			TimestepsA: len(timestepsA),
+			TimestepsB: len(timestepsB),
+		})
+	}
+
+	n := len(timestepsA)
+	if len(timestepsB) < n {
+		n = len(timestepsB)
+	}
+
+	for t := 0; t < n; t++ {
+		folderA, folderB := path.Join(folder, timestepsA[t]), path.Join(folder, timestepsB[t])
+		names, inA, inB := c.children(folderA, folderB)
+
+		for _, name := range names {
+			variablePath := path.Join(folder, name)
+			if !c.existsInBoth(variablePath, inA[name], inB[name]) {
+				continue
+			}
+
+			if err := c.compareVariable(folderA+"/"+name, folderB+"/"+name, variablePath, t); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *binoutComparer) existsInBoth(reportPath string, inA, inB bool) bool {
+	if !inB {
+		c.addDifference(BinoutDifference{Kind: BinoutDiffMissing, Path: reportPath})
+		return false
+	}
+	if !inA {
+		c.addDifference(BinoutDifference{Kind: BinoutDiffExtra, Path: reportPath})
+		return false
+	}
+	return true
+}
+
+func (c *binoutComparer) compareVariable(pathA, pathB, reportPath string, timestep int) error {
+	if !c.compared[reportPath] {
+		c.compared[reportPath] = true
+		c.report.NumCompared++
+	}
+
+	typeA, typeB := c.a.GetTypeID(pathA), c.b.GetTypeID(pathB)
+	if typeA != typeB {
+		c.addDifference(BinoutDifference{Kind: BinoutDiffType, Path: reportPath, TypeA: typeA, TypeB: typeB})
+	}
+
+	dataA, err := c.a.readFloat64(pathA)
+	if err != nil {
+		return err
+	}
+	dataB, err := c.b.readFloat64(pathB)
+	if err != nil {
+		return err
+	}
+
+	if len(dataA) != len(dataB) {
+		c.addDifference(BinoutDifference{Kind: BinoutDiffLength, Path: reportPath, LengthA: len(dataA), LengthB: len(dataB)})
+		return nil
+	}
+
+	for i := range dataA {
+		absDev, relDev, ok := c.deviation(dataA[i], dataB[i])
+		if ok {
+			continue
+		}
+
+		diff := c.values[reportPath]
+		if diff == nil {
+			diff = &BinoutDifference{Kind: BinoutDiffValues, Path: reportPath, MaxAbsoluteDeviation: -1}
+			c.values[reportPath] = diff
+		}
+
+		diff.NumExceeded++
+		diff.MaxRelativeDeviation = math.Max(diff.MaxRelativeDeviation, relDev)
+		if absDev > diff.MaxAbsoluteDeviation {
+			diff.MaxAbsoluteDeviation = absDev
+			diff.Timestep = timestep
+			diff.Index = i
+			diff.ValueA, diff.ValueB = dataA[i], dataB[i]
+		}
+	}
+
+	return nil
+}
+
+// deviation returns the absolute and relative deviation of a and b and whether
+// they are inside of the tolerances
+func (c *binoutComparer) deviation(a, b float64) (float64, float64, bool) {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		if math.IsNaN(a) && math.IsNaN(b) {
+			return 0, 0, true
+		}
+		return math.Inf(1), math.Inf(1), false
+	}
+	if a == b {
+		return 0, 0, true
+	}
+
+	absDev := math.Abs(a - b)
+	scale := math.Max(math.Abs(a), math.Abs(b))
+	relDev := absDev / scale
+
+	return absDev, relDev, absDev <= c.options.AbsoluteTolerance+c.options.RelativeTolerance*scale
+}
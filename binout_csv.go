@@ -0,0 +1,106 @@
+package dynareadout
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+)
+
+// ExportCSV writes timed variables (as accepted by SimplePathToReal) as CSV
+// into w. The first column is the time followed by one column per value of
+// every variable. The headers are built from the ids and the legend of the
+// metadata. All variables need to be part of the same database (e.g.
+// "nodout/x_displacement" and "nodout/y_displacement"). The data is read and
+// written one timestep at a time so that large databases do not need to fit
+// into memory.
+func (bin_file Binout) ExportCSV(w io.Writer, simplePaths ...string) error {
+	if len(simplePaths) == 0 {
+		return errors.New("No variables have been given")
+	}
+
+	var dbPath string
+	variables := make([]string, len(simplePaths))
+	for i, simplePath := range simplePaths {
+		realPath, _, timed, err := bin_file.SimplePathToReal(simplePath)
+		if err != nil {
+			return err
+		}
+		if !timed {
+			return fmt.Errorf("The variable \"%s\" is not timed", simplePath)
+		}
+
+		dir, variable := path.Split(realPath)
+		dir = path.Clean(dir)
+		if i == 0 {
+			dbPath = dir
+		} else if dir != dbPath {
+			return fmt.Errorf("The variable \"%s\" is not part of \"%s\"", simplePath, dbPath)
+		}
+		variables[i] = variable
+	}
+
+	db, err := bin_file.openDatabase(dbPath)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	var record []string
+	// The number of values of every variable, which is determined by the first
+	// timestep and used for the header
+	numValues := make([]int, len(variables))
+
+	for t, d := range db.timesteps() {
+		timestepPath := db.path + "/" + d
+
+		time, err := bin_file.readFloat64(timestepPath + "/time")
+		if err != nil {
+			return err
+		}
+		if len(time) == 0 {
+			return fmt.Errorf("\"%s/time\" is empty", timestepPath)
+		}
+
+		values := make([][]float64, len(variables))
+		for i, variable := range variables {
+			values[i], err = bin_file.readFloat64(timestepPath + "/" + variable)
+			if err != nil {
+				return err
+			}
+		}
+
+		if t == 0 {
+			record = append(record, "time")
+			for i, variable := range variables {
+				numValues[i] = len(values[i])
+				record = append(record, db.valueLabels(variable, numValues[i])...)
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+
+		for i, variable := range variables {
+			if len(values[i]) != numValues[i] {
+				return fmt.Errorf("\"%s/%s\" contains %d values instead of %d", timestepPath, variable, len(values[i]), numValues[i])
+			}
+		}
+
+		record = record[:0]
+		record = append(record, strconv.FormatFloat(time[0], 'g', -1, 64))
+		for _, v := range values {
+			for _, value := range v {
+				record = append(record, strconv.FormatFloat(value, 'g', -1, 64))
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
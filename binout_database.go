@@ -0,0 +1,405 @@
+package dynareadout
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// The width of one title inside of metadata/legend
+const binoutLegendTitleWidth = 80
+
+// binoutDatabase is the common base of the typed binout readers (nodout,
+// rcforc, ...). It holds the ids of the metadata folder so that the timed
+// variables can be addressed by user ids instead of array indices.
+type binoutDatabase struct {
+	bin_file Binout
+	path     string
+	ids      []int64
+	indices  map[int64]int
+	titles   map[int64]string
+}
+
+func (bin_file Binout) openDatabase(path string) (binoutDatabase, error) {
+	db := binoutDatabase{
+		bin_file: bin_file,
+		path:     path,
+		indices:  make(map[int64]int),
+		titles:   make(map[int64]string),
+	}
+
+	if _, err := bin_file.GetNumTimesteps(path); err != nil {
+		return db, newKindError(ErrPathNotFound, "The database \"%s\" does not exist", path)
+	}
+
+	idsPath := path + "/metadata/ids"
+	if bin_file.VariableExists(idsPath) {
+		ids, err := bin_file.readInt64(idsPath)
+		if err != nil {
+			return db, err
+		}
+		db.ids = ids
+	}
+
+	for i, id := range db.ids {
+		db.indices[id] = i
+	}
+
+	legendPath := path + "/metadata/legend"
+	legendIDsPath := path + "/metadata/legend_ids"
+	if bin_file.VariableExists(legendPath) && bin_file.VariableExists(legendIDsPath) {
+		legend, err := bin_file.ReadString(legendPath)
+		if err != nil {
+			return db, err
+		}
+		legendIDs, err := bin_file.readInt64(legendIDsPath)
+		if err != nil {
+			return db, err
+		}
+
+		for i, id := range legendIDs {
+			start := i * binoutLegendTitleWidth
+			if start >= len(legend) {
+				break
+			}
+			end := start + binoutLegendTitleWidth
+			if end > len(legend) {
+				end = len(legend)
+			}
+			db.titles[id] = strings.TrimSpace(legend[start:end])
+		}
+	}
+
+	return db, nil
+}
+
+// Path returns the path of the database inside the binout (e.g. "/nodout")
+func (db binoutDatabase) Path() string {
+	return db.path
+}
+
+// IDs returns the user ids stored in metadata/ids
+func (db binoutDatabase) IDs() []int64 {
+	return db.ids
+}
+
+// Index returns the index of id inside of IDs
+func (db binoutDatabase) Index(id int64) (int, bool) {
+	index, ok := db.indices[id]
+	return index, ok
+}
+
+// unit returns the string of metadata/units or an empty string if it does not
+// exist
+func (db binoutDatabase) unit() (string, error) {
+	unitsPath := db.path + "/metadata/units"
+	if !db.bin_file.VariableExists(unitsPath) {
+		return "", nil
+	}
+
+	units, err := db.bin_file.ReadString(unitsPath)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(units), nil
+}
+
+// Title returns the title of id from metadata/legend or an empty string if no
+// title exists
+func (db binoutDatabase) Title(id int64) string {
+	return db.titles[id]
+}
+
+func (db binoutDatabase) NumTimesteps() (uint64, error) {
+	return db.bin_file.GetNumTimesteps(db.path)
+}
+
+func (db binoutDatabase) Time() ([]float64, error) {
+	data, err := db.bin_file.readTimedFloat64(db.path + "/time")
+	if err != nil {
+		return nil, err
+	}
+
+	time := make([]float64, len(data))
+	for t := range data {
+		if len(data[t]) != 0 {
+			time[t] = data[t][0]
+		}
+	}
+
+	return time, nil
+}
+
+// ReadVariable reads a timed variable of the database. The returned slice is
+// indexed by [timestep][value]
+func (db binoutDatabase) ReadVariable(variable string) ([][]float64, error) {
+	return db.bin_file.readTimedFloat64(db.path + "/" + variable)
+}
+
+// ReadID reads a timed variable of the database which stores one value per id
+// and returns the values of id over all timesteps
+func (db binoutDatabase) ReadID(variable string, id int64) ([]float64, error) {
+	return db.readColumn(variable, id)
+}
+
+func (db binoutDatabase) index(id int64) (int, error) {
+	index, ok := db.indices[id]
+	if !ok {
+		return 0, fmt.Errorf("The id %d does not exist in \"%s\"", id, db.path)
+	}
+	return index, nil
+}
+
+// readColumn reads a timed variable and returns the values of id over all
+// timesteps
+func (db binoutDatabase) readColumn(variable string, id int64) ([]float64, error) {
+	index, err := db.index(id)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := db.ReadVariable(variable)
+	if err != nil {
+		return nil, err
+	}
+
+	return binoutColumn(data, index, db.path+"/"+variable)
+}
+
+// readVector reads three timed variables and combines the values of id into
+// vectors over all timesteps
+func (db binoutDatabase) readVector(id int64, x, y, z string) ([][3]float64, error) {
+	var components [3][]float64
+	for i, variable := range [3]string{x, y, z} {
+		column, err := db.readColumn(variable, id)
+		if err != nil {
+			return nil, err
+		}
+		components[i] = column
+	}
+
+	return combineVector(components), nil
+}
+
+func combineVector(components [3][]float64) [][3]float64 {
+	vec := make([][3]float64, len(components[0]))
+	for t := range vec {
+		for i := range components {
+			if t < len(components[i]) {
+				vec[t][i] = components[i][t]
+			}
+		}
+	}
+	return vec
+}
+
+func vectorMagnitudes(vec [][3]float64) []float64 {
+	magnitudes := make([]float64, len(vec))
+	for t, v := range vec {
+		magnitudes[t] = math.Sqrt(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])
+	}
+	return magnitudes
+}
+
+// valueLabels returns one label per value of a variable which is built from
+// the ids and titles of the database
+func (db binoutDatabase) valueLabels(variable string, numValues int) []string {
+	labels := make([]string, numValues)
+	for i := range labels {
+		if len(db.ids) == numValues {
+			id := db.ids[i]
+			labels[i] = fmt.Sprintf("%s %d", variable, id)
+			if title := db.Title(id); title != "" {
+				labels[i] += " (" + title + ")"
+			}
+		} else if numValues == 1 {
+			labels[i] = variable
+		} else {
+			labels[i] = fmt.Sprintf("%s[%d]", variable, i)
+		}
+	}
+	return labels
+}
+
+func binoutColumn(data [][]float64, index int, path string) ([]float64, error) {
+	column := make([]float64, len(data))
+	for t := range data {
+		if index >= len(data[t]) {
+			return nil, fmt.Errorf("\"%s\" contains only %d values at timestep %d", path, len(data[t]), t)
+		}
+		column[t] = data[t][index]
+	}
+	return column, nil
+}
+
+// readInt64 reads an integer variable of any integer type as int64
+func (bin_file Binout) readInt64(path string) ([]int64, error) {
+	switch bin_file.GetTypeID(path) {
+	case BinoutTypeInt8:
+		return convertSlice[int8, int64](bin_file.ReadInt8(path))
+	case BinoutTypeInt16:
+		return convertSlice[int16, int64](bin_file.ReadInt16(path))
+	case BinoutTypeInt32:
+		return convertSlice[int32, int64](bin_file.ReadInt32(path))
+	case BinoutTypeInt64:
+		return bin_file.ReadInt64(path)
+	case BinoutTypeUint8:
+		return convertSlice[uint8, int64](bin_file.ReadUint8(path))
+	case BinoutTypeUint16:
+		return convertSlice[uint16, int64](bin_file.ReadUint16(path))
+	case BinoutTypeUint32:
+		return convertSlice[uint32, int64](bin_file.ReadUint32(path))
+	case BinoutTypeUint64:
+		return convertSlice[uint64, int64](bin_file.ReadUint64(path))
+	default:
+		return nil, fmt.Errorf("\"%s\" is not an integer variable", path)
+	}
+}
+
+// readTimedFloat64 reads a timed floating point variable of any precision as
+// float64
+func (bin_file Binout) readTimedFloat64(path string) ([][]float64, error) {
+	_, typeID, timed, err := bin_file.SimplePathToReal(path)
+	if err != nil {
+		return nil, err
+	}
+	if !timed {
+		return nil, fmt.Errorf("The variable \"%s\" is not timed", path)
+	}
+
+	switch typeID {
+	case BinoutTypeFloat32:
+		data, err := bin_file.ReadTimedFloat32(path)
+		if err != nil {
+			return nil, err
+		}
+
+		data64 := make([][]float64, len(data))
+		for t := range data {
+			data64[t] = make([]float64, len(data[t]))
+			for i, v := range data[t] {
+				data64[t][i] = float64(v)
+			}
+		}
+		return data64, nil
+	case BinoutTypeFloat64:
+		return bin_file.ReadTimedFloat64(path)
+	default:
+		return nil, fmt.Errorf("The variable \"%s\" is not a floating point variable", path)
+	}
+}
+
+func convertSlice[Tsrc goType, Tdst goType](src []Tsrc, err error) ([]Tdst, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	dst := make([]Tdst, len(src))
+	for i, v := range src {
+		dst[i] = Tdst(v)
+	}
+	return dst, nil
+}
+
+// readPoints reads a timed variable which stores multiple values (e.g.
+// integration points) per id and returns the values of id indexed by
+// [timestep][point]
+func (db binoutDatabase) readPoints(variable string, id int64) ([][]float64, error) {
+	index, err := db.index(id)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := db.ReadVariable(variable)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([][]float64, len(data))
+	for t := range data {
+		if len(data[t])%len(db.ids) != 0 {
+			return nil, fmt.Errorf("\"%s/%s\" contains %d values at timestep %d which can not be distributed onto %d ids", db.path, variable, len(data[t]), t, len(db.ids))
+		}
+
+		numPoints := len(data[t]) / len(db.ids)
+		points[t] = append([]float64(nil), data[t][index*numPoints:(index+1)*numPoints]...)
+	}
+
+	return points, nil
+}
+
+// findDatabases returns the paths of all folders beneath path (including path)
+// which contain dxxxxxx folders
+func (bin_file Binout) findDatabases(path string) []string {
+	var databases []string
+
+	if timesteps, err := bin_file.GetNumTimesteps(path); err == nil && timesteps != 0 {
+		databases = append(databases, path)
+	}
+
+	for _, child := range bin_file.GetChildren(path) {
+		if child == "metadata" || isBinoutDString(child) {
+			continue
+		}
+		databases = append(databases, bin_file.findDatabases(path+"/"+child)...)
+	}
+
+	return databases
+}
+
+func isBinoutDString(name string) bool {
+	if len(name) < 2 || name[0] != 'd' {
+		return false
+	}
+	for _, c := range name[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// readFloat64 reads a variable of any type as float64
+func (bin_file Binout) readFloat64(path string) ([]float64, error) {
+	switch bin_file.GetTypeID(path) {
+	case BinoutTypeFloat32:
+		return convertSlice[float32, float64](bin_file.ReadFloat32(path))
+	case BinoutTypeFloat64:
+		return bin_file.ReadFloat64(path)
+	case BinoutTypeInt8:
+		return convertSlice[int8, float64](bin_file.ReadInt8(path))
+	case BinoutTypeInt16:
+		return convertSlice[int16, float64](bin_file.ReadInt16(path))
+	case BinoutTypeInt32:
+		return convertSlice[int32, float64](bin_file.ReadInt32(path))
+	case BinoutTypeInt64:
+		return convertSlice[int64, float64](bin_file.ReadInt64(path))
+	case BinoutTypeUint8:
+		return convertSlice[uint8, float64](bin_file.ReadUint8(path))
+	case BinoutTypeUint16:
+		return convertSlice[uint16, float64](bin_file.ReadUint16(path))
+	case BinoutTypeUint32:
+		return convertSlice[uint32, float64](bin_file.ReadUint32(path))
+	case BinoutTypeUint64:
+		return convertSlice[uint64, float64](bin_file.ReadUint64(path))
+	default:
+		return nil, fmt.Errorf("\"%s\" has not been found", path)
+	}
+}
+
+// timesteps returns the names of all dxxxxxx folders of the database
+func (db binoutDatabase) timesteps() []string {
+	var timesteps []string
+	for _, child := range db.bin_file.GetChildren(db.path) {
+		if isBinoutDString(child) {
+			timesteps = append(timesteps, child)
+		}
+	}
+	return timesteps
+}
+
+// ReadIDs reads metadata/ids of a database (e.g. "/nodout") as int64
+// regardless of the integer type in which they have been written
+func (bin_file Binout) ReadIDs(databasePath string) ([]int64, error) {
+	return bin_file.readInt64(strings.TrimSuffix(databasePath, "/") + "/metadata/ids")
+}
@@ -0,0 +1,113 @@
+package dynareadout
+
+import "fmt"
+
+// The element types of the sub folders of elout
+const (
+	EloutSolid      = "solid"
+	EloutShell      = "shell"
+	EloutThickShell = "thickshell"
+	EloutBeam       = "beam"
+)
+
+// EloutElements reads the element output of one element type of the elout
+// database. Solids, shells and thick shells may store multiple integration
+// points per element, which is why the values are indexed by
+// [timestep][point]
+type EloutElements struct {
+	binoutDatabase
+}
+
+// EloutElementTypes returns the element types which exist inside of elout
+func (bin_file Binout) EloutElementTypes() []string {
+	var types []string
+	for _, child := range bin_file.GetChildren("/elout") {
+		if child != "metadata" {
+			types = append(types, child)
+		}
+	}
+	return types
+}
+
+func (bin_file Binout) Elout(elementType string) (EloutElements, error) {
+	db, err := bin_file.openDatabase("/elout/" + elementType)
+	return EloutElements{db}, err
+}
+
+// Read reads any variable of the element (e.g. "sig_xx" or "axial")
+func (e EloutElements) Read(variable string, elementID int64) ([][]float64, error) {
+	return e.readPoints(variable, elementID)
+}
+
+// Stress returns the stress tensor (xx, yy, zz, xy, yz, zx) of all integration
+// points of a solid, shell or thick shell element
+func (e EloutElements) Stress(elementID int64) ([][][6]float64, error) {
+	return e.readTensor(elementID, [6]string{"sig_xx", "sig_yy", "sig_zz", "sig_xy", "sig_yz", "sig_zx"})
+}
+
+// Strain returns the strain tensor (xx, yy, zz, xy, yz, zx) of all
+// integration points of a shell or thick shell element
+func (e EloutElements) Strain(elementID int64) ([][][6]float64, error) {
+	return e.readTensor(elementID, [6]string{"eps_xx", "eps_yy", "eps_zz", "eps_xy", "eps_yz", "eps_zx"})
+}
+
+// BeamResultants returns the resultants (axial, shear_s, shear_t, moment_s,
+// moment_t, torsion) of a beam element
+func (e EloutElements) BeamResultants(elementID int64) ([][6]float64, error) {
+	variables := [6]string{"axial", "shear_s", "shear_t", "moment_s", "moment_t", "torsion"}
+	var components [6][]float64
+	for i, variable := range variables {
+		column, err := e.readColumn(variable, elementID)
+		if err != nil {
+			return nil, err
+		}
+		components[i] = column
+	}
+
+	return e.combineResultants(variables, components)
+}
+
+// combineResultants combines the columns of the six resultants, which need to
+// contain the same number of timesteps (e.g. a family which has been refreshed
+// while reading could contain more timesteps for the later columns)
+func (e EloutElements) combineResultants(variables [6]string, components [6][]float64) ([][6]float64, error) {
+	for i := range components {
+		if len(components[i]) != len(components[0]) {
+			return nil, fmt.Errorf("\"%s/%s\" contains %d timesteps instead of %d", e.path, variables[i], len(components[i]), len(components[0]))
+		}
+	}
+
+	resultants := make([][6]float64, len(components[0]))
+	for t := range resultants {
+		for i := range components {
+			resultants[t][i] = components[i][t]
+		}
+	}
+
+	return resultants, nil
+}
+
+func (e EloutElements) readTensor(elementID int64, variables [6]string) ([][][6]float64, error) {
+	var components [6][][]float64
+	for i, variable := range variables {
+		points, err := e.readPoints(variable, elementID)
+		if err != nil {
+			return nil, err
+		}
+		components[i] = points
+	}
+
+	tensor := make([][][6]float64, len(components[0]))
+	for t := range tensor {
+		tensor[t] = make([][6]float64, len(components[0][t]))
+		for p := range tensor[t] {
+			for i := range components {
+				if t < len(components[i]) && p < len(components[i][t]) {
+					tensor[t][p][i] = components[i][t][p]
+				}
+			}
+		}
+	}
+
+	return tensor, nil
+}
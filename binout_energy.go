@@ -0,0 +1,150 @@
+package dynareadout
+
+import "strings"
+
+// Matsum reads the part energies and momenta of the matsum database
+type Matsum struct {
+	binoutDatabase
+}
+
+// Rbdout reads the rigid body kinematics of the rbdout database
+type Rbdout struct {
+	binoutDatabase
+}
+
+// Spcforc reads the reaction forces of single point constraints of the
+// spcforc database
+type Spcforc struct {
+	binoutDatabase
+}
+
+// Bndout reads one of the boundary condition databases beneath bndout (e.g.
+// "/bndout/discrete/nodes")
+type Bndout struct {
+	binoutDatabase
+}
+
+// Sleout reads the sliding interface energies of the sleout database
+type Sleout struct {
+	binoutDatabase
+}
+
+func (bin_file Binout) Matsum() (Matsum, error) {
+	db, err := bin_file.openDatabase("/matsum")
+	return Matsum{db}, err
+}
+
+func (m Matsum) KineticEnergy(partID int64) ([]float64, error) {
+	return m.readColumn("kinetic_energy", partID)
+}
+
+func (m Matsum) InternalEnergy(partID int64) ([]float64, error) {
+	return m.readColumn("internal_energy", partID)
+}
+
+func (m Matsum) HourglassEnergy(partID int64) ([]float64, error) {
+	return m.readColumn("hourglass_energy", partID)
+}
+
+func (m Matsum) Mass(partID int64) ([]float64, error) {
+	return m.readColumn("mass", partID)
+}
+
+func (m Matsum) AddedMass(partID int64) ([]float64, error) {
+	return m.readColumn("added_mass", partID)
+}
+
+func (m Matsum) Momentum(partID int64) ([][3]float64, error) {
+	return m.readVector(partID, "x_momentum", "y_momentum", "z_momentum")
+}
+
+func (m Matsum) RigidBodyVelocity(partID int64) ([][3]float64, error) {
+	return m.readVector(partID, "x_rbvelocity", "y_rbvelocity", "z_rbvelocity")
+}
+
+func (bin_file Binout) Rbdout() (Rbdout, error) {
+	db, err := bin_file.openDatabase("/rbdout")
+	return Rbdout{db}, err
+}
+
+func (r Rbdout) Coordinates(partID int64) ([][3]float64, error) {
+	return r.readVector(partID, "global_x", "global_y", "global_z")
+}
+
+func (r Rbdout) Displacement(partID int64) ([][3]float64, error) {
+	return r.readVector(partID, "global_dx", "global_dy", "global_dz")
+}
+
+func (r Rbdout) Velocity(partID int64) ([][3]float64, error) {
+	return r.readVector(partID, "global_vx", "global_vy", "global_vz")
+}
+
+func (r Rbdout) Acceleration(partID int64) ([][3]float64, error) {
+	return r.readVector(partID, "global_ax", "global_ay", "global_az")
+}
+
+func (r Rbdout) RotationalDisplacement(partID int64) ([][3]float64, error) {
+	return r.readVector(partID, "global_rdx", "global_rdy", "global_rdz")
+}
+
+func (r Rbdout) RotationalVelocity(partID int64) ([][3]float64, error) {
+	return r.readVector(partID, "global_rvx", "global_rvy", "global_rvz")
+}
+
+func (r Rbdout) RotationalAcceleration(partID int64) ([][3]float64, error) {
+	return r.readVector(partID, "global_rax", "global_ray", "global_raz")
+}
+
+func (bin_file Binout) Spcforc() (Spcforc, error) {
+	db, err := bin_file.openDatabase("/spcforc")
+	return Spcforc{db}, err
+}
+
+func (s Spcforc) Force(nodeID int64) ([][3]float64, error) {
+	return s.readVector(nodeID, "x_force", "y_force", "z_force")
+}
+
+func (s Spcforc) Moment(nodeID int64) ([][3]float64, error) {
+	return s.readVector(nodeID, "x_moment", "y_moment", "z_moment")
+}
+
+// BndoutDatabases returns the paths of all databases beneath bndout
+func (bin_file Binout) BndoutDatabases() []string {
+	return bin_file.findDatabases("/bndout")
+}
+
+// Bndout opens a database beneath bndout. path can either be absolute (e.g.
+// "/bndout/discrete/nodes") or relative to bndout (e.g. "discrete/nodes")
+func (bin_file Binout) Bndout(path string) (Bndout, error) {
+	if !strings.HasPrefix(path, "/") {
+		path = "/bndout/" + path
+	}
+
+	db, err := bin_file.openDatabase(strings.TrimSuffix(path, "/"))
+	return Bndout{db}, err
+}
+
+func (b Bndout) Force(id int64) ([][3]float64, error) {
+	return b.readVector(id, "x_force", "y_force", "z_force")
+}
+
+func (b Bndout) Energy(id int64) ([]float64, error) {
+	return b.readColumn("energy", id)
+}
+
+func (bin_file Binout) Sleout() (Sleout, error) {
+	db, err := bin_file.openDatabase("/sleout")
+	return Sleout{db}, err
+}
+
+func (s Sleout) SlaveEnergy(interfaceID int64) ([]float64, error) {
+	return s.readColumn("slave", interfaceID)
+}
+
+func (s Sleout) MasterEnergy(interfaceID int64) ([]float64, error) {
+	return s.readColumn("master", interfaceID)
+}
+
+func (s Sleout) FrictionalEnergy(interfaceID int64) ([]float64, error) {
+	return s.readColumn("frictional_energy", interfaceID)
+}
@@ -0,0 +1,385 @@
+package dynareadout
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// BinoutSelection selects the data which is copied by Extract. Path is either
+// a folder (e.g. a whole database like "/glstat"), a variable or a timed
+// variable as accepted by SimplePathToReal (e.g. "nodout/x_displacement"). If
+// IDs is not empty only the values of these ids are copied from the
+// databases beneath Path.
+type BinoutSelection struct {
+	Path string
+	IDs  []int64
+}
+
+type binoutExtractor struct {
+	bin_file Binout
+	writer   *BinoutWriter
+	written  map[string]bool
+}
+
+// binoutIDFilter holds the indices of the selected ids of a database
+type binoutIDFilter struct {
+	indices []int
+	numIDs  int
+	ids     map[int64]bool
+	// optional is set for metadata which does not need to store one value per id
+	optional bool
+}
+
+// Extract copies the selected folders and variables into writer while
+// preserving their paths, the metadata and the dxxxxxx folders. This can be
+// used to create a small binout out of a large multi file binout. Data which
+// is selected multiple times is only written once.
+func (bin_file Binout) Extract(writer *BinoutWriter, selections ...BinoutSelection) error {
+	if len(selections) == 0 {
+		return errors.New("No selections have been given")
+	}
+
+	e := binoutExtractor{
+		bin_file: bin_file,
+		writer:   writer,
+		written:  make(map[string]bool),
+	}
+
+	for _, s := range selections {
+		if err := e.extract(s); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExtractFile is the same as Extract, but writes into a newly created file
+func (bin_file Binout) ExtractFile(fileName string, selections ...BinoutSelection) error {
+	writer, err := BinoutCreate(fileName)
+	if err != nil {
+		return err
+	}
+
+	if err := bin_file.Extract(writer, selections...); err != nil {
+		writer.Close()
+		return err
+	}
+
+	return writer.Close()
+}
+
+func (e *binoutExtractor) extract(s BinoutSelection) error {
+	folder := path.Clean("/" + s.Path)
+
+	// A folder
+	if !e.bin_file.VariableExists(folder) && len(e.bin_file.GetChildren(folder)) != 0 {
+		return e.copyFolder(folder, s.IDs, true)
+	}
+
+	realPath, _, timed, err := e.bin_file.SimplePathToReal(s.Path)
+	if err != nil {
+		return err
+	}
+
+	dbPath, variable := path.Split(realPath)
+	dbPath = path.Clean(dbPath)
+
+	if !timed {
+		var filter *binoutIDFilter
+		if len(s.IDs) != 0 && path.Base(dbPath) == "metadata" {
+			filter, err = e.idFilter(path.Dir(dbPath), s.IDs)
+			if err != nil {
+				return err
+			}
+		}
+		return e.copyVariable(realPath, filter)
+	}
+
+	filter, err := e.idFilter(dbPath, s.IDs)
+	if err != nil {
+		return err
+	}
+	if filter != nil && len(filter.indices) == 0 {
+		return fmt.Errorf("None of the ids exist in \"%s\"", dbPath)
+	}
+
+	if err := e.copyMetadata(dbPath, filter); err != nil {
+		return err
+	}
+
+	for _, child := range e.bin_file.GetChildren(dbPath) {
+		if !isBinoutDString(child) {
+			continue
+		}
+
+		timestep := path.Join(dbPath, child)
+		if err := e.copyVariable(timestep+"/time", nil); err != nil {
+			return err
+		}
+		if err := e.copyVariable(timestep+"/"+variable, filter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyFolder copies all variables beneath folder. If ids are given, every
+// database beneath folder is filtered by them and databases which contain none
+// of the ids are skipped. Only if the selected folder itself is a database
+// this is an error.
+func (e *binoutExtractor) copyFolder(folder string, ids []int64, selected bool) error {
+	var filter *binoutIDFilter
+	if len(ids) != 0 {
+		if timesteps, err := e.bin_file.GetNumTimesteps(folder); err == nil && timesteps != 0 {
+			filter, err = e.idFilter(folder, ids)
+			if err != nil {
+				return err
+			}
+			if len(filter.indices) == 0 {
+				if selected {
+					return fmt.Errorf("None of the ids exist in \"%s\"", folder)
+				}
+				return nil
+			}
+		}
+	}
+
+	for _, child := range e.bin_file.GetChildren(folder) {
+		childPath := path.Join(folder, child)
+
+		var err error
+		if e.bin_file.VariableExists(childPath) {
+			err = e.copyVariable(childPath, nil)
+		} else if filter != nil && child == "metadata" {
+			err = e.copyMetadata(folder, filter)
+		} else if filter != nil && isBinoutDString(child) {
+			err = e.copyTimestep(childPath, filter)
+		} else {
+			err = e.copyFolder(childPath, ids, false)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *binoutExtractor) copyTimestep(folder string, filter *binoutIDFilter) error {
+	for _, child := range e.bin_file.GetChildren(folder) {
+		childFilter := filter
+		if child == "time" {
+			childFilter = nil
+		}
+		if err := e.copyVariable(path.Join(folder, child), childFilter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyMetadata copies the metadata folder of a database. The ids, the legend
+// and all other variables storing one value per id are filtered.
+func (e *binoutExtractor) copyMetadata(dbPath string, filter *binoutIDFilter) error {
+	metadata := dbPath + "/metadata"
+	for _, child := range e.bin_file.GetChildren(metadata) {
+		childPath := metadata + "/" + child
+
+		var err error
+		switch {
+		case filter != nil && child == "legend":
+			err = e.copyLegend(dbPath, filter)
+		case filter != nil && child == "legend_ids":
+			err = e.copyLegendIDs(dbPath, filter)
+		case e.bin_file.GetTypeID(childPath) == BinoutTypeInt8:
+			// Strings like the title are never filtered
+			err = e.copyVariable(childPath, nil)
+		case filter != nil:
+			optional := *filter
+			optional.optional = true
+			err = e.copyVariable(childPath, &optional)
+		default:
+			err = e.copyVariable(childPath, nil)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *binoutExtractor) copyLegend(dbPath string, filter *binoutIDFilter) error {
+	legendPath := dbPath + "/metadata/legend"
+	if e.written[legendPath] {
+		return nil
+	}
+
+	legend, err := e.bin_file.ReadString(legendPath)
+	if err != nil {
+		return err
+	}
+
+	legendIDs, err := e.bin_file.readInt64(dbPath + "/metadata/legend_ids")
+	if err != nil {
+		// Without legend_ids the legend can not be filtered
+		return e.copyVariable(legendPath, nil)
+	}
+
+	var filtered strings.Builder
+	for i, id := range legendIDs {
+		start := i * binoutLegendTitleWidth
+		if start >= len(legend) {
+			break
+		}
+		end := start + binoutLegendTitleWidth
+		if end > len(legend) {
+			end = len(legend)
+		}
+		if filter.ids[id] {
+			filtered.WriteString(legend[start:end])
+		}
+	}
+
+	e.written[legendPath] = true
+	if err := e.writer.Cd(dbPath + "/metadata"); err != nil {
+		return err
+	}
+	return e.writer.WriteString("legend", filtered.String())
+}
+
+func (e *binoutExtractor) copyLegendIDs(dbPath string, filter *binoutIDFilter) error {
+	legendIDsPath := dbPath + "/metadata/legend_ids"
+	legendIDs, err := e.bin_file.readInt64(legendIDsPath)
+	if err != nil {
+		return err
+	}
+
+	var indices []int
+	for i, id := range legendIDs {
+		if filter.ids[id] {
+			indices = append(indices, i)
+		}
+	}
+
+	return e.copyVariable(legendIDsPath, &binoutIDFilter{
+		indices: indices,
+		numIDs:  len(legendIDs),
+		ids:     filter.ids,
+	})
+}
+
+// idFilter returns the filter of the given ids inside of the database. nil is
+// returned if no ids are given. ids which do not exist in the database are
+// ignored.
+func (e *binoutExtractor) idFilter(dbPath string, ids []int64) (*binoutIDFilter, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	db, err := e.bin_file.openDatabase(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(db.ids) == 0 {
+		return nil, fmt.Errorf("The database \"%s\" does not contain ids", dbPath)
+	}
+
+	filter := &binoutIDFilter{
+		numIDs: len(db.ids),
+		ids:    make(map[int64]bool, len(ids)),
+	}
+	for _, id := range ids {
+		filter.ids[id] = true
+	}
+
+	// Keep the order of the database and support duplicate ids like in rcforc
+	for i, id := range db.ids {
+		if filter.ids[id] {
+			filter.indices = append(filter.indices, i)
+		}
+	}
+
+	return filter, nil
+}
+
+func (e *binoutExtractor) copyVariable(variablePath string, filter *binoutIDFilter) error {
+	if e.written[variablePath] {
+		return nil
+	}
+	e.written[variablePath] = true
+
+	dir, name := path.Split(variablePath)
+	if err := e.writer.Cd(dir); err != nil {
+		return err
+	}
+
+	w := e.writer
+	switch typeID := e.bin_file.GetTypeID(variablePath); typeID {
+	case BinoutTypeInt8:
+		return copyBinoutData(w.WriteInt8, name, filter)(e.bin_file.ReadInt8(variablePath))
+	case BinoutTypeInt16:
+		return copyBinoutData(w.WriteInt16, name, filter)(e.bin_file.ReadInt16(variablePath))
+	case BinoutTypeInt32:
+		return copyBinoutData(w.WriteInt32, name, filter)(e.bin_file.ReadInt32(variablePath))
+	case BinoutTypeInt64:
+		return copyBinoutData(w.WriteInt64, name, filter)(e.bin_file.ReadInt64(variablePath))
+	case BinoutTypeUint8:
+		return copyBinoutData(w.WriteUint8, name, filter)(e.bin_file.ReadUint8(variablePath))
+	case BinoutTypeUint16:
+		return copyBinoutData(w.WriteUint16, name, filter)(e.bin_file.ReadUint16(variablePath))
+	case BinoutTypeUint32:
+		return copyBinoutData(w.WriteUint32, name, filter)(e.bin_file.ReadUint32(variablePath))
+	case BinoutTypeUint64:
+		return copyBinoutData(w.WriteUint64, name, filter)(e.bin_file.ReadUint64(variablePath))
+	case BinoutTypeFloat32:
+		return copyBinoutData(w.WriteFloat32, name, filter)(e.bin_file.ReadFloat32(variablePath))
+	case BinoutTypeFloat64:
+		return copyBinoutData(w.WriteFloat64, name, filter)(e.bin_file.ReadFloat64(variablePath))
+	default:
+		return fmt.Errorf("\"%s\" has an invalid type %d", variablePath, typeID)
+	}
+}
+
+// copyBinoutData returns a function which filters the result of a read
+// function and writes it using write
+func copyBinoutData[T goType](write func(string, []T) error, name string, filter *binoutIDFilter) func([]T, error) error {
+	return func(data []T, err error) error {
+		if err != nil {
+			return err
+		}
+		data, err = filterBinoutValues(name, data, filter)
+		if err != nil {
+			return err
+		}
+		return write(name, data)
+	}
+}
+
+// filterBinoutValues returns the values of the selected ids. If the variable
+// stores multiple values per id (e.g. integration points) all of them are
+// kept. An error is returned if the values can not be distributed onto the
+// ids, unless the filter is optional in which case they are kept unchanged.
+func filterBinoutValues[T goType](name string, data []T, filter *binoutIDFilter) ([]T, error) {
+	if filter == nil || filter.numIDs == 0 || len(data) == 0 {
+		return data, nil
+	}
+	if len(data)%filter.numIDs != 0 {
+		if filter.optional {
+			return data, nil
+		}
+		return nil, fmt.Errorf("The %d values of \"%s\" can not be distributed onto %d ids", len(data), name, filter.numIDs)
+	}
+
+	perID := len(data) / filter.numIDs
+	filtered := make([]T, 0, len(filter.indices)*perID)
+	for _, index := range filter.indices {
+		filtered = append(filtered, data[index*perID:(index+1)*perID]...)
+	}
+	return filtered, nil
+}
@@ -0,0 +1,90 @@
+package dynareadout
+
+// Deforc reads the discrete element forces of the deforc database
+type Deforc struct {
+	binoutDatabase
+}
+
+// Jntforc reads the joint forces of the jntforc database
+type Jntforc struct {
+	binoutDatabase
+}
+
+// Secforc reads the cross section forces of the secforc database
+type Secforc struct {
+	binoutDatabase
+}
+
+func (bin_file Binout) Deforc() (Deforc, error) {
+	db, err := bin_file.openDatabase("/deforc")
+	return Deforc{db}, err
+}
+
+func (d Deforc) Force(elementID int64) ([][3]float64, error) {
+	return d.readVector(elementID, "x_force", "y_force", "z_force")
+}
+
+func (d Deforc) ResultantForce(elementID int64) ([]float64, error) {
+	return d.readColumn("resultant_force", elementID)
+}
+
+func (d Deforc) Displacement(elementID int64) ([]float64, error) {
+	return d.readColumn("displacement", elementID)
+}
+
+// Jntforc opens the joints of jntforc. Newer versions of LS-Dyna write them
+// into a "joints" sub folder
+func (bin_file Binout) Jntforc() (Jntforc, error) {
+	path := "/jntforc"
+	if _, err := bin_file.GetNumTimesteps("/jntforc/joints"); err == nil {
+		path = "/jntforc/joints"
+	}
+
+	db, err := bin_file.openDatabase(path)
+	return Jntforc{db}, err
+}
+
+func (j Jntforc) Force(jointID int64) ([][3]float64, error) {
+	return j.readVector(jointID, "x_force", "y_force", "z_force")
+}
+
+func (j Jntforc) Moment(jointID int64) ([][3]float64, error) {
+	return j.readVector(jointID, "x_moment", "y_moment", "z_moment")
+}
+
+func (j Jntforc) ResultantForce(jointID int64) ([]float64, error) {
+	return j.readColumn("resultant_force", jointID)
+}
+
+func (j Jntforc) ResultantMoment(jointID int64) ([]float64, error) {
+	return j.readColumn("resultant_moment", jointID)
+}
+
+func (bin_file Binout) Secforc() (Secforc, error) {
+	db, err := bin_file.openDatabase("/secforc")
+	return Secforc{db}, err
+}
+
+func (s Secforc) Force(sectionID int64) ([][3]float64, error) {
+	return s.readVector(sectionID, "x_force", "y_force", "z_force")
+}
+
+func (s Secforc) Moment(sectionID int64) ([][3]float64, error) {
+	return s.readVector(sectionID, "x_moment", "y_moment", "z_moment")
+}
+
+func (s Secforc) Centroid(sectionID int64) ([][3]float64, error) {
+	return s.readVector(sectionID, "x_centroid", "y_centroid", "z_centroid")
+}
+
+func (s Secforc) Area(sectionID int64) ([]float64, error) {
+	return s.readColumn("area", sectionID)
+}
+
+func (s Secforc) ResultantForce(sectionID int64) ([]float64, error) {
+	force, err := s.Force(sectionID)
+	if err != nil {
+		return nil, err
+	}
+	return vectorMagnitudes(force), nil
+}
@@ -0,0 +1,225 @@
+package dynareadout
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	GlstatCheckHourglassRatio = iota
+	GlstatCheckSlidingRatio
+	GlstatCheckEnergyRatio
+)
+
+// Glstat holds the global statistics of the glstat database. Variables which
+// have not been written by the solver are nil
+type Glstat struct {
+	Time                     []float64
+	TimeStep                 []float64
+	KineticEnergy            []float64
+	InternalEnergy           []float64
+	TotalEnergy              []float64
+	EnergyRatio              []float64
+	EnergyRatioWithoutEroded []float64
+	HourglassEnergy          []float64
+	SlidingInterfaceEnergy   []float64
+	StonewallEnergy          []float64
+	SpringAndDamperEnergy    []float64
+	SystemDampingEnergy      []float64
+	JointInternalEnergy      []float64
+	ExternalWork             []float64
+	ErodedKineticEnergy      []float64
+	ErodedInternalEnergy     []float64
+	ErodedHourglassEnergy    []float64
+	AddedMass                []float64
+	PercentMassIncrease      []float64
+}
+
+// GlstatEnergyLimits configures the thresholds of Glstat.CheckEnergyBalance.
+// A limit of zero disables the corresponding check
+type GlstatEnergyLimits struct {
+	// Maximum of hourglass energy / internal energy
+	MaxHourglassRatio float64
+	// Maximum of |sliding interface energy| / total energy
+	MaxSlidingRatio float64
+	// Allowed range of the energy ratio
+	MinEnergyRatio float64
+	MaxEnergyRatio float64
+}
+
+// GlstatWarning describes a violated limit of Glstat.CheckEnergyBalance
+type GlstatWarning struct {
+	Check int
+	// The first time at which the limit has been exceeded
+	FirstTime float64
+	// The time and value of the worst violation
+	Time  float64
+	Value float64
+	Limit float64
+}
+
+func DefaultGlstatEnergyLimits() GlstatEnergyLimits {
+	return GlstatEnergyLimits{
+		MaxHourglassRatio: 0.1,
+		MaxSlidingRatio:   0.1,
+		MinEnergyRatio:    0.9,
+		MaxEnergyRatio:    1.1,
+	}
+}
+
+func (bin_file Binout) Glstat() (Glstat, error) {
+	var glstat Glstat
+
+	db, err := bin_file.openDatabase("/glstat")
+	if err != nil {
+		return glstat, err
+	}
+
+	glstat.Time, err = db.Time()
+	if err != nil {
+		return glstat, err
+	}
+
+	variables := []struct {
+		name string
+		dst  *[]float64
+	}{
+		{"time_step", &glstat.TimeStep},
+		{"kinetic_energy", &glstat.KineticEnergy},
+		{"internal_energy", &glstat.InternalEnergy},
+		{"total_energy", &glstat.TotalEnergy},
+		{"energy_ratio", &glstat.EnergyRatio},
+		{"energy_ratio_wo_eroded", &glstat.EnergyRatioWithoutEroded},
+		{"hourglass_energy", &glstat.HourglassEnergy},
+		{"sliding_interface_energy", &glstat.SlidingInterfaceEnergy},
+		{"stonewall_energy", &glstat.StonewallEnergy},
+		{"spring_and_damper_energy", &glstat.SpringAndDamperEnergy},
+		{"system_damping_energy", &glstat.SystemDampingEnergy},
+		{"joint_internal_energy", &glstat.JointInternalEnergy},
+		{"external_work", &glstat.ExternalWork},
+		{"eroded_kinetic_energy", &glstat.ErodedKineticEnergy},
+		{"eroded_internal_energy", &glstat.ErodedInternalEnergy},
+		{"eroded_hourglass_energy", &glstat.ErodedHourglassEnergy},
+		{"added_mass", &glstat.AddedMass},
+		{"percent_increase", &glstat.PercentMassIncrease},
+	}
+
+	for _, v := range variables {
+		if _, _, _, err := bin_file.SimplePathToReal(db.path + "/" + v.name); err != nil {
+			continue
+		}
+
+		data, err := db.ReadVariable(v.name)
+		if err != nil {
+			return glstat, err
+		}
+		*v.dst, err = binoutColumn(data, 0, db.path+"/"+v.name)
+		if err != nil {
+			return glstat, err
+		}
+	}
+
+	return glstat, nil
+}
+
+// CheckEnergyBalance checks the hourglass and sliding energy ratios and the
+// energy ratio against the given limits. For every violated limit one warning
+// is returned
+func (g Glstat) CheckEnergyBalance(limits GlstatEnergyLimits) []GlstatWarning {
+	var warnings []GlstatWarning
+
+	if limits.MaxHourglassRatio > 0 {
+		if w, ok := g.checkRatio(GlstatCheckHourglassRatio, g.HourglassEnergy, g.InternalEnergy, limits.MaxHourglassRatio); ok {
+			warnings = append(warnings, w)
+		}
+	}
+	if limits.MaxSlidingRatio > 0 {
+		if w, ok := g.checkRatio(GlstatCheckSlidingRatio, g.SlidingInterfaceEnergy, g.TotalEnergy, limits.MaxSlidingRatio); ok {
+			warnings = append(warnings, w)
+		}
+	}
+	if limits.MinEnergyRatio > 0 || limits.MaxEnergyRatio > 0 {
+		if w, ok := g.checkEnergyRatio(limits.MinEnergyRatio, limits.MaxEnergyRatio); ok {
+			warnings = append(warnings, w)
+		}
+	}
+
+	return warnings
+}
+
+func (g Glstat) checkRatio(check int, numerator, denominator []float64, limit float64) (GlstatWarning, bool) {
+	w := GlstatWarning{
+		Check: check,
+		Limit: limit,
+	}
+	violated := false
+
+	for t := 0; t < len(numerator) && t < len(denominator) && t < len(g.Time); t++ {
+		if denominator[t] <= 0 {
+			continue
+		}
+
+		ratio := math.Abs(numerator[t]) / denominator[t]
+		if ratio <= limit {
+			continue
+		}
+
+		if !violated {
+			violated = true
+			w.FirstTime = g.Time[t]
+		}
+		if ratio > w.Value {
+			w.Value = ratio
+			w.Time = g.Time[t]
+		}
+	}
+
+	return w, violated
+}
+
+func (g Glstat) checkEnergyRatio(min, max float64) (GlstatWarning, bool) {
+	w := GlstatWarning{
+		Check: GlstatCheckEnergyRatio,
+	}
+	violated := false
+	worstDeviation := 0.0
+
+	for t := 0; t < len(g.EnergyRatio) && t < len(g.Time); t++ {
+		ratio := g.EnergyRatio[t]
+
+		var limit float64
+		if min > 0 && ratio < min {
+			limit = min
+		} else if max > 0 && ratio > max {
+			limit = max
+		} else {
+			continue
+		}
+
+		if !violated {
+			violated = true
+			w.FirstTime = g.Time[t]
+		}
+		if deviation := math.Abs(ratio - limit); deviation > worstDeviation {
+			worstDeviation = deviation
+			w.Value = ratio
+			w.Limit = limit
+			w.Time = g.Time[t]
+		}
+	}
+
+	return w, violated
+}
+
+func (w GlstatWarning) String() string {
+	switch w.Check {
+	case GlstatCheckHourglassRatio:
+		return fmt.Sprintf("The hourglass energy ratio exceeds %g starting at time %g (maximum %g at time %g)", w.Limit, w.FirstTime, w.Value, w.Time)
+	case GlstatCheckSlidingRatio:
+		return fmt.Sprintf("The sliding interface energy ratio exceeds %g starting at time %g (maximum %g at time %g)", w.Limit, w.FirstTime, w.Value, w.Time)
+	case GlstatCheckEnergyRatio:
+		return fmt.Sprintf("The energy ratio leaves the allowed range starting at time %g (worst %g at time %g, limit %g)", w.FirstTime, w.Value, w.Time, w.Limit)
+	default:
+		return fmt.Sprintf("Unknown glstat check %d", w.Check)
+	}
+}
@@ -0,0 +1,40 @@
+package dynareadout
+
+// Nodout reads the nodal time histories of the nodout database. All values are
+// addressed by node ids
+type Nodout struct {
+	binoutDatabase
+}
+
+func (bin_file Binout) Nodout() (Nodout, error) {
+	db, err := bin_file.openDatabase("/nodout")
+	return Nodout{db}, err
+}
+
+func (n Nodout) Displacement(nodeID int64) ([][3]float64, error) {
+	return n.readVector(nodeID, "x_displacement", "y_displacement", "z_displacement")
+}
+
+func (n Nodout) Velocity(nodeID int64) ([][3]float64, error) {
+	return n.readVector(nodeID, "x_velocity", "y_velocity", "z_velocity")
+}
+
+func (n Nodout) Acceleration(nodeID int64) ([][3]float64, error) {
+	return n.readVector(nodeID, "x_acceleration", "y_acceleration", "z_acceleration")
+}
+
+func (n Nodout) Coordinates(nodeID int64) ([][3]float64, error) {
+	return n.readVector(nodeID, "x_coordinate", "y_coordinate", "z_coordinate")
+}
+
+func (n Nodout) RotationalDisplacement(nodeID int64) ([][3]float64, error) {
+	return n.readVector(nodeID, "rx_displacement", "ry_displacement", "rz_displacement")
+}
+
+func (n Nodout) RotationalVelocity(nodeID int64) ([][3]float64, error) {
+	return n.readVector(nodeID, "rx_velocity", "ry_velocity", "rz_velocity")
+}
+
+func (n Nodout) RotationalAcceleration(nodeID int64) ([][3]float64, error) {
+	return n.readVector(nodeID, "rx_acceleration", "ry_acceleration", "rz_acceleration")
+}
@@ -0,0 +1,145 @@
+package dynareadout
+
+import "fmt"
+
+// The values of metadata/side of rcforc
+const (
+	RcforcSideSlave  = 0
+	RcforcSideMaster = 1
+)
+
+// Rcforc reads the resultant interface forces of the rcforc database. Every
+// contact interface is written twice, once for the slave and once for the
+// master side, which is why the series are addressed by interface id and side
+type Rcforc struct {
+	binoutDatabase
+	interfaceIDs []int64
+	sides        map[rcforcEntry]int
+}
+
+type rcforcEntry struct {
+	id   int64
+	side int
+}
+
+func (bin_file Binout) Rcforc() (Rcforc, error) {
+	var rcforc Rcforc
+
+	db, err := bin_file.openDatabase("/rcforc")
+	if err != nil {
+		return rcforc, err
+	}
+	rcforc.binoutDatabase = db
+	rcforc.sides = make(map[rcforcEntry]int)
+
+	var sides []int64
+	sidePath := db.path + "/metadata/side"
+	if bin_file.VariableExists(sidePath) {
+		sides, err = bin_file.readInt64(sidePath)
+		if err != nil {
+			return rcforc, err
+		}
+		if len(sides) != len(db.ids) {
+			return rcforc, fmt.Errorf("\"%s\" contains %d values instead of %d", sidePath, len(sides), len(db.ids))
+		}
+	}
+
+	known := make(map[int64]bool)
+	for i, id := range db.ids {
+		side := RcforcSideSlave
+		if sides != nil {
+			side = int(sides[i])
+		}
+
+		entry := rcforcEntry{id, side}
+		if _, ok := rcforc.sides[entry]; ok {
+			continue
+		}
+		rcforc.sides[entry] = i
+
+		if !known[id] {
+			known[id] = true
+			rcforc.interfaceIDs = append(rcforc.interfaceIDs, id)
+		}
+	}
+
+	return rcforc, nil
+}
+
+// InterfaceIDs returns the ids of all contact interfaces without duplicates
+func (r Rcforc) InterfaceIDs() []int64 {
+	return r.interfaceIDs
+}
+
+// HasSide returns whether a series of the given interface and side exists
+func (r Rcforc) HasSide(interfaceID int64, side int) bool {
+	_, ok := r.Index(interfaceID, side)
+	return ok
+}
+
+// Titles maps all interface ids to the titles of the interfaces
+func (r Rcforc) Titles() map[int64]string {
+	titles := make(map[int64]string, len(r.interfaceIDs))
+	for _, id := range r.interfaceIDs {
+		titles[id] = r.Title(id)
+	}
+	return titles
+}
+
+func (r Rcforc) Force(interfaceID int64, side int) ([][3]float64, error) {
+	return r.readSideVector(interfaceID, side, "x_force", "y_force", "z_force")
+}
+
+func (r Rcforc) Moment(interfaceID int64, side int) ([][3]float64, error) {
+	return r.readSideVector(interfaceID, side, "x_moment", "y_moment", "z_moment")
+}
+
+// ResultantForce returns the magnitude of the force vector over time
+func (r Rcforc) ResultantForce(interfaceID int64, side int) ([]float64, error) {
+	force, err := r.Force(interfaceID, side)
+	if err != nil {
+		return nil, err
+	}
+
+	return vectorMagnitudes(force), nil
+}
+
+func (r Rcforc) Mass(interfaceID int64, side int) ([]float64, error) {
+	return r.ReadID("mass", interfaceID, side)
+}
+
+// Index returns the index of the given interface and side inside of IDs. It
+// shadows the index by id, because every interface id appears once per side
+func (r Rcforc) Index(interfaceID int64, side int) (int, bool) {
+	index, ok := r.sides[rcforcEntry{interfaceID, side}]
+	return index, ok
+}
+
+// ReadID reads a timed variable of rcforc and returns the values of the given
+// interface and side over all timesteps
+func (r Rcforc) ReadID(variable string, interfaceID int64, side int) ([]float64, error) {
+	index, ok := r.Index(interfaceID, side)
+	if !ok {
+		return nil, fmt.Errorf("The interface %d with side %d does not exist in \"%s\"", interfaceID, side, r.path)
+	}
+
+	data, err := r.ReadVariable(variable)
+	if err != nil {
+		return nil, err
+	}
+
+	return binoutColumn(data, index, r.path+"/"+variable)
+}
+
+func (r Rcforc) readSideVector(interfaceID int64, side int, x, y, z string) ([][3]float64, error) {
+	var components [3][]float64
+	for i, variable := range [3]string{x, y, z} {
+		column, err := r.ReadID(variable, interfaceID, side)
+		if err != nil {
+			return nil, err
+		}
+		components[i] = column
+	}
+
+	return combineVector(components), nil
+}
@@ -0,0 +1,119 @@
+package dynareadout
+
+/*
+#cgo CFLAGS: -ansi
+#include "dynareadout/src/binout.h"
+#include "dynareadout/src/binout_defines.h"
+
+static const binout_file_t *binout_stat(binout_file *bin_file, const char *path) {
+  path_view_t pv = path_view_new(path);
+  return binout_directory_get_file(&bin_file->directory, &pv);
+}
+
+static const binout_file_state *binout_file_state_at(const binout_file *bin_file, size_t index) {
+  return &bin_file->file_states[index];
+}
+*/
+import "C"
+
+import (
+	"strings"
+	"unsafe"
+)
+
+// BinoutFileInfo holds the header of one file of a binout family
+type BinoutFileInfo struct {
+	FileName string
+	// The number of bytes which have been parsed (see Refresh)
+	Size                   int64
+	HeaderSize             uint8
+	RecordLengthFieldSize  uint8
+	RecordOffsetFieldSize  uint8
+	RecordCommandFieldSize uint8
+	RecordTypeIDFieldSize  uint8
+	BigEndian              bool
+	FloatFormat            uint8
+}
+
+// BinoutStat describes where the data of a variable is stored
+type BinoutStat struct {
+	Path   string
+	TypeID uint64
+	// The size of the data in bytes
+	Size        uint64
+	NumElements uint64
+	// The index into Files and the name of the file which contains the data
+	FileIndex int
+	FileName  string
+	// The position of the data inside of the file
+	Offset int64
+}
+
+// Files returns the headers of all opened files of the family. The files are
+// ordered like the FileIndex of Stat.
+func (bin_file Binout) Files() ([]BinoutFileInfo, error) {
+	handle, err := bin_file.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer bin_file.unlock()
+
+	files := make([]BinoutFileInfo, handle.num_files)
+	for i := range files {
+		state := C.binout_file_state_at(handle, C.size_t(i))
+		header := &state.header
+
+		files[i] = BinoutFileInfo{
+			FileName:               C.GoString(state.file_name),
+			Size:                   int64(state.parsed_size),
+			HeaderSize:             uint8(header.header_size),
+			RecordLengthFieldSize:  uint8(header.record_length_field_size),
+			RecordOffsetFieldSize:  uint8(header.record_offset_field_size),
+			RecordCommandFieldSize: uint8(header.record_command_field_size),
+			RecordTypeIDFieldSize:  uint8(header.record_typeid_field_size),
+			BigEndian:              state.big_endian != 0,
+			FloatFormat:            uint8(header.float_format),
+		}
+	}
+
+	return files, nil
+}
+
+// Stat returns the type, size and location of a variable
+func (bin_file Binout) Stat(path string) (BinoutStat, error) {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	handle, err := bin_file.lock()
+	if err != nil {
+		return BinoutStat{}, err
+	}
+	defer bin_file.unlock()
+
+	pathC := C.CString(path)
+	fileC := C.binout_stat(handle, pathC)
+	C.free(unsafe.Pointer(pathC))
+
+	if fileC == nil {
+		return BinoutStat{}, newKindError(ErrPathNotFound, "The variable \"%s\" does not exist", path)
+	}
+
+	stat := BinoutStat{
+		Path:      path,
+		TypeID:    uint64(fileC.var_type),
+		Size:      uint64(fileC.size),
+		FileIndex: int(fileC.file_index),
+		Offset:    int64(fileC.file_pos),
+	}
+
+	if typeSize := uint64(C._binout_get_type_size(C.uint64_t(stat.TypeID))); typeSize != 0 {
+		stat.NumElements = stat.Size / typeSize
+	}
+
+	if fileNames := binoutFileNames(handle); stat.FileIndex < len(fileNames) {
+		stat.FileName = fileNames[stat.FileIndex]
+	}
+
+	return stat, nil
+}
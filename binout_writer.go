@@ -0,0 +1,416 @@
+package dynareadout
+
+/*
+#include "dynareadout/src/binout.h"
+#include "dynareadout/src/binout_defines.h"
+*/
+import "C"
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"strings"
+)
+
+var errBinoutWriterClosed = errors.New("The binout writer has already been closed")
+
+// The name of a variable is prefixed by its length as one byte
+const binoutMaxNameLength = math.MaxUint8
+
+// BinoutWriterOptions configures the layout of the records written by
+// BinoutWriter. The field sizes are given in bytes and need to be between 1
+// and 8.
+type BinoutWriterOptions struct {
+	ByteOrder              binary.ByteOrder
+	RecordLengthFieldSize  uint8
+	RecordOffsetFieldSize  uint8
+	RecordCommandFieldSize uint8
+	RecordTypeIDFieldSize  uint8
+}
+
+// DefaultBinoutWriterOptions returns the layout which is written by LS-Dyna on
+// little endian machines
+func DefaultBinoutWriterOptions() BinoutWriterOptions {
+	return BinoutWriterOptions{
+		ByteOrder:              binary.LittleEndian,
+		RecordLengthFieldSize:  8,
+		RecordOffsetFieldSize:  8,
+		RecordCommandFieldSize: 1,
+		RecordTypeIDFieldSize:  1,
+	}
+}
+
+// BinoutWriter writes a binout file which can be read by BinoutOpen. Data is
+// written into the folder selected with Cd. Timed data is written into
+// dxxxxxx folders which are created by BeginTimestep. Close writes the symbol
+// table (the VARIABLE records of all variables) like LS-Dyna does, so that
+// the file can also be opened by LS-PrePost. The offset of the symbol table
+// is written into the record after the header, which is only possible if the
+// underlying writer can seek (e.g. a regular *os.File). Otherwise the offset
+// stays zero.
+type BinoutWriter struct {
+	w         *bufio.Writer
+	seeker    io.WriteSeeker
+	closer    io.Closer
+	options   BinoutWriterOptions
+	path      string
+	timesteps map[string]int
+	err       error
+
+	// start is the position of the header inside of seeker and offset the
+	// number of bytes which have been written since
+	start   int64
+	offset  uint64
+	symbols []binoutSymbol
+	// The position of the field which points to the symbol table
+	symbolTableField uint64
+}
+
+// binoutSymbol is the entry of one variable in the symbol table. offset is
+// the position of the DATA record and length the number of values.
+type binoutSymbol struct {
+	folder string
+	name   string
+	typeID uint64
+	offset uint64
+	length uint64
+}
+
+// BinoutCreate creates (or truncates) the file fileName and writes the binout
+// header into it
+func BinoutCreate(fileName string) (*BinoutWriter, error) {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	writer, err := NewBinoutWriter(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	writer.closer = file
+
+	return writer, nil
+}
+
+// NewBinoutWriter writes the binout header into w and returns a writer which
+// writes records into w. Close needs to be called to flush the data.
+func NewBinoutWriter(w io.Writer) (*BinoutWriter, error) {
+	return NewBinoutWriterWithOptions(w, DefaultBinoutWriterOptions())
+}
+
+// NewBinoutWriterWithOptions is the same as NewBinoutWriter, but writes the
+// records with the byte order and field sizes of options
+func NewBinoutWriterWithOptions(w io.Writer, options BinoutWriterOptions) (*BinoutWriter, error) {
+	for _, size := range [4]uint8{
+		options.RecordLengthFieldSize,
+		options.RecordOffsetFieldSize,
+		options.RecordCommandFieldSize,
+		options.RecordTypeIDFieldSize,
+	} {
+		if size == 0 || size > 8 {
+			return nil, fmt.Errorf("Invalid field size %d", size)
+		}
+	}
+
+	var endianess byte
+	switch options.ByteOrder {
+	case binary.LittleEndian:
+		endianess = C.BINOUT_HEADER_LITTLE_ENDIAN
+	case binary.BigEndian:
+		endianess = C.BINOUT_HEADER_BIG_ENDIAN
+	default:
+		return nil, errors.New("The byte order needs to be binary.LittleEndian or binary.BigEndian")
+	}
+
+	writer := &BinoutWriter{
+		w:         bufio.NewWriter(w),
+		options:   options,
+		path:      "/",
+		timesteps: make(map[string]int),
+	}
+	// Pipes are files which can not seek
+	if seeker, ok := w.(io.WriteSeeker); ok {
+		if start, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			writer.seeker, writer.start = seeker, start
+		}
+	}
+
+	header := [C.sizeof_binout_header]byte{
+		C.sizeof_binout_header,
+		options.RecordLengthFieldSize,
+		options.RecordOffsetFieldSize,
+		options.RecordCommandFieldSize,
+		options.RecordTypeIDFieldSize,
+		endianess,
+		C.BINOUT_HEADER_FLOAT_IEEE,
+		0,
+	}
+	if _, err := writer.w.Write(header[:]); err != nil {
+		return nil, err
+	}
+	writer.offset = uint64(len(header))
+
+	// The offset of the symbol table is not known yet, it is filled in by Close
+	writer.symbolTableField = writer.offset + uint64(options.RecordLengthFieldSize) + uint64(options.RecordCommandFieldSize)
+	if err := writer.writeRecord(C.BINOUT_COMMAND_SYMBOLTABLEOFFSET, writer.offsetField(0)); err != nil {
+		return nil, err
+	}
+
+	return writer, nil
+}
+
+// Close writes the symbol table, flushes all records and closes the file if it
+// has been created by BinoutCreate
+func (writer *BinoutWriter) Close() error {
+	err := writer.err
+	if err == errBinoutWriterClosed {
+		return err
+	}
+	var tableOffset uint64
+	if err == nil {
+		tableOffset, err = writer.writeSymbolTable()
+	}
+	if flushErr := writer.w.Flush(); err == nil {
+		err = flushErr
+	}
+	if err == nil {
+		err = writer.writeSymbolTableOffset(tableOffset)
+	}
+	if writer.closer != nil {
+		if closeErr := writer.closer.Close(); err == nil {
+			err = closeErr
+		}
+		writer.closer = nil
+	}
+	writer.err = errBinoutWriterClosed
+	return err
+}
+
+// Path returns the folder into which data is currently written
+func (writer *BinoutWriter) Path() string {
+	return writer.path
+}
+
+// Cd changes the folder into which data is written. folder can be absolute or
+// relative to the current folder (including "..").
+func (writer *BinoutWriter) Cd(folder string) error {
+	if !strings.HasPrefix(folder, "/") {
+		folder = writer.path + "/" + folder
+	}
+	folder = path.Clean(folder)
+	if folder == writer.path {
+		return nil
+	}
+
+	if err := writer.writeRecord(C.BINOUT_COMMAND_CD, []byte(folder)); err != nil {
+		return err
+	}
+	writer.path = folder
+	return nil
+}
+
+// BeginTimestep creates the next dxxxxxx folder of the database dbPath (e.g.
+// "/nodout"), changes into it and writes the variable "time". Every timed
+// variable written afterwards belongs to this timestep. The path of the new
+// folder is returned.
+func (writer *BinoutWriter) BeginTimestep(dbPath string, time float64) (string, error) {
+	dbPath = path.Clean("/" + dbPath)
+	writer.timesteps[dbPath]++
+	folder := fmt.Sprintf("%s/d%06d", dbPath, writer.timesteps[dbPath])
+
+	if err := writer.Cd(folder); err != nil {
+		return "", err
+	}
+	if err := writer.WriteFloat64("time", []float64{time}); err != nil {
+		return "", err
+	}
+
+	return folder, nil
+}
+
+func (writer *BinoutWriter) WriteInt8(name string, data []int8) error {
+	return writeBinoutData(writer, name, BinoutTypeInt8, data)
+}
+
+func (writer *BinoutWriter) WriteInt16(name string, data []int16) error {
+	return writeBinoutData(writer, name, BinoutTypeInt16, data)
+}
+
+func (writer *BinoutWriter) WriteInt32(name string, data []int32) error {
+	return writeBinoutData(writer, name, BinoutTypeInt32, data)
+}
+
+func (writer *BinoutWriter) WriteInt64(name string, data []int64) error {
+	return writeBinoutData(writer, name, BinoutTypeInt64, data)
+}
+
+func (writer *BinoutWriter) WriteUint8(name string, data []uint8) error {
+	return writeBinoutData(writer, name, BinoutTypeUint8, data)
+}
+
+func (writer *BinoutWriter) WriteUint16(name string, data []uint16) error {
+	return writeBinoutData(writer, name, BinoutTypeUint16, data)
+}
+
+func (writer *BinoutWriter) WriteUint32(name string, data []uint32) error {
+	return writeBinoutData(writer, name, BinoutTypeUint32, data)
+}
+
+func (writer *BinoutWriter) WriteUint64(name string, data []uint64) error {
+	return writeBinoutData(writer, name, BinoutTypeUint64, data)
+}
+
+func (writer *BinoutWriter) WriteFloat32(name string, data []float32) error {
+	return writeBinoutData(writer, name, BinoutTypeFloat32, data)
+}
+
+func (writer *BinoutWriter) WriteFloat64(name string, data []float64) error {
+	return writeBinoutData(writer, name, BinoutTypeFloat64, data)
+}
+
+// WriteString writes str as an int8 variable like LS-Dyna does for titles and
+// legends
+func (writer *BinoutWriter) WriteString(name string, str string) error {
+	data := make([]int8, len(str))
+	for i := 0; i < len(str); i++ {
+		data[i] = int8(str[i])
+	}
+	return writer.WriteInt8(name, data)
+}
+
+func writeBinoutData[T goType](writer *BinoutWriter, name string, typeID uint64, data []T) error {
+	if writer.path == "/" {
+		return fmt.Errorf("The variable \"%s\" can not be written into the root folder", name)
+	}
+	if len(name) == 0 || len(name) > binoutMaxNameLength || strings.Contains(name, "/") {
+		return fmt.Errorf("Invalid variable name \"%s\"", name)
+	}
+
+	var buffer bytes.Buffer
+	writer.writeField(&buffer, typeID, writer.options.RecordTypeIDFieldSize)
+	buffer.WriteByte(byte(len(name)))
+	buffer.WriteString(name)
+	if err := binary.Write(&buffer, writer.options.ByteOrder, data); err != nil {
+		return err
+	}
+
+	symbol := binoutSymbol{
+		folder: writer.path,
+		name:   name,
+		typeID: typeID,
+		offset: writer.offset,
+		length: uint64(len(data)),
+	}
+	if err := writer.writeRecord(C.BINOUT_COMMAND_DATA, buffer.Bytes()); err != nil {
+		return err
+	}
+	writer.symbols = append(writer.symbols, symbol)
+	return nil
+}
+
+// writeSymbolTable writes one VARIABLE record for every variable and returns
+// the offset of the table. The records are preceded by a CD record whenever
+// the folder changes.
+func (writer *BinoutWriter) writeSymbolTable() (uint64, error) {
+	tableOffset := writer.offset
+	if err := writer.writeRecord(C.BINOUT_COMMAND_BEGINSYMBOLTABLE, nil); err != nil {
+		return 0, err
+	}
+
+	folder := ""
+	for _, symbol := range writer.symbols {
+		if symbol.folder != folder {
+			if err := writer.writeRecord(C.BINOUT_COMMAND_CD, []byte(symbol.folder)); err != nil {
+				return 0, err
+			}
+			folder = symbol.folder
+		}
+
+		var buffer bytes.Buffer
+		buffer.WriteByte(byte(len(symbol.name)))
+		buffer.WriteString(symbol.name)
+		writer.writeField(&buffer, symbol.typeID, writer.options.RecordTypeIDFieldSize)
+		writer.writeField(&buffer, symbol.offset, writer.options.RecordOffsetFieldSize)
+		writer.writeField(&buffer, symbol.length, writer.options.RecordLengthFieldSize)
+		if err := writer.writeRecord(C.BINOUT_COMMAND_VARIABLE, buffer.Bytes()); err != nil {
+			return 0, err
+		}
+	}
+
+	// The offset of the next symbol table, which does not exist
+	if err := writer.writeRecord(C.BINOUT_COMMAND_ENDSYMBOLTABLE, writer.offsetField(0)); err != nil {
+		return 0, err
+	}
+
+	return tableOffset, nil
+}
+
+// writeSymbolTableOffset fills in the offset of the symbol table into the
+// record after the header. It needs to be called after all records have been
+// flushed.
+func (writer *BinoutWriter) writeSymbolTableOffset(tableOffset uint64) error {
+	seeker := writer.seeker
+	if seeker == nil {
+		return nil
+	}
+
+	if _, err := seeker.Seek(writer.start+int64(writer.symbolTableField), io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := seeker.Write(writer.offsetField(tableOffset)); err != nil {
+		return err
+	}
+	_, err := seeker.Seek(0, io.SeekEnd)
+	return err
+}
+
+// offsetField returns value as an offset field of the writer
+func (writer *BinoutWriter) offsetField(value uint64) []byte {
+	var buffer bytes.Buffer
+	writer.writeField(&buffer, value, writer.options.RecordOffsetFieldSize)
+	return buffer.Bytes()
+}
+
+func (writer *BinoutWriter) writeRecord(command uint64, data []byte) error {
+	if writer.err != nil {
+		return writer.err
+	}
+
+	var buffer bytes.Buffer
+	length := uint64(writer.options.RecordLengthFieldSize) + uint64(writer.options.RecordCommandFieldSize) + uint64(len(data))
+	if writer.options.RecordLengthFieldSize < 8 && length >= 1<<(8*writer.options.RecordLengthFieldSize) {
+		return fmt.Errorf("The record with %d bytes is too large for a length field of %d bytes", length, writer.options.RecordLengthFieldSize)
+	}
+	writer.writeField(&buffer, length, writer.options.RecordLengthFieldSize)
+	writer.writeField(&buffer, command, writer.options.RecordCommandFieldSize)
+	buffer.Write(data)
+
+	if _, err := writer.w.Write(buffer.Bytes()); err != nil {
+		writer.err = err
+		return err
+	}
+	writer.offset += uint64(buffer.Len())
+	return nil
+}
+
+// writeField writes the lowest size bytes of value in the byte order of the
+// writer
+func (writer *BinoutWriter) writeField(buffer *bytes.Buffer, value uint64, size uint8) {
+	var bytes [8]byte
+	if writer.options.ByteOrder == binary.ByteOrder(binary.BigEndian) {
+		binary.BigEndian.PutUint64(bytes[:], value)
+		buffer.Write(bytes[8-size:])
+	} else {
+		binary.LittleEndian.PutUint64(bytes[:], value)
+		buffer.Write(bytes[:size])
+	}
+}
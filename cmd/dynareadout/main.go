@@ -0,0 +1,174 @@
+// Command dynareadout is a command line tool for working with the binary output
+// files of LS-Dyna.
+//
+// Usage:
+//
+//	dynareadout extract -o <output> <binout> <selection>...
+//	dynareadout compare [-abs <tolerance>] [-rel <tolerance>] <binout> <binout>
+//
+// A selection is a folder, a variable or a timed variable of the binout (e.g.
+// "/glstat" or "nodout/x_displacement") which can optionally be followed by a
+// list of ids (e.g. "/nodout:1001,1002").
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	dro "github.com/PucklaJ/dynareadout_go"
+)
+
+type command struct {
+	name        string
+	description string
+	run         func(args []string) error
+}
+
+var commands = []command{
+	{"extract", "Copy selected databases or variables into a new binout", extract},
+	{"compare", "Report the differences between two binouts", compare},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			if err := c.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", c.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Unknown command \"%s\"\n", os.Args[1])
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: dynareadout <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.description)
+	}
+}
+
+func extract(args []string) error {
+	flags := flag.NewFlagSet("extract", flag.ExitOnError)
+	output := flags.String("o", "", "The file name of the new binout")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: dynareadout extract -o <output> <binout> <selection>...")
+		fmt.Fprintln(flags.Output(), "A selection is a path optionally followed by ids, e.g. \"/glstat\" or \"/nodout:1001,1002\"")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if *output == "" || flags.NArg() < 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	selections := make([]dro.BinoutSelection, flags.NArg()-1)
+	for i, arg := range flags.Args()[1:] {
+		selection, err := parseSelection(arg)
+		if err != nil {
+			return err
+		}
+		selections[i] = selection
+	}
+
+	binFile, err := openBinout(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer binFile.Close()
+
+	return binFile.ExtractFile(*output, selections...)
+}
+
+// openBinout opens a binout and only fails if none of its files could be
+// opened. All other file errors are printed as warnings.
+func openBinout(fileName string) (dro.Binout, error) {
+	binFile, err := dro.BinoutOpen(fileName)
+	if err != nil {
+		var openErr *dro.BinoutOpenError
+		if !errors.As(err, &openErr) || openErr.Fatal {
+			binFile.Close()
+			return binFile, err
+		}
+		for _, fileErr := range openErr.FileErrors {
+			fmt.Fprintln(os.Stderr, "Warning:", fileErr)
+		}
+	}
+	return binFile, nil
+}
+
+// parseSelection parses "path" or "path:id,id,..."
+func parseSelection(arg string) (dro.BinoutSelection, error) {
+	selection := dro.BinoutSelection{Path: arg}
+
+	colon := strings.LastIndexByte(arg, ':')
+	if colon == -1 {
+		return selection, nil
+	}
+
+	selection.Path = arg[:colon]
+	for _, idStr := range strings.Split(arg[colon+1:], ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(idStr), 10, 64)
+		if err != nil {
+			return selection, fmt.Errorf("Invalid id \"%s\" in \"%s\"", idStr, arg)
+		}
+		selection.IDs = append(selection.IDs, id)
+	}
+
+	return selection, nil
+}
+
+func compare(args []string) error {
+	flags := flag.NewFlagSet("compare", flag.ExitOnError)
+	var options dro.BinoutCompareOptions
+	flags.Float64Var(&options.AbsoluteTolerance, "abs", 0.0, "The absolute tolerance")
+	flags.Float64Var(&options.RelativeTolerance, "rel", 0.0, "The relative tolerance")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: dynareadout compare [-abs <tolerance>] [-rel <tolerance>] <binout> <binout>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	a, err := openBinout(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	b, err := openBinout(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	report, err := dro.CompareBinouts(a, b, options)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(report)
+	if !report.Equal() {
+		return fmt.Errorf("The binouts differ")
+	}
+	return nil
+}
@@ -8,40 +8,137 @@ package dynareadout
 import "C"
 
 import (
-	"errors"
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
 	"time"
 	"unsafe"
 )
 
+// D3plot is an opened d3plot family. Its methods can be called from multiple
+// goroutines at the same time. Copies of a D3plot share the opened files, so
+// closing or refreshing one of them affects all of them.
 type D3plot struct {
+	h        *d3plotHandle
+	fileName string
+}
+
+type d3plotHandle struct {
+	// mtx is locked for reading by every method and for writing by Close and
+	// Refresh
+	mtx    sync.RWMutex
 	handle C.d3plot_file
+	closed bool
+
+	// index is built by the first call to Index
+	indexMtx sync.Mutex
+	index    *D3plotIndex
 }
 
-func D3plotOpen(fileName string) (plotFile D3plot, err error) {
+func D3plotOpen(fileName string) (D3plot, error) {
 	fileNameC := C.CString(fileName)
-
-	plotFile.handle = C.d3plot_open(fileNameC)
+	handle := C.d3plot_open(fileNameC)
 	C.free(unsafe.Pointer(fileNameC))
 
-	if plotFile.handle.error_string != nil {
-		err = errors.New(C.GoString(plotFile.handle.error_string))
-		C.d3plot_close(&plotFile.handle)
+	if handle.error_string != nil {
+		err := d3plotError(&handle)
+		C.d3plot_close(&handle)
+		return D3plot{}, err
+	}
+
+	plotFile := D3plot{
+		h:        &d3plotHandle{handle: handle},
+		fileName: fileName,
 	}
+	setLeakFinalizer(plotFile.h, fmt.Sprintf("D3plot \"%s\"", fileName), (*d3plotHandle).close)
 
-	return
+	return plotFile, nil
 }
 
+// Close closes the files of plotFile and all of its copies. It waits for
+// running reads and can be called multiple times. All other methods return
+// ErrClosed afterwards.
 func (plotFile D3plot) Close() {
-	C.d3plot_close(&plotFile.handle)
+	if plotFile.h != nil {
+		plotFile.h.close()
+	}
+}
+
+func (h *d3plotHandle) close() {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	if !h.closed {
+		C.d3plot_close(&h.handle)
+		h.closed = true
+	}
+	runtime.SetFinalizer(h, nil)
+}
+
+// lock returns a copy of the C handle on which one method can work without
+// interfering with other goroutines. The copy needs to be released with
+// unlock.
+func (plotFile D3plot) lock() (C.d3plot_file, error) {
+	if plotFile.h == nil {
+		return C.d3plot_file{}, ErrClosed
+	}
+
+	plotFile.h.mtx.RLock()
+	if plotFile.h.closed {
+		plotFile.h.mtx.RUnlock()
+		return C.d3plot_file{}, ErrClosed
+	}
+	return plotFile.h.handle, nil
+}
+
+func (plotFile D3plot) unlock(handle *C.d3plot_file) {
+	if handle.error_string != nil {
+		C.free(unsafe.Pointer(handle.error_string))
+	}
+	plotFile.h.mtx.RUnlock()
+}
+
+// Refresh reads the states which have been written since the d3plot has been
+// opened (or since the last Refresh), including the states of new files of the
+// family. A state which is still being written is left for the next Refresh.
+func (plotFile D3plot) Refresh() error {
+	if plotFile.h == nil {
+		return ErrClosed
+	}
+
+	h := plotFile.h
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	if h.closed {
+		return ErrClosed
+	}
+
+	C.d3plot_refresh(&h.handle)
+	if h.handle.error_string != nil {
+		err := d3plotError(&h.handle)
+		// The error must not stay in the handle, since every method works on a
+		// copy of it which would free the error again
+		C.free(unsafe.Pointer(h.handle.error_string))
+		h.handle.error_string = nil
+		return err
+	}
+	return nil
 }
 
 func (plotFile D3plot) ReadNodeIDs() ([]uint64, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numIds C.size_t
-	dataC := C.d3plot_read_node_ids(&plotFile.handle, &numIds)
+	dataC := C.d3plot_read_node_ids(&handle, &numIds)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	if numIds == 0 {
@@ -54,12 +151,17 @@ func (plotFile D3plot) ReadNodeIDs() ([]uint64, error) {
 }
 
 func (plotFile D3plot) ReadSolidElementIDs() ([]uint64, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numIds C.size_t
-	dataC := C.d3plot_read_solid_element_ids(&plotFile.handle, &numIds)
+	dataC := C.d3plot_read_solid_element_ids(&handle, &numIds)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	if numIds == 0 {
@@ -72,12 +174,17 @@ func (plotFile D3plot) ReadSolidElementIDs() ([]uint64, error) {
 }
 
 func (plotFile D3plot) ReadBeamElementIDs() ([]uint64, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numIds C.size_t
-	dataC := C.d3plot_read_beam_element_ids(&plotFile.handle, &numIds)
+	dataC := C.d3plot_read_beam_element_ids(&handle, &numIds)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	if numIds == 0 {
@@ -90,12 +197,17 @@ func (plotFile D3plot) ReadBeamElementIDs() ([]uint64, error) {
 }
 
 func (plotFile D3plot) ReadShellElementIDs() ([]uint64, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numIds C.size_t
-	dataC := C.d3plot_read_shell_element_ids(&plotFile.handle, &numIds)
+	dataC := C.d3plot_read_shell_element_ids(&handle, &numIds)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	if numIds == 0 {
@@ -108,12 +220,17 @@ func (plotFile D3plot) ReadShellElementIDs() ([]uint64, error) {
 }
 
 func (plotFile D3plot) ReadThickShellElementIDs() ([]uint64, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numIds C.size_t
-	dataC := C.d3plot_read_thick_shell_element_ids(&plotFile.handle, &numIds)
+	dataC := C.d3plot_read_thick_shell_element_ids(&handle, &numIds)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	if numIds == 0 {
@@ -126,12 +243,17 @@ func (plotFile D3plot) ReadThickShellElementIDs() ([]uint64, error) {
 }
 
 func (plotFile D3plot) ReadAllElementIDs() ([]uint64, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numIds C.size_t
-	dataC := C.d3plot_read_all_element_ids(&plotFile.handle, &numIds)
+	dataC := C.d3plot_read_all_element_ids(&handle, &numIds)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	if numIds == 0 {
@@ -144,12 +266,17 @@ func (plotFile D3plot) ReadAllElementIDs() ([]uint64, error) {
 }
 
 func (plotFile D3plot) ReadPartIDs() ([]uint64, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numIds C.size_t
-	dataC := C.d3plot_read_part_ids(&plotFile.handle, &numIds)
+	dataC := C.d3plot_read_part_ids(&handle, &numIds)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	if numIds == 0 {
@@ -162,12 +289,17 @@ func (plotFile D3plot) ReadPartIDs() ([]uint64, error) {
 }
 
 func (plotFile D3plot) ReadPartTitles() ([]string, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numTitles C.size_t
-	dataC := C.d3plot_read_part_titles(&plotFile.handle, &numTitles)
+	dataC := C.d3plot_read_part_titles(&handle, &numTitles)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	if numTitles == 0 {
@@ -187,12 +319,17 @@ func (plotFile D3plot) ReadPartTitles() ([]string, error) {
 
 // TODO: Implement bindings for the 32-Bit variants
 func (plotFile D3plot) ReadNodeCoordinates(state uint64) ([][3]float64, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numNodes C.size_t
-	dataC := C.d3plot_read_node_coordinates(&plotFile.handle, C.size_t(state), &numNodes)
+	dataC := C.d3plot_read_node_coordinates(&handle, C.size_t(state), &numNodes)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	if numNodes == 0 {
@@ -213,12 +350,17 @@ func (plotFile D3plot) ReadNodeCoordinates(state uint64) ([][3]float64, error) {
 }
 
 func (plotFile D3plot) ReadAllNodeCoordinates() ([][][3]float64, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numNodes, numTimeSteps C.size_t
-	dataC := C.d3plot_read_all_node_coordinates(&plotFile.handle, &numNodes, &numTimeSteps)
+	dataC := C.d3plot_read_all_node_coordinates(&handle, &numNodes, &numTimeSteps)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	if numNodes == 0 || numTimeSteps == 0 {
@@ -237,20 +379,31 @@ func (plotFile D3plot) ReadAllNodeCoordinates() ([][][3]float64, error) {
 			timeStep[n][2] = float64(carrIdx(nodePtr, 2))
 		}
 
-		coords = append(coords, timeStep)
+		coords[t] = timeStep
 	}
 	C.free(unsafe.Pointer(dataC))
 
 	return coords, nil
 }
 
+// ReadAllNodeCoordinatesContext is the same as ReadAllNodeCoordinates, but reads the states one
+// after another and stops as soon as ctx is done
+func (plotFile D3plot) ReadAllNodeCoordinatesContext(ctx context.Context) ([][][3]float64, error) {
+	return readAllStatesContext(ctx, plotFile, plotFile.ReadNodeCoordinates)
+}
+
 func (plotFile D3plot) ReadNodeVelocity(state uint64) ([][3]float64, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numNodes C.size_t
-	dataC := C.d3plot_read_node_velocity(&plotFile.handle, C.size_t(state), &numNodes)
+	dataC := C.d3plot_read_node_velocity(&handle, C.size_t(state), &numNodes)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	if numNodes == 0 {
@@ -271,12 +424,17 @@ func (plotFile D3plot) ReadNodeVelocity(state uint64) ([][3]float64, error) {
 }
 
 func (plotFile D3plot) ReadAllNodeVelocity() ([][][3]float64, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numNodes, numTimeSteps C.size_t
-	dataC := C.d3plot_read_all_node_velocity(&plotFile.handle, &numNodes, &numTimeSteps)
+	dataC := C.d3plot_read_all_node_velocity(&handle, &numNodes, &numTimeSteps)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	if numNodes == 0 || numTimeSteps == 0 {
@@ -295,20 +453,31 @@ func (plotFile D3plot) ReadAllNodeVelocity() ([][][3]float64, error) {
 			timeStep[n][2] = float64(carrIdx(nodePtr, 2))
 		}
 
-		velocities = append(velocities, timeStep)
+		velocities[t] = timeStep
 	}
 	C.free(unsafe.Pointer(dataC))
 
 	return velocities, nil
 }
 
+// ReadAllNodeVelocityContext is the same as ReadAllNodeVelocity, but reads the states one
+// after another and stops as soon as ctx is done
+func (plotFile D3plot) ReadAllNodeVelocityContext(ctx context.Context) ([][][3]float64, error) {
+	return readAllStatesContext(ctx, plotFile, plotFile.ReadNodeVelocity)
+}
+
 func (plotFile D3plot) ReadNodeAcceleration(state uint64) ([][3]float64, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numNodes C.size_t
-	dataC := C.d3plot_read_node_acceleration(&plotFile.handle, C.size_t(state), &numNodes)
+	dataC := C.d3plot_read_node_acceleration(&handle, C.size_t(state), &numNodes)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	if numNodes == 0 {
@@ -329,12 +498,17 @@ func (plotFile D3plot) ReadNodeAcceleration(state uint64) ([][3]float64, error)
 }
 
 func (plotFile D3plot) ReadAllNodeAcceleration() ([][][3]float64, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numNodes, numTimeSteps C.size_t
-	dataC := C.d3plot_read_all_node_acceleration(&plotFile.handle, &numNodes, &numTimeSteps)
+	dataC := C.d3plot_read_all_node_acceleration(&handle, &numNodes, &numTimeSteps)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	if numNodes == 0 || numTimeSteps == 0 {
@@ -353,30 +527,46 @@ func (plotFile D3plot) ReadAllNodeAcceleration() ([][][3]float64, error) {
 			timeStep[n][2] = float64(carrIdx(nodePtr, 2))
 		}
 
-		accelerations = append(accelerations, timeStep)
+		accelerations[t] = timeStep
 	}
 	C.free(unsafe.Pointer(dataC))
 
 	return accelerations, nil
 }
 
+// ReadAllNodeAccelerationContext is the same as ReadAllNodeAcceleration, but reads the states one
+// after another and stops as soon as ctx is done
+func (plotFile D3plot) ReadAllNodeAccelerationContext(ctx context.Context) ([][][3]float64, error) {
+	return readAllStatesContext(ctx, plotFile, plotFile.ReadNodeAcceleration)
+}
+
 func (plotFile D3plot) ReadTime(state uint64) (float64, error) {
-	timeC := C.d3plot_read_time(&plotFile.handle, C.size_t(state))
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return float64(timeC), err
+	handle, err := plotFile.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer plotFile.unlock(&handle)
+
+	timeC := C.d3plot_read_time(&handle, C.size_t(state))
+	if handle.error_string != nil {
+		return float64(timeC), d3plotError(&handle)
 	}
 
 	return float64(timeC), nil
 }
 
 func (plotFile D3plot) ReadAllTime() ([]float64, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numStates C.size_t
-	dataC := C.d3plot_read_all_time(&plotFile.handle, &numStates)
+	dataC := C.d3plot_read_all_time(&handle, &numStates)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	times := make([]float64, numStates)
@@ -389,12 +579,17 @@ func (plotFile D3plot) ReadAllTime() ([]float64, error) {
 }
 
 func (plotFile D3plot) ReadSolidsState(state uint64) ([]C.d3plot_solid, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numSolids C.size_t
-	dataC := C.d3plot_read_solids_state(&plotFile.handle, C.size_t(state), &numSolids)
+	dataC := C.d3plot_read_solids_state(&handle, C.size_t(state), &numSolids)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	if numSolids == 0 {
@@ -412,12 +607,17 @@ func (plotFile D3plot) ReadSolidsState(state uint64) ([]C.d3plot_solid, error) {
 
 // TODO: Make separate struct to wrap around c type so that history variables can be read
 func (plotFile D3plot) ReadThickShellsState(state uint64) ([]C.d3plot_thick_shell, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numThickShells, numHistoryVariables C.size_t
-	dataC := C.d3plot_read_thick_shells_state(&plotFile.handle, C.size_t(state), &numThickShells, &numHistoryVariables)
+	dataC := C.d3plot_read_thick_shells_state(&handle, C.size_t(state), &numThickShells, &numHistoryVariables)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	if numThickShells == 0 {
@@ -434,12 +634,17 @@ func (plotFile D3plot) ReadThickShellsState(state uint64) ([]C.d3plot_thick_shel
 }
 
 func (plotFile D3plot) ReadBeamsState(state uint64) ([]C.d3plot_beam, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numBeams C.size_t
-	dataC := C.d3plot_read_beams_state(&plotFile.handle, C.size_t(state), &numBeams)
+	dataC := C.d3plot_read_beams_state(&handle, C.size_t(state), &numBeams)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	if numBeams == 0 {
@@ -457,12 +662,17 @@ func (plotFile D3plot) ReadBeamsState(state uint64) ([]C.d3plot_beam, error) {
 
 // TODO: Make separate struct to wrap around c type so that history variables can be read
 func (plotFile D3plot) ReadShellsState(state uint64) ([]C.d3plot_shell, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numShells, numHistoryVariables C.size_t
-	dataC := C.d3plot_read_shells_state(&plotFile.handle, C.size_t(state), &numShells, &numHistoryVariables)
+	dataC := C.d3plot_read_shells_state(&handle, C.size_t(state), &numShells, &numHistoryVariables)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	if numShells == 0 {
@@ -479,12 +689,17 @@ func (plotFile D3plot) ReadShellsState(state uint64) ([]C.d3plot_shell, error) {
 }
 
 func (plotFile D3plot) ReadSolidElements() ([]C.d3plot_solid_con, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numSolids C.size_t
-	dataC := C.d3plot_read_solid_elements(&plotFile.handle, &numSolids)
+	dataC := C.d3plot_read_solid_elements(&handle, &numSolids)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	if numSolids == 0 {
@@ -501,12 +716,17 @@ func (plotFile D3plot) ReadSolidElements() ([]C.d3plot_solid_con, error) {
 }
 
 func (plotFile D3plot) ReadThickShellElements() ([]C.d3plot_thick_shell_con, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numThickShells C.size_t
-	dataC := C.d3plot_read_thick_shell_elements(&plotFile.handle, &numThickShells)
+	dataC := C.d3plot_read_thick_shell_elements(&handle, &numThickShells)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	if numThickShells == 0 {
@@ -523,12 +743,17 @@ func (plotFile D3plot) ReadThickShellElements() ([]C.d3plot_thick_shell_con, err
 }
 
 func (plotFile D3plot) ReadBeamElements() ([]C.d3plot_beam_con, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numBeams C.size_t
-	dataC := C.d3plot_read_beam_elements(&plotFile.handle, &numBeams)
+	dataC := C.d3plot_read_beam_elements(&handle, &numBeams)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	if numBeams == 0 {
@@ -545,12 +770,17 @@ func (plotFile D3plot) ReadBeamElements() ([]C.d3plot_beam_con, error) {
 }
 
 func (plotFile D3plot) ReadShellElements() ([]C.d3plot_shell_con, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numShells C.size_t
-	dataC := C.d3plot_read_shell_elements(&plotFile.handle, &numShells)
+	dataC := C.d3plot_read_shell_elements(&handle, &numShells)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	if numShells == 0 {
@@ -567,11 +797,16 @@ func (plotFile D3plot) ReadShellElements() ([]C.d3plot_shell_con, error) {
 }
 
 func (plotFile D3plot) ReadTitle() (string, error) {
-	titleC := C.d3plot_read_title(&plotFile.handle)
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
+	handle, err := plotFile.lock()
+	if err != nil {
 		return "", err
 	}
+	defer plotFile.unlock(&handle)
+
+	titleC := C.d3plot_read_title(&handle)
+	if handle.error_string != nil {
+		return "", d3plotError(&handle)
+	}
 
 	title := C.GoString(titleC)
 	C.free(unsafe.Pointer(titleC))
@@ -579,12 +814,17 @@ func (plotFile D3plot) ReadTitle() (string, error) {
 }
 
 func (plotFile D3plot) ReadRunTime() (time.Time, error) {
-	dataC := C.d3plot_read_run_time(&plotFile.handle)
-
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
+	handle, err := plotFile.lock()
+	if err != nil {
 		return time.Time{}, err
 	}
+	defer plotFile.unlock(&handle)
+
+	dataC := C.d3plot_read_run_time(&handle)
+
+	if handle.error_string != nil {
+		return time.Time{}, d3plotError(&handle)
+	}
 
 	t := time.Date(
 		int(dataC.tm_year+1900),
@@ -600,18 +840,29 @@ func (plotFile D3plot) ReadRunTime() (time.Time, error) {
 }
 
 func (plotFile D3plot) ReadPart(partIndex uint64) (D3plotPart, error) {
-	var part D3plotPart
+	handle, err := plotFile.lock()
+	if err != nil {
+		return D3plotPart{}, err
+	}
+	defer plotFile.unlock(&handle)
 
-	part.handle = C.d3plot_read_part(&plotFile.handle, C.size_t(partIndex))
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return part, err
+	part := D3plotPart{nodes: new(d3plotPartNodes)}
+
+	part.handle = C.d3plot_read_part(&handle, C.size_t(partIndex))
+	if handle.error_string != nil {
+		return part, d3plotError(&handle)
 	}
 
 	return part, nil
 }
 
 func (plotFile D3plot) ReadPartByID(partID uint64, partIDs []uint64) (D3plotPart, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return D3plotPart{}, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var cPartIDs *C.d3_word
 	var cNumPartIDs C.size_t
 
@@ -620,21 +871,71 @@ func (plotFile D3plot) ReadPartByID(partID uint64, partIDs []uint64) (D3plotPart
 		cNumPartIDs = C.size_t(len(partIDs))
 	}
 
-	var part D3plotPart
+	part := D3plotPart{nodes: new(d3plotPartNodes)}
 
-	part.handle = C.d3plot_read_part_by_id(&plotFile.handle, C.d3_word(partID), cPartIDs, cNumPartIDs)
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return part, err
+	part.handle = C.d3plot_read_part_by_id(&handle, C.d3_word(partID), cPartIDs, cNumPartIDs)
+	if handle.error_string != nil {
+		return part, d3plotError(&handle)
 	}
 
 	return part, nil
 }
 
 func (plotFile D3plot) NumTimeSteps() uint64 {
-	return uint64(plotFile.handle.num_states)
+	handle, err := plotFile.lock()
+	if err != nil {
+		return 0
+	}
+	defer plotFile.unlock(&handle)
+
+	return uint64(handle.num_states)
+}
+
+// HasNodeVelocity returns whether the states contain the velocities of the
+// nodes. ReadNodeVelocity does not return valid data otherwise.
+func (plotFile D3plot) HasNodeVelocity() bool {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return false
+	}
+	defer plotFile.unlock(&handle)
+
+	return handle.control_data.iv != 0
 }
 
+// HasNodeAcceleration returns whether the states contain the accelerations of
+// the nodes. ReadNodeAcceleration does not return valid data otherwise.
+func (plotFile D3plot) HasNodeAcceleration() bool {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return false
+	}
+	defer plotFile.unlock(&handle)
+
+	return handle.control_data.ia != 0
+}
+
+// D3plotIndexForID returns the index of id in the sorted IDs or D3plotNotFound.
+// D3plot.Index does not need sorted IDs and is faster for multiple lookups.
 func D3plotIndexForID(id uint64, IDs []uint64) uint64 {
+	if len(IDs) == 0 {
+		return D3plotNotFound
+	}
 	return uint64(C.d3plot_index_for_id(C.d3_word(id), (*C.d3_word)(&IDs[0]), C.size_t(len(IDs))))
 }
+
+func readAllStatesContext[T any](ctx context.Context, plotFile D3plot, read func(uint64) (T, error)) ([]T, error) {
+	data := make([]T, plotFile.NumTimeSteps())
+	for i := range data {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var err error
+		data[i], err = read(uint64(i))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,203 @@
+package dynareadout
+
+/*
+#cgo CFLAGS: -ansi
+#include "dynareadout/src/d3plot.h"
+
+static void tensor_to_array(const d3plot_tensor *t, double *a) {
+  a[0] = t->x;
+  a[1] = t->y;
+  a[2] = t->z;
+  a[3] = t->xy;
+  a[4] = t->yz;
+  a[5] = t->zx;
+}
+
+static void surface_to_array(const d3plot_surface *s, double *a) {
+  tensor_to_array(&s->stress, a);
+  a[6] = s->effective_plastic_strain;
+}
+
+static void solid_to_array(const d3plot_solid *s, double *a) {
+  tensor_to_array(&s->stress, a);
+  a[6] = s->effective_plastic_strain;
+  tensor_to_array(&s->strain, &a[7]);
+}
+
+static void shell_to_array(const d3plot_shell *s, double *a) {
+  surface_to_array(&s->mid, a);
+  surface_to_array(&s->inner, &a[7]);
+  surface_to_array(&s->outer, &a[14]);
+  tensor_to_array(&s->inner_strain, &a[21]);
+  tensor_to_array(&s->outer_strain, &a[27]);
+  a[33] = s->thickness;
+  a[34] = s->internal_energy;
+}
+
+static void thick_shell_to_array(const d3plot_thick_shell *s, double *a) {
+  surface_to_array(&s->mid, a);
+  surface_to_array(&s->inner, &a[7]);
+  surface_to_array(&s->outer, &a[14]);
+  tensor_to_array(&s->inner_strain, &a[21]);
+  tensor_to_array(&s->outer_strain, &a[27]);
+}
+*/
+import "C"
+
+// D3plotTensor holds the components xx, yy, zz, xy, yz, zx of a symmetric
+// tensor
+type D3plotTensor [6]float64
+
+type D3plotSolidState struct {
+	Stress                 D3plotTensor
+	EffectivePlasticStrain float64
+	Strain                 D3plotTensor
+}
+
+type D3plotSurfaceState struct {
+	Stress                 D3plotTensor
+	EffectivePlasticStrain float64
+}
+
+type D3plotShellState struct {
+	Mid            D3plotSurfaceState
+	Inner          D3plotSurfaceState
+	Outer          D3plotSurfaceState
+	InnerStrain    D3plotTensor
+	OuterStrain    D3plotTensor
+	Thickness      float64
+	InternalEnergy float64
+}
+
+type D3plotThickShellState struct {
+	Mid         D3plotSurfaceState
+	Inner       D3plotSurfaceState
+	Outer       D3plotSurfaceState
+	InnerStrain D3plotTensor
+	OuterStrain D3plotTensor
+}
+
+type D3plotBeamState struct {
+	AxialForce         float64
+	SShearResultant    float64
+	TShearResultant    float64
+	SBendingMoment     float64
+	TBendingMoment     float64
+	TorsionalResultant float64
+}
+
+// ReadSolidStates is the same as ReadSolidsState, but returns go types
+func (plotFile D3plot) ReadSolidStates(state uint64) ([]D3plotSolidState, error) {
+	solidsC, err := plotFile.ReadSolidsState(state)
+	if err != nil {
+		return nil, err
+	}
+
+	return solidStatesFromC(solidsC), nil
+}
+
+func solidStatesFromC(solidsC []C.d3plot_solid) []D3plotSolidState {
+	solids := make([]D3plotSolidState, len(solidsC))
+	var a [13]C.double
+	for i := range solidsC {
+		C.solid_to_array(&solidsC[i], &a[0])
+		solids[i].Stress = tensorFromArray(a[0:6])
+		solids[i].EffectivePlasticStrain = float64(a[6])
+		solids[i].Strain = tensorFromArray(a[7:13])
+	}
+
+	return solids
+}
+
+// ReadShellStates is the same as ReadShellsState, but returns go types
+func (plotFile D3plot) ReadShellStates(state uint64) ([]D3plotShellState, error) {
+	shellsC, err := plotFile.ReadShellsState(state)
+	if err != nil {
+		return nil, err
+	}
+
+	return shellStatesFromC(shellsC), nil
+}
+
+func shellStatesFromC(shellsC []C.d3plot_shell) []D3plotShellState {
+	shells := make([]D3plotShellState, len(shellsC))
+	var a [35]C.double
+	for i := range shellsC {
+		C.shell_to_array(&shellsC[i], &a[0])
+		shells[i].Mid = surfaceFromArray(a[0:7])
+		shells[i].Inner = surfaceFromArray(a[7:14])
+		shells[i].Outer = surfaceFromArray(a[14:21])
+		shells[i].InnerStrain = tensorFromArray(a[21:27])
+		shells[i].OuterStrain = tensorFromArray(a[27:33])
+		shells[i].Thickness = float64(a[33])
+		shells[i].InternalEnergy = float64(a[34])
+	}
+
+	return shells
+}
+
+// ReadThickShellStates is the same as ReadThickShellsState, but returns go
+// types
+func (plotFile D3plot) ReadThickShellStates(state uint64) ([]D3plotThickShellState, error) {
+	thickShellsC, err := plotFile.ReadThickShellsState(state)
+	if err != nil {
+		return nil, err
+	}
+
+	return thickShellStatesFromC(thickShellsC), nil
+}
+
+func thickShellStatesFromC(thickShellsC []C.d3plot_thick_shell) []D3plotThickShellState {
+	thickShells := make([]D3plotThickShellState, len(thickShellsC))
+	var a [33]C.double
+	for i := range thickShellsC {
+		C.thick_shell_to_array(&thickShellsC[i], &a[0])
+		thickShells[i].Mid = surfaceFromArray(a[0:7])
+		thickShells[i].Inner = surfaceFromArray(a[7:14])
+		thickShells[i].Outer = surfaceFromArray(a[14:21])
+		thickShells[i].InnerStrain = tensorFromArray(a[21:27])
+		thickShells[i].OuterStrain = tensorFromArray(a[27:33])
+	}
+
+	return thickShells
+}
+
+// ReadBeamStates is the same as ReadBeamsState, but returns go types
+func (plotFile D3plot) ReadBeamStates(state uint64) ([]D3plotBeamState, error) {
+	beamsC, err := plotFile.ReadBeamsState(state)
+	if err != nil {
+		return nil, err
+	}
+
+	return beamStatesFromC(beamsC), nil
+}
+
+func beamStatesFromC(beamsC []C.d3plot_beam) []D3plotBeamState {
+	beams := make([]D3plotBeamState, len(beamsC))
+	for i, b := range beamsC {
+		beams[i] = D3plotBeamState{
+			AxialForce:         float64(b.axial_force),
+			SShearResultant:    float64(b.s_shear_resultant),
+			TShearResultant:    float64(b.t_shear_resultant),
+			SBendingMoment:     float64(b.s_bending_moment),
+			TBendingMoment:     float64(b.t_bending_moment),
+			TorsionalResultant: float64(b.torsional_resultant),
+		}
+	}
+
+	return beams
+}
+
+func tensorFromArray(a []C.double) (t D3plotTensor) {
+	for i := range t {
+		t[i] = float64(a[i])
+	}
+	return
+}
+
+func surfaceFromArray(a []C.double) D3plotSurfaceState {
+	return D3plotSurfaceState{
+		Stress:                 tensorFromArray(a[0:6]),
+		EffectivePlasticStrain: float64(a[6]),
+	}
+}
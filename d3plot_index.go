@@ -0,0 +1,142 @@
+package dynareadout
+
+import "math"
+
+// D3plotNotFound is returned by the lookups of D3plotIDMap and by
+// D3plotIndexForID for IDs and indices which do not exist
+const D3plotNotFound = math.MaxUint64
+
+// D3plotIndex maps the IDs of the nodes, elements and parts of a d3plot to
+// their indices and back. The indices are the ones of the states (e.g.
+// ReadNodeCoordinates) and of ReadPart. It is never modified, so it can be
+// used from multiple goroutines.
+type D3plotIndex struct {
+	Nodes       D3plotIDMap
+	Solids      D3plotIDMap
+	ThickShells D3plotIDMap
+	Beams       D3plotIDMap
+	Shells      D3plotIDMap
+	Parts       D3plotIDMap
+}
+
+// D3plotIDMap maps the IDs of one kind of entity to their indices and back
+type D3plotIDMap struct {
+	ids     []uint64
+	indices map[uint64]uint64
+}
+
+// Index returns the index of the d3plot. It is built by the first call and
+// reused by all others (and all copies of plotFile).
+func (plotFile D3plot) Index() (*D3plotIndex, error) {
+	if plotFile.h == nil {
+		return nil, ErrClosed
+	}
+
+	h := plotFile.h
+	h.mtx.RLock()
+	closed := h.closed
+	h.mtx.RUnlock()
+	if closed {
+		return nil, ErrClosed
+	}
+
+	h.indexMtx.Lock()
+	defer h.indexMtx.Unlock()
+
+	if h.index != nil {
+		return h.index, nil
+	}
+
+	readers := [...]func() ([]uint64, error){
+		plotFile.ReadNodeIDs,
+		plotFile.ReadSolidElementIDs,
+		plotFile.ReadThickShellElementIDs,
+		plotFile.ReadBeamElementIDs,
+		plotFile.ReadShellElementIDs,
+		plotFile.ReadPartIDs,
+	}
+	var maps [len(readers)]D3plotIDMap
+	for i, read := range readers {
+		ids, err := read()
+		if err != nil {
+			return nil, err
+		}
+		maps[i] = newD3plotIDMap(ids)
+	}
+
+	h.index = &D3plotIndex{
+		Nodes:       maps[0],
+		Solids:      maps[1],
+		ThickShells: maps[2],
+		Beams:       maps[3],
+		Shells:      maps[4],
+		Parts:       maps[5],
+	}
+	return h.index, nil
+}
+
+func newD3plotIDMap(ids []uint64) D3plotIDMap {
+	m := D3plotIDMap{
+		ids:     ids,
+		indices: make(map[uint64]uint64, len(ids)),
+	}
+	for i, id := range ids {
+		// The first index wins if an ID exists multiple times
+		if _, ok := m.indices[id]; !ok {
+			m.indices[id] = uint64(i)
+		}
+	}
+	return m
+}
+
+// Len returns the number of IDs
+func (m D3plotIDMap) Len() int {
+	return len(m.ids)
+}
+
+// IDs returns all IDs ordered by their index. The slice must not be modified.
+func (m D3plotIDMap) IDs() []uint64 {
+	return m.ids
+}
+
+// Index returns the index of id
+func (m D3plotIDMap) Index(id uint64) (uint64, bool) {
+	index, ok := m.indices[id]
+	return index, ok
+}
+
+// ID returns the ID at index
+func (m D3plotIDMap) ID(index uint64) (uint64, bool) {
+	if index >= uint64(len(m.ids)) {
+		return 0, false
+	}
+	return m.ids[index], true
+}
+
+// Indices returns the index of every ID of ids or D3plotNotFound if it does
+// not exist
+func (m D3plotIDMap) Indices(ids []uint64) []uint64 {
+	indices := make([]uint64, len(ids))
+	for i, id := range ids {
+		index, ok := m.indices[id]
+		if !ok {
+			index = D3plotNotFound
+		}
+		indices[i] = index
+	}
+	return indices
+}
+
+// IDsAt returns the ID at every index of indices or D3plotNotFound if the
+// index is out of range
+func (m D3plotIDMap) IDsAt(indices []uint64) []uint64 {
+	ids := make([]uint64, len(indices))
+	for i, index := range indices {
+		if index < uint64(len(m.ids)) {
+			ids[i] = m.ids[index]
+		} else {
+			ids[i] = D3plotNotFound
+		}
+	}
+	return ids
+}
@@ -2,16 +2,26 @@ package dynareadout
 
 /*
 #cgo CFLAGS: -ansi
+#include <stdlib.h>
 #include "dynareadout/src/d3plot.h"
 */
 import "C"
 import (
-	"errors"
+	"sync"
 	"unsafe"
 )
 
 type D3plotPart struct {
 	handle C.d3plot_part
+	// nodes caches the node indices for the part-scoped readers. It is shared
+	// by all copies of the part.
+	nodes *d3plotPartNodes
+}
+
+type d3plotPartNodes struct {
+	mtx     sync.Mutex
+	loaded  bool
+	indices []C.size_t
 }
 
 func (part D3plotPart) SolidID(index int) uint64 {
@@ -51,12 +61,17 @@ func (part D3plotPart) Free() {
 }
 
 func (part D3plotPart) GetNodeIDs(plotFile D3plot) ([]uint64, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numPartNodeIDs C.size_t
-	dataC := C.d3plot_part_get_node_ids2(&plotFile.handle, &part.handle, &numPartNodeIDs, nil, 0, nil, 0, nil, 0, nil, 0, nil, 0, nil, nil, nil, nil)
+	dataC := C.d3plot_part_get_node_ids2(&handle, &part.handle, &numPartNodeIDs, nil, 0, nil, 0, nil, 0, nil, 0, nil, 0, nil, nil, nil, nil)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	data := carrToSlice[C.d3_word, uint64](dataC, numPartNodeIDs)
@@ -65,12 +80,17 @@ func (part D3plotPart) GetNodeIDs(plotFile D3plot) ([]uint64, error) {
 }
 
 func (part D3plotPart) GetNodeIndices(plotFile D3plot) ([]uint64, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
 	var numPartNodeIDs C.size_t
-	dataC := C.d3plot_part_get_node_indices2(&plotFile.handle, &part.handle, &numPartNodeIDs, nil, 0, nil, 0, nil, 0, nil, 0, nil, nil, nil, nil)
+	dataC := C.d3plot_part_get_node_indices2(&handle, &part.handle, &numPartNodeIDs, nil, 0, nil, 0, nil, 0, nil, 0, nil, nil, nil, nil)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return nil, err
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
 	}
 
 	data := carrToSlice[C.d3_word, uint64](dataC, numPartNodeIDs)
@@ -79,8 +99,14 @@ func (part D3plotPart) GetNodeIndices(plotFile D3plot) ([]uint64, error) {
 }
 
 func (part D3plotPart) GetNumNodes(plotFile D3plot) (int, error) {
+	handle, err := plotFile.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer plotFile.unlock(&handle)
+
 	numNodes := C.d3plot_part_get_num_nodes2(
-		&plotFile.handle,
+		&handle,
 		&part.handle,
 		nil,
 		0,
@@ -96,10 +122,233 @@ func (part D3plotPart) GetNumNodes(plotFile D3plot) (int, error) {
 		nil,
 	)
 
-	if plotFile.handle.error_string != nil {
-		err := errors.New(C.GoString(plotFile.handle.error_string))
-		return 0, err
+	if handle.error_string != nil {
+		return 0, d3plotError(&handle)
 	}
 
 	return int(numNodes), nil
 }
+
+// SolidIndices returns the indices of the solids of the part into the solids
+// of the d3plot (e.g. of ReadSolidStates)
+func (part D3plotPart) SolidIndices() []uint64 {
+	return partIndices(part.handle.solid_indices, part.handle.num_solids)
+}
+
+// ThickShellIndices returns the indices of the thick shells of the part into
+// the thick shells of the d3plot
+func (part D3plotPart) ThickShellIndices() []uint64 {
+	return partIndices(part.handle.thick_shell_indices, part.handle.num_thick_shells)
+}
+
+// BeamIndices returns the indices of the beams of the part into the beams of
+// the d3plot
+func (part D3plotPart) BeamIndices() []uint64 {
+	return partIndices(part.handle.beam_indices, part.handle.num_beams)
+}
+
+// ShellIndices returns the indices of the shells of the part into the shells
+// of the d3plot
+func (part D3plotPart) ShellIndices() []uint64 {
+	return partIndices(part.handle.shell_indices, part.handle.num_shells)
+}
+
+// ReadNodeCoordinates is the same as D3plot.ReadNodeCoordinates, but only
+// reads the nodes of the part in the order of GetNodeIndices. The node indices
+// are only read by the first call of the part-scoped readers. plotFile needs to
+// be the d3plot from which the part has been read.
+func (part D3plotPart) ReadNodeCoordinates(plotFile D3plot, state uint64) ([][3]float64, error) {
+	return part.readNodes(plotFile, func(handle *C.d3plot_file, indices *C.size_t, numNodes C.size_t) *C.double {
+		return C.d3plot_read_node_coordinates_subset(handle, C.size_t(state), indices, numNodes)
+	})
+}
+
+// ReadNodeVelocity is the same as ReadNodeCoordinates, but for the velocities
+func (part D3plotPart) ReadNodeVelocity(plotFile D3plot, state uint64) ([][3]float64, error) {
+	return part.readNodes(plotFile, func(handle *C.d3plot_file, indices *C.size_t, numNodes C.size_t) *C.double {
+		return C.d3plot_read_node_velocity_subset(handle, C.size_t(state), indices, numNodes)
+	})
+}
+
+// ReadNodeAcceleration is the same as ReadNodeCoordinates, but for the
+// accelerations
+func (part D3plotPart) ReadNodeAcceleration(plotFile D3plot, state uint64) ([][3]float64, error) {
+	return part.readNodes(plotFile, func(handle *C.d3plot_file, indices *C.size_t, numNodes C.size_t) *C.double {
+		return C.d3plot_read_node_acceleration_subset(handle, C.size_t(state), indices, numNodes)
+	})
+}
+
+// ReadSolidsState is the same as D3plot.ReadSolidsState, but only reads the
+// solids of the part in the order of SolidIndices
+func (part D3plotPart) ReadSolidsState(plotFile D3plot, state uint64) ([]C.d3plot_solid, error) {
+	return readPartElements(plotFile, part.handle.num_solids, func(handle *C.d3plot_file) *C.d3plot_solid {
+		return C.d3plot_read_solids_state_subset(handle, C.size_t(state), part.handle.solid_indices, part.handle.num_solids)
+	})
+}
+
+// ReadThickShellsState is the same as D3plot.ReadThickShellsState, but only
+// reads the thick shells of the part in the order of ThickShellIndices
+func (part D3plotPart) ReadThickShellsState(plotFile D3plot, state uint64) ([]C.d3plot_thick_shell, error) {
+	return readPartElements(plotFile, part.handle.num_thick_shells, func(handle *C.d3plot_file) *C.d3plot_thick_shell {
+		var numHistoryVariables C.size_t
+		return C.d3plot_read_thick_shells_state_subset(handle, C.size_t(state), part.handle.thick_shell_indices, part.handle.num_thick_shells, &numHistoryVariables)
+	})
+}
+
+// ReadBeamsState is the same as D3plot.ReadBeamsState, but only reads the
+// beams of the part in the order of BeamIndices
+func (part D3plotPart) ReadBeamsState(plotFile D3plot, state uint64) ([]C.d3plot_beam, error) {
+	return readPartElements(plotFile, part.handle.num_beams, func(handle *C.d3plot_file) *C.d3plot_beam {
+		return C.d3plot_read_beams_state_subset(handle, C.size_t(state), part.handle.beam_indices, part.handle.num_beams)
+	})
+}
+
+// ReadShellsState is the same as D3plot.ReadShellsState, but only reads the
+// shells of the part in the order of ShellIndices
+func (part D3plotPart) ReadShellsState(plotFile D3plot, state uint64) ([]C.d3plot_shell, error) {
+	return readPartElements(plotFile, part.handle.num_shells, func(handle *C.d3plot_file) *C.d3plot_shell {
+		var numHistoryVariables C.size_t
+		return C.d3plot_read_shells_state_subset(handle, C.size_t(state), part.handle.shell_indices, part.handle.num_shells, &numHistoryVariables)
+	})
+}
+
+// ReadSolidStates is the same as ReadSolidsState, but returns go types
+func (part D3plotPart) ReadSolidStates(plotFile D3plot, state uint64) ([]D3plotSolidState, error) {
+	solids, err := part.ReadSolidsState(plotFile, state)
+	if err != nil {
+		return nil, err
+	}
+	return solidStatesFromC(solids), nil
+}
+
+// ReadThickShellStates is the same as ReadThickShellsState, but returns go
+// types
+func (part D3plotPart) ReadThickShellStates(plotFile D3plot, state uint64) ([]D3plotThickShellState, error) {
+	thickShells, err := part.ReadThickShellsState(plotFile, state)
+	if err != nil {
+		return nil, err
+	}
+	return thickShellStatesFromC(thickShells), nil
+}
+
+// ReadBeamStates is the same as ReadBeamsState, but returns go types
+func (part D3plotPart) ReadBeamStates(plotFile D3plot, state uint64) ([]D3plotBeamState, error) {
+	beams, err := part.ReadBeamsState(plotFile, state)
+	if err != nil {
+		return nil, err
+	}
+	return beamStatesFromC(beams), nil
+}
+
+// ReadShellStates is the same as ReadShellsState, but returns go types
+func (part D3plotPart) ReadShellStates(plotFile D3plot, state uint64) ([]D3plotShellState, error) {
+	shells, err := part.ReadShellsState(plotFile, state)
+	if err != nil {
+		return nil, err
+	}
+	return shellStatesFromC(shells), nil
+}
+
+// cachedNodeIndices returns GetNodeIndices, which is only read once per part
+func (part D3plotPart) cachedNodeIndices(plotFile D3plot) ([]C.size_t, error) {
+	if part.nodes == nil {
+		return part.readNodeIndices(plotFile)
+	}
+
+	part.nodes.mtx.Lock()
+	defer part.nodes.mtx.Unlock()
+
+	if !part.nodes.loaded {
+		indices, err := part.readNodeIndices(plotFile)
+		if err != nil {
+			return nil, err
+		}
+		part.nodes.indices = indices
+		part.nodes.loaded = true
+	}
+	return part.nodes.indices, nil
+}
+
+func (part D3plotPart) readNodeIndices(plotFile D3plot) ([]C.size_t, error) {
+	nodeIndices, err := part.GetNodeIndices(plotFile)
+	if err != nil {
+		return nil, err
+	}
+
+	indices := make([]C.size_t, len(nodeIndices))
+	for i, index := range nodeIndices {
+		indices[i] = C.size_t(index)
+	}
+	return indices, nil
+}
+
+// readNodes reads the nodes of the part of one state with read, which gets the
+// node indices of the part
+func (part D3plotPart) readNodes(plotFile D3plot, read func(*C.d3plot_file, *C.size_t, C.size_t) *C.double) ([][3]float64, error) {
+	indices, err := part.cachedNodeIndices(plotFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(indices) == 0 {
+		return [][3]float64{}, nil
+	}
+
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
+	dataC := read(&handle, &indices[0], C.size_t(len(indices)))
+
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
+	}
+
+	nodes := make([][3]float64, len(indices))
+	for i := range nodes {
+		nodePtr := carrIdxPtr(dataC, i*3)
+
+		nodes[i][0] = float64(*nodePtr)
+		nodes[i][1] = float64(carrIdx(nodePtr, 1))
+		nodes[i][2] = float64(carrIdx(nodePtr, 2))
+	}
+	C.free(unsafe.Pointer(dataC))
+
+	return nodes, nil
+}
+
+// readPartElements reads the numElements elements of the part of one state
+// with read, but only if the part contains any of them
+func readPartElements[T any](plotFile D3plot, numElements C.size_t, read func(*C.d3plot_file) *T) ([]T, error) {
+	if numElements == 0 {
+		return []T{}, nil
+	}
+
+	handle, err := plotFile.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer plotFile.unlock(&handle)
+
+	dataC := read(&handle)
+
+	if handle.error_string != nil {
+		return nil, d3plotError(&handle)
+	}
+
+	elements := make([]T, numElements)
+	copy(elements, unsafe.Slice(dataC, numElements))
+	C.free(unsafe.Pointer(dataC))
+
+	return elements, nil
+}
+
+func partIndices(indicesC *C.size_t, numIndices C.size_t) []uint64 {
+	indices := make([]uint64, numIndices)
+	for i, index := range unsafe.Slice(indicesC, numIndices) {
+		indices[i] = uint64(index)
+	}
+	return indices
+}
@@ -0,0 +1,156 @@
+package dynareadout
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// D3plotField selects a field of a state which is read by States, ReadState
+// and ReadStatesParallel
+type D3plotField int
+
+// The fields of a state which can be read by States
+const (
+	D3plotFieldCoordinates D3plotField = iota
+	D3plotFieldVelocities
+	D3plotFieldAccelerations
+	D3plotFieldSolids
+	D3plotFieldThickShells
+	D3plotFieldShells
+	D3plotFieldBeams
+)
+
+// D3plotState holds the data of one state read by States. Only the fields
+// which have been requested are set.
+type D3plotState struct {
+	Index         uint64
+	Time          float64
+	Coordinates   [][3]float64
+	Velocities    [][3]float64
+	Accelerations [][3]float64
+	Solids        []D3plotSolidState
+	ThickShells   []D3plotThickShellState
+	Shells        []D3plotShellState
+	Beams         []D3plotBeamState
+}
+
+// States reads the given fields (D3plotFieldCoordinates, ...) of every state
+// one after another and calls fn for each of them. Only one state is held in
+// memory at a time, so that d3plots with thousands of states can be processed.
+// The time of a state is always read. Reading stops at the first error
+// returned by fn or if ctx is done.
+func (plotFile D3plot) States(ctx context.Context, fn func(D3plotState) error, fields ...D3plotField) error {
+	for _, field := range fields {
+		if field < D3plotFieldCoordinates || field > D3plotFieldBeams {
+			return fmt.Errorf("Invalid d3plot field %d", field)
+		}
+	}
+
+	numStates := plotFile.NumTimeSteps()
+	for i := uint64(0); i < numStates; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		state, err := plotFile.ReadState(i, fields...)
+		if err != nil {
+			return err
+		}
+		if err := fn(state); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReadState reads the given fields (D3plotFieldCoordinates, ...) of one state
+func (plotFile D3plot) ReadState(index uint64, fields ...D3plotField) (state D3plotState, err error) {
+	state.Index = index
+	state.Time, err = plotFile.ReadTime(index)
+	if err != nil {
+		return
+	}
+
+	for _, field := range fields {
+		switch field {
+		case D3plotFieldCoordinates:
+			state.Coordinates, err = plotFile.ReadNodeCoordinates(index)
+		case D3plotFieldVelocities:
+			state.Velocities, err = plotFile.ReadNodeVelocity(index)
+		case D3plotFieldAccelerations:
+			state.Accelerations, err = plotFile.ReadNodeAcceleration(index)
+		case D3plotFieldSolids:
+			state.Solids, err = plotFile.ReadSolidStates(index)
+		case D3plotFieldThickShells:
+			state.ThickShells, err = plotFile.ReadThickShellStates(index)
+		case D3plotFieldShells:
+			state.Shells, err = plotFile.ReadShellStates(index)
+		case D3plotFieldBeams:
+			state.Beams, err = plotFile.ReadBeamStates(index)
+		default:
+			err = fmt.Errorf("Invalid d3plot field %d", field)
+		}
+
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// ReadStatesParallel reads the given fields of the states on workers
+// goroutines and calls fn for each of them. fn is called concurrently and in
+// no particular order. If workers is not positive, runtime.NumCPU() goroutines
+// are used. Reading stops at the first error, which is returned.
+func (plotFile D3plot) ReadStatesParallel(states []uint64, workers int, fn func(D3plotState) error, fields ...D3plotField) error {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(states) {
+		workers = len(states)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var firstErr error
+	var errOnce sync.Once
+	indices := make(chan uint64)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for index := range indices {
+				state, err := plotFile.ReadState(index, fields...)
+				if err == nil {
+					err = fn(state)
+				}
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, index := range states {
+		select {
+		case indices <- index:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indices)
+	wg.Wait()
+
+	return firstErr
+}
@@ -0,0 +1,57 @@
+package dynareadout
+
+import (
+	"context"
+	"time"
+)
+
+// The interval in which Watch looks for new states
+const D3plotWatchInterval = time.Second
+
+// Watch is the same as WatchInterval with D3plotWatchInterval
+func (plotFile D3plot) Watch(ctx context.Context) (<-chan uint64, <-chan error) {
+	return plotFile.WatchInterval(ctx, D3plotWatchInterval)
+}
+
+// WatchInterval refreshes plotFile every interval to look for new states of a
+// d3plot which is still being written by LS-Dyna and sends their indices
+// beginning at NumTimeSteps. The received states can be read from plotFile
+// right away. If an error occurs (e.g. ErrClosed if plotFile has been closed)
+// it is sent on the error channel and watching stops. Both channels are closed
+// when watching stops or ctx is done.
+func (plotFile D3plot) WatchInterval(ctx context.Context, interval time.Duration) (<-chan uint64, <-chan error) {
+	states := make(chan uint64)
+	errs := make(chan error, 1)
+	nextState := plotFile.NumTimeSteps()
+
+	go func() {
+		defer close(states)
+		defer close(errs)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+
+			if err := plotFile.Refresh(); err != nil {
+				errs <- err
+				return
+			}
+
+			for numStates := plotFile.NumTimeSteps(); nextState < numStates; nextState++ {
+				select {
+				case states <- nextState:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return states, errs
+}
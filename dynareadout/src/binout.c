@@ -35,28 +35,14 @@
 #include <stdlib.h>
 #include <string.h>
 
-#define FILE_FAILED(message)                                                   \
-  multi_file_close(file);                                                      \
-  _binout_add_file_error(&bin_file, file_names[cur_file_index], message);      \
-  cur_file_index++;                                                            \
-  continue
-
-#define BIN_FILE_READ(dst, size, count, message)                               \
-  read_count = multi_file_read(file, &file_index, &dst, size, count);          \
-  if (read_count != count) {                                                   \
-    cur_file_failed = 1;                                                       \
-    _binout_add_file_error(&bin_file, file_names[cur_file_index], message);    \
+#define PARSE_FAILED(kind, message)                                            \
+  {                                                                            \
+    _binout_add_file_error(bin_file, state->file_name, kind, message);         \
     break;                                                                     \
   }
 
-#define BIN_FILE_READ_FREE(dst, size, count, obj, message)                     \
-  read_count = multi_file_read(file, &file_index, dst, size, count);           \
-  if (read_count != count) {                                                   \
-    free(obj);                                                                 \
-    _binout_add_file_error(&bin_file, file_names[cur_file_index], message);    \
-    cur_file_failed = 1;                                                       \
-    break;                                                                     \
-  }
+#define PARSE_READ(dst, size, count)                                           \
+  (multi_file_read(file, &file_index, dst, size, count) == (size_t)(count))
 
 binout_file binout_open(const char *file_name) {
   BEGIN_PROFILE_FUNC();
@@ -65,256 +51,429 @@ binout_file binout_open(const char *file_name) {
   bin_file.directory.children = NULL;
   bin_file.directory.num_children = 0;
   bin_file.files = NULL;
+  bin_file.file_states = NULL;
   bin_file.file_errors = NULL;
   bin_file.error_string = NULL;
+  bin_file.error_kind = BINOUT_ERROR_OTHER;
   bin_file.num_files = 0;
   bin_file.num_file_errors = 0;
 
-  char **file_names = binout_glob(file_name, &bin_file.num_files);
-  if (bin_file.num_files == 0) {
-    _binout_add_file_error(&bin_file, file_name, "No files have been found");
+  size_t num_file_names;
+  char **file_names = binout_glob(file_name, &num_file_names);
+  if (num_file_names == 0) {
+    _binout_add_file_error(&bin_file, file_name, BINOUT_FILE_ERROR_NOT_FOUND,
+                           "No files have been found");
     END_PROFILE_FUNC();
     return bin_file;
   }
 
-  bin_file.files = malloc(bin_file.num_files * sizeof(multi_file_t));
+  bin_file.files = malloc(num_file_names * sizeof(multi_file_t));
+  bin_file.file_states = malloc(num_file_names * sizeof(binout_file_state));
 
   size_t cur_file_index = 0;
-  while (cur_file_index < bin_file.num_files) {
-    bin_file.files[cur_file_index] =
-        multi_file_open(file_names[cur_file_index]);
-#ifdef NO_THREAD_SAFETY
-    if (!bin_file.files[cur_file_index]) {
-      _binout_add_file_error(&bin_file, file_names[cur_file_index],
-                             strerror(errno));
-    }
-#endif
+  while (cur_file_index < num_file_names) {
+    _binout_add_file(&bin_file, file_names[cur_file_index]);
+    cur_file_index++;
+  }
+
+  binout_free_glob(file_names, num_file_names);
 
+  /* Parse all files and remember which of them failed*/
+  int *failed = malloc(bin_file.num_files * sizeof(int));
+  cur_file_index = 0;
+  while (cur_file_index < bin_file.num_files) {
+    failed[cur_file_index] = !_binout_parse_file(&bin_file, cur_file_index, 1);
     cur_file_index++;
   }
 
+  /* Clean up failed files. The order of the remaining files is kept and the
+   * file indices of the directory are updated, since they index into files.
+   * A file which failed while parsing its records stays open, since all
+   * records before the failed one have been added and can still be read. The
+   * error is still reported by binout_open_error.*/
+  size_t *file_index_map = malloc(bin_file.num_files * sizeof(size_t));
+  size_t num_open_files = 0;
   cur_file_index = 0;
   while (cur_file_index < bin_file.num_files) {
-    multi_file_t *file = &bin_file.files[cur_file_index];
-    multi_file_index_t file_index = multi_file_access(file);
+    if (failed[cur_file_index]) {
+      multi_file_close(&bin_file.files[cur_file_index]);
+      free(bin_file.file_states[cur_file_index].file_name);
+    } else {
+      bin_file.files[num_open_files] = bin_file.files[cur_file_index];
+      bin_file.file_states[num_open_files] =
+          bin_file.file_states[cur_file_index];
+      file_index_map[cur_file_index] = num_open_files;
+      num_open_files++;
+    }
 
-    /* Just ignore the file if it failed to open*/
-#ifndef NO_THREAD_SAFETY
-    if (file_index.index == ULONG_MAX) {
-      FILE_FAILED(strerror(errno));
+    cur_file_index++;
+  }
+
+  if (num_open_files != bin_file.num_files) {
+    size_t i = 0;
+    while (i < bin_file.directory.num_children) {
+      _binout_folder_remap_file_indices(&bin_file.directory.children[i],
+                                        file_index_map);
+      i++;
     }
-#else
-    if (!(*file)) {
-      cur_file_index++;
-      continue;
+
+    bin_file.num_files = num_open_files;
+    if (num_open_files == 0) {
+      free(bin_file.files);
+      free(bin_file.file_states);
+      bin_file.files = NULL;
+      bin_file.file_states = NULL;
     }
+  }
+  free(file_index_map);
+  free(failed);
+
+  END_PROFILE_FUNC();
+
+  return bin_file;
+}
+
+void binout_refresh(binout_file *bin_file, const char *file_name) {
+  BEGIN_PROFILE_FUNC();
+
+  /* The errors always describe the latest scan*/
+  _binout_free_file_errors(bin_file);
+
+  /* Continue parsing the known files*/
+  size_t i = 0;
+  while (i < bin_file->num_files) {
+    _binout_parse_file(bin_file, i, 0);
+    i++;
+  }
+
+  /* Add files which have been created since the last scan*/
+  size_t num_file_names;
+  char **file_names = binout_glob(file_name, &num_file_names);
+
+  i = 0;
+  while (i < num_file_names) {
+    int known = 0;
+    size_t j = 0;
+    while (j < bin_file->num_files) {
+      if (strcmp(bin_file->file_states[j].file_name, file_names[i]) == 0) {
+        known = 1;
+        break;
+      }
+      j++;
+    }
+
+    if (!known) {
+      bin_file->files = realloc(bin_file->files, (bin_file->num_files + 1) *
+                                                     sizeof(multi_file_t));
+      bin_file->file_states =
+          realloc(bin_file->file_states,
+                  (bin_file->num_files + 1) * sizeof(binout_file_state));
+      _binout_add_file(bin_file, file_names[i]);
+
+      /* The new file is the last one, so it can just be removed again if its
+       * header can not be read (yet)*/
+      if (!_binout_parse_file(bin_file, bin_file->num_files - 1, 0)) {
+        bin_file->num_files--;
+        multi_file_close(&bin_file->files[bin_file->num_files]);
+        free(bin_file->file_states[bin_file->num_files].file_name);
+      }
+    }
+
+    i++;
+  }
+
+  binout_free_glob(file_names, num_file_names);
+
+  END_PROFILE_FUNC();
+}
+
+void _binout_add_file(binout_file *bin_file, const char *file_name) {
+  bin_file->files[bin_file->num_files] = multi_file_open(file_name);
+
+  binout_file_state *state = &bin_file->file_states[bin_file->num_files];
+  memset(state, 0, sizeof(binout_file_state));
+  state->file_name = malloc(strlen(file_name) + 1);
+  memcpy(state->file_name, file_name, strlen(file_name) + 1);
+  state->current_path[0] = PATH_SEP;
+  state->current_path[1] = '\0';
+
+  bin_file->num_files++;
+}
+
+int _binout_parse_file(binout_file *bin_file, size_t cur_file_index,
+                       int report_truncation) {
+  multi_file_t *file = &bin_file->files[cur_file_index];
+  binout_file_state *state = &bin_file->file_states[cur_file_index];
+
+#ifdef NO_THREAD_SAFETY
+  if (!(*file)) {
+    _binout_add_file_error(bin_file, state->file_name, BINOUT_FILE_ERROR_IO,
+                           strerror(errno));
+    return 0;
+  }
 #endif
 
-    binout_header header;
+  multi_file_index_t file_index = multi_file_access(file);
+#ifndef NO_THREAD_SAFETY
+  if (file_index.index == ULONG_MAX) {
+    _binout_add_file_error(bin_file, state->file_name, BINOUT_FILE_ERROR_IO,
+                           strerror(errno));
+    return 0;
+  }
+#endif
 
-    /* Read header */
-    size_t read_count =
-        multi_file_read(file, &file_index, &header, sizeof(binout_header), 1);
-    if (read_count == 0) {
-      FILE_FAILED("Failed to read header");
+  /* Get the file size*/
+  const long file_size = (long)path_get_file_size(state->file_name);
+
+  /* Read the header if the file has not been parsed yet*/
+  if (state->parsed_size == 0) {
+    binout_header *header = &state->header;
+    const char *error = NULL;
+
+    if (!PARSE_READ(header, sizeof(binout_header), 1)) {
+      multi_file_return(file, &file_index);
+      /* The header of a file which is still being written may be incomplete*/
+      if (report_truncation) {
+        _binout_add_file_error(bin_file, state->file_name,
+                               BINOUT_FILE_ERROR_TRUNCATED,
+                               "Failed to read header");
+      }
+      return 0;
     }
 
     /* Check if the binout file is actually supported (Might also be an
      * indicator that the given file is not a binout) */
-    if (header.endianess != BINOUT_HEADER_LITTLE_ENDIAN) {
-      FILE_FAILED("Unsupported Endianess");
+    if (header->endianess != BINOUT_HEADER_LITTLE_ENDIAN &&
+        header->endianess != BINOUT_HEADER_BIG_ENDIAN) {
+      error = "Unsupported Endianess";
+    } else if (header->header_size < sizeof(binout_header)) {
+      error = "The header size is unsupported";
+    } else if (header->record_length_field_size == 0 ||
+               header->record_length_field_size > 8) {
+      error = "The record length field size is unsupported";
+    } else if (header->record_command_field_size == 0 ||
+               header->record_command_field_size > 8) {
+      error = "The command length field size is unsupported";
+    } else if (header->record_typeid_field_size > 8) {
+      error = "The typeid field size is unsupported";
+    } else if (header->float_format != BINOUT_HEADER_FLOAT_IEEE) {
+      error = "The float format is unsupported";
+    } else if (header->header_size > file_size) {
+      error = "Failed to skip the header";
     }
-    if (header.record_length_field_size > 8) {
-      FILE_FAILED("The record length field size is unsupported");
+
+    if (error) {
+      multi_file_return(file, &file_index);
+      _binout_add_file_error(bin_file, state->file_name,
+                             BINOUT_FILE_ERROR_HEADER, error);
+      return 0;
     }
-    if (header.record_command_field_size > 8) {
-      FILE_FAILED("The command length field size is unsupported");
+
+    state->big_endian = header->endianess == BINOUT_HEADER_BIG_ENDIAN;
+    /* Skip the rest of the header if it is larger than the known fields*/
+    state->parsed_size = header->header_size;
+  }
+
+  const binout_header *header = &state->header;
+
+  if (multi_file_seek(file, &file_index, state->parsed_size, SEEK_SET) != 0) {
+    multi_file_return(file, &file_index);
+    _binout_add_file_error(bin_file, state->file_name, BINOUT_FILE_ERROR_IO,
+                           "Failed to seek to the next record");
+    return 1;
+  }
+
+  /* The folder of the current path. The directory may have changed since the
+   * last call, so it needs to be searched again.*/
+  binout_folder_t *current_folder = NULL;
+  {
+    path_view_t current_path = path_view_new(state->current_path);
+    if (path_view_advance(&current_path)) {
+      current_folder =
+          binout_directory_insert_folder(&bin_file->directory, &current_path);
     }
-    if (header.record_typeid_field_size > 8) {
-      FILE_FAILED("The typeid field size is unsupported");
+  }
+
+  uint8_t field_buffer[8];
+  /* A buffer for the path of the CD command*/
+  char path_buffer[1024];
+
+  /* We cannot use EOF, so we use this*/
+  while (1) {
+    /* Check if we are already at the end or if an error occurred in ftell*/
+    const long current_file_pos = multi_file_tell(file, &file_index);
+    if (current_file_pos == -1 || current_file_pos == file_size) {
+      break;
     }
-    if (header.float_format != BINOUT_HEADER_FLOAT_IEEE) {
-      FILE_FAILED("The float format is unsupported");
+
+    const long record_header_size =
+        header->record_length_field_size + header->record_command_field_size;
+    if (current_file_pos + record_header_size > file_size) {
+      if (report_truncation) {
+        PARSE_FAILED(BINOUT_FILE_ERROR_TRUNCATED, "The record is truncated");
+      }
+      break;
     }
 
-    /* Get the file size*/
-    const long file_size = (long)path_get_file_size(file_names[cur_file_index]);
+    if (!PARSE_READ(field_buffer, 1, header->record_length_field_size)) {
+      PARSE_FAILED(BINOUT_FILE_ERROR_IO, "Failed to read record length");
+    }
+    const uint64_t record_length = _binout_field_to_uint64(
+        field_buffer, header->record_length_field_size, state->big_endian);
 
-    /* Parse all records */
+    if (!PARSE_READ(field_buffer, 1, header->record_command_field_size)) {
+      PARSE_FAILED(BINOUT_FILE_ERROR_IO, "Failed to read command");
+    }
+    const uint64_t record_command = _binout_field_to_uint64(
+        field_buffer, header->record_command_field_size, state->big_endian);
 
-    /* Store the current path which is changed by the CD commands*/
-    /* Store 1KB on the stack, this should totally suffice*/
-    char current_path_string[1024];
-    current_path_string[0] = PATH_SEP;
-    current_path_string[1] = '\0';
-    path_view_t current_path = path_view_new(current_path_string);
-    binout_folder_t *current_folder = NULL;
+    if (record_length < (uint64_t)record_header_size) {
+      PARSE_FAILED(BINOUT_FILE_ERROR_RECORD, "The record length is invalid");
+    }
 
-    int cur_file_failed = 0;
+    /* Records of a file which is still being written may be incomplete. They
+     * are parsed by the next refresh.*/
+    if ((uint64_t)current_file_pos + record_length > (uint64_t)file_size) {
+      if (report_truncation) {
+        if (record_command == BINOUT_COMMAND_DATA) {
+          PARSE_FAILED(BINOUT_FILE_ERROR_TRUNCATED,
+                       "The DATA record is truncated");
+        }
+        PARSE_FAILED(BINOUT_FILE_ERROR_TRUNCATED, "The record is truncated");
+      }
+      break;
+    }
 
-    /* A buffer for the path of the CD command*/
-    char path_buffer[1024];
+    const uint64_t record_data_length = record_length - record_header_size;
 
-    /* We cannot use EOF, so we use this*/
-    while (1) {
-      /* Check if we are already at the end or if an error occurred in ftell*/
-      const long current_file_pos = multi_file_tell(file, &file_index);
-      if (current_file_pos == -1 || current_file_pos == file_size) {
-        break;
+    /* Execute code for all the different commands
+     * Currently only CD and DATA. All other commands are ignored*/
+    if (record_command == BINOUT_COMMAND_CD) {
+      if (record_data_length >= 1024) {
+        PARSE_FAILED(BINOUT_FILE_ERROR_RECORD,
+                     "The PATH of the CD record is too long");
       }
 
-      uint64_t record_length = 0, record_command = 0;
-
-      BIN_FILE_READ(record_length, header.record_length_field_size, 1,
-                    "Failed to read record length");
-      BIN_FILE_READ(record_command, header.record_command_field_size, 1,
-                    "Failed to read command");
-
-      const uint64_t record_data_length = record_length -
-                                          header.record_length_field_size -
-                                          header.record_command_field_size;
-
-      /* Execute code for all the different commands
-       * Currently only CD and DATA. All other commands are ignored*/
-      if (record_command == BINOUT_COMMAND_CD) {
-        assert(record_data_length < 1024);
-
-        path_buffer[record_data_length] = '\0';
-        BIN_FILE_READ(path_buffer, 1, record_data_length,
-                      "Failed to read PATH of CD record");
-
-        if PATH_IS_ABS (path_buffer) {
-          memcpy(current_path_string, path_buffer, record_data_length + 1);
-          current_path = path_view_new(current_path_string);
-          /* Only insert the current folder if the current path is not the
-           * root folder*/
-          if (path_view_advance(&current_path)) {
-            current_folder = binout_directory_insert_folder(&bin_file.directory,
-                                                            &current_path);
-          }
-        } else {
-          path_view_t path = path_view_new(path_buffer);
+      path_buffer[record_data_length] = '\0';
+      if (!PARSE_READ(path_buffer, 1, record_data_length)) {
+        PARSE_FAILED(BINOUT_FILE_ERROR_IO, "Failed to read PATH of CD record");
+      }
 
-          while (1) {
-            if (path_view_strcmp(&path, "..") == 0) {
-              size_t index = path_move_up(current_path_string);
-              index += index == 0;
+      char *current_path_string = state->current_path;
+      path_view_t current_path;
 
-              current_path_string[index] = '\0';
-            } else {
-              /* Join current_path_string with path*/
-              const int path_len = PATH_VIEW_LEN((&path));
-              int len = strlen(current_path_string);
-              assert((len + path_len + 1) < 1024);
+      if PATH_IS_ABS (path_buffer) {
+        memcpy(current_path_string, path_buffer, record_data_length + 1);
+        current_path = path_view_new(current_path_string);
+        /* Only insert the current folder if the current path is not the
+         * root folder*/
+        if (path_view_advance(&current_path)) {
+          current_folder = binout_directory_insert_folder(&bin_file->directory,
+                                                          &current_path);
+        } else {
+          current_folder = NULL;
+        }
+      } else {
+        path_view_t path = path_view_new(path_buffer);
 
-              if (current_path_string[len - 1] != PATH_SEP) {
-                current_path_string[len] = PATH_SEP;
-                len++;
+        while (1) {
+          if (path_view_strcmp(&path, "..") == 0) {
+            size_t index = path_move_up(current_path_string);
+            index += index == 0;
 
-                assert(len < 1024);
-              }
+            current_path_string[index] = '\0';
+          } else {
+            /* Join current_path_string with path*/
+            const int path_len = PATH_VIEW_LEN((&path));
+            int len = strlen(current_path_string);
+            assert((len + path_len + 1) < 1024);
 
-              PATH_VIEW_CPY(&current_path_string[len], (&path));
-              current_path_string[len + path_len] = '\0';
-            }
+            if (current_path_string[len - 1] != PATH_SEP) {
+              current_path_string[len] = PATH_SEP;
+              len++;
 
-            if (!path_view_advance(&path)) {
-              break;
+              assert(len < 1024);
             }
-          }
 
-          current_path = path_view_new(current_path_string);
-          path_view_advance(&current_path);
+            PATH_VIEW_CPY(&current_path_string[len], (&path));
+            current_path_string[len + path_len] = '\0';
+          }
 
-          current_folder = binout_directory_insert_folder(&bin_file.directory,
-                                                          &current_path);
-        }
-      } else if (record_command == BINOUT_COMMAND_DATA) {
-        /* If current_folder is NULL, this means that there are files inside
-         * '/', which we do not support. And LS Dyna does also not do this.
-         */
-        assert(current_folder != NULL);
-
-        uint64_t type_id = 0;
-        uint8_t variable_name_length;
-
-        BIN_FILE_READ(type_id, header.record_typeid_field_size, 1,
-                      "Failed to read TYPEID of DATA record");
-        BIN_FILE_READ(variable_name_length, BINOUT_DATA_NAME_LENGTH, 1,
-                      "Failed to read Name length of DATA record");
-
-        char *variable_name = malloc(variable_name_length + 1);
-        variable_name[variable_name_length] = '\0';
-
-        BIN_FILE_READ_FREE(variable_name, 1, variable_name_length,
-                           variable_name, "Failed to read Name of DATA record");
-
-        /* How large the data segment of the data record is*/
-        const uint64_t data_length =
-            record_data_length - header.record_typeid_field_size -
-            BINOUT_DATA_NAME_LENGTH - variable_name_length;
-        const long file_pos = multi_file_tell(file, &file_index);
-        /* Skip the data since we will read it at a later point, if it is
-         * requested by the programmer*/
-        if (multi_file_seek(file, &file_index, data_length, SEEK_CUR) != 0) {
-          free(variable_name);
-          cur_file_failed = 1;
-          _binout_add_file_error(&bin_file, file_names[cur_file_index],
-                                 "Failed to skip Data of DATA record");
-          break;
+          if (!path_view_advance(&path)) {
+            break;
+          }
         }
 
-        binout_folder_insert_file(current_folder, variable_name,
-                                  (uint8_t)type_id, data_length,
-                                  (uint8_t)cur_file_index, file_pos);
-      } else {
-        /* Just skip the record and ignore its data*/
-        if (multi_file_seek(file, &file_index, record_data_length, SEEK_CUR) !=
-            0) {
-          cur_file_failed = 1;
-          _binout_add_file_error(&bin_file, file_names[cur_file_index],
-                                 "Failed to skip data of a record");
-          break;
+        current_path = path_view_new(current_path_string);
+        if (path_view_advance(&current_path)) {
+          current_folder = binout_directory_insert_folder(&bin_file->directory,
+                                                          &current_path);
+        } else {
+          current_folder = NULL;
         }
       }
-    }
+    } else if (record_command == BINOUT_COMMAND_DATA) {
+      /* If current_folder is NULL, this means that there are files inside
+       * '/', which we do not support. And LS Dyna does also not do this.
+       */
+      if (current_folder == NULL) {
+        PARSE_FAILED(BINOUT_FILE_ERROR_RECORD,
+                     "The DATA record is not inside of a folder");
+      }
 
-    multi_file_return(file, &file_index);
+      uint8_t variable_name_length;
 
-    if (cur_file_failed) {
-      multi_file_close(file);
-    }
+      if (!PARSE_READ(field_buffer, 1, header->record_typeid_field_size)) {
+        PARSE_FAILED(BINOUT_FILE_ERROR_IO,
+                     "Failed to read TYPEID of DATA record");
+      }
+      const uint64_t type_id = _binout_field_to_uint64(
+          field_buffer, header->record_typeid_field_size, state->big_endian);
 
-    cur_file_index++;
-  }
+      if (!PARSE_READ(&variable_name_length, BINOUT_DATA_NAME_LENGTH, 1)) {
+        PARSE_FAILED(BINOUT_FILE_ERROR_IO,
+                     "Failed to read Name length of DATA record");
+      }
 
-  binout_free_glob(file_names, bin_file.num_files);
+      char *variable_name = malloc(variable_name_length + 1);
+      variable_name[variable_name_length] = '\0';
 
-  /* Clean up failed files*/
-  cur_file_index = 0;
-  while (cur_file_index < bin_file.num_files) {
-#ifndef NO_THREAD_SAFETY
-    if (bin_file.files[cur_file_index].file_handles == NULL) {
-#else
-    if (!bin_file.files[cur_file_index]) {
-#endif
-      /* Swap with the last element*/
-      bin_file.files[cur_file_index] = bin_file.files[bin_file.num_files - 1];
+      if (!PARSE_READ(variable_name, 1, variable_name_length)) {
+        free(variable_name);
+        PARSE_FAILED(BINOUT_FILE_ERROR_IO,
+                     "Failed to read Name of DATA record");
+      }
 
-      /* Reallocate memory*/
-      bin_file.num_files--;
-      bin_file.files =
-          realloc(bin_file.files, bin_file.num_files * sizeof(multi_file_t));
+      /* How large the data segment of the data record is*/
+      const uint64_t data_length =
+          record_data_length - header->record_typeid_field_size -
+          BINOUT_DATA_NAME_LENGTH - variable_name_length;
+      const long file_pos = multi_file_tell(file, &file_index);
+      /* Skip the data since we will read it at a later point, if it is
+       * requested by the programmer*/
+      if (file_pos == -1 ||
+          multi_file_seek(file, &file_index, data_length, SEEK_CUR) != 0) {
+        free(variable_name);
+        PARSE_FAILED(BINOUT_FILE_ERROR_IO,
+                     "Failed to skip Data of DATA record");
+      }
 
-      cur_file_index--;
+      binout_folder_insert_file(current_folder, variable_name,
+                                (uint8_t)type_id, data_length,
+                                (uint8_t)cur_file_index, file_pos);
+    } else {
+      /* Just skip the record and ignore its data*/
+      if (multi_file_seek(file, &file_index, record_data_length, SEEK_CUR) !=
+          0) {
+        PARSE_FAILED(BINOUT_FILE_ERROR_IO, "Failed to skip data of a record");
+      }
     }
 
-    cur_file_index++;
+    state->parsed_size = multi_file_tell(file, &file_index);
   }
 
-  END_PROFILE_FUNC();
-
-  return bin_file;
+  multi_file_return(file, &file_index);
+  return 1;
 }
 
 void binout_close(binout_file *bin_file) {
@@ -324,17 +483,15 @@ void binout_close(binout_file *bin_file) {
   size_t cur_file_index = 0;
   while (cur_file_index < bin_file->num_files) {
     multi_file_close(&bin_file->files[cur_file_index]);
+    free(bin_file->file_states[cur_file_index].file_name);
 
     cur_file_index++;
   }
+  free(bin_file->files);
+  free(bin_file->file_states);
 
   /* Free all file errors*/
-  size_t i = 0;
-  while (i < bin_file->num_file_errors) {
-    free(bin_file->file_errors[i]);
-
-    i++;
-  }
+  _binout_free_file_errors(bin_file);
 
   binout_directory_free(&bin_file->directory);
 
@@ -343,6 +500,7 @@ void binout_close(binout_file *bin_file) {
   bin_file->directory.children = NULL;
   bin_file->directory.num_children = 0;
   bin_file->files = NULL;
+  bin_file->file_states = NULL;
   bin_file->file_errors = NULL;
   bin_file->error_string = NULL;
   bin_file->num_files = 0;
@@ -360,7 +518,8 @@ uint8_t binout_get_type_id(binout_file *bin_file,
   const binout_file_t *file =
       binout_directory_get_file(&bin_file->directory, &path);
   if (!file) {
-    NEW_ERROR_STRING_F("\"%s\" has not been found", path_to_variable);
+    NEW_KIND_ERROR_STRING_F(BINOUT_ERROR_PATH_NOT_FOUND,
+                            "\"%s\" has not been found", path_to_variable);
     END_PROFILE_FUNC();
     return BINOUT_TYPE_INVALID;
   }
@@ -430,7 +589,9 @@ char *binout_open_error(binout_file *bin_file) {
 
   size_t i = 0;
   while (i < bin_file->num_file_errors) {
-    string_builder_append(&file_error, bin_file->file_errors[i]);
+    string_builder_append(&file_error, bin_file->file_errors[i].file_name);
+    string_builder_append(&file_error, ": ");
+    string_builder_append(&file_error, bin_file->file_errors[i].message);
     if (i != bin_file->num_file_errors - 1) {
       string_builder_append_char(&file_error, '\n');
     }
@@ -761,17 +922,90 @@ const char *_binout_get_type_name(const uint64_t type_id) {
 }
 
 void _binout_add_file_error(binout_file *bin_file, const char *file_name,
-                            const char *message) {
+                            uint8_t kind, const char *message) {
   bin_file->num_file_errors++;
-  bin_file->file_errors = realloc(bin_file->file_errors,
-                                  bin_file->num_file_errors * sizeof(char *));
-
-  string_builder_t new_file_error = string_builder_new();
-  string_builder_append(&new_file_error, file_name);
-  string_builder_append(&new_file_error, ": ");
-  string_builder_append(&new_file_error, message);
-  bin_file->file_errors[bin_file->num_file_errors - 1] =
-      string_builder_move(&new_file_error);
+  bin_file->file_errors =
+      realloc(bin_file->file_errors,
+              bin_file->num_file_errors * sizeof(binout_file_error));
+
+  binout_file_error *file_error =
+      &bin_file->file_errors[bin_file->num_file_errors - 1];
+  file_error->file_name = malloc(strlen(file_name) + 1);
+  memcpy(file_error->file_name, file_name, strlen(file_name) + 1);
+  file_error->message = malloc(strlen(message) + 1);
+  memcpy(file_error->message, message, strlen(message) + 1);
+  file_error->kind = kind;
+}
+
+void _binout_free_file_errors(binout_file *bin_file) {
+  size_t i = 0;
+  while (i < bin_file->num_file_errors) {
+    free(bin_file->file_errors[i].file_name);
+    free(bin_file->file_errors[i].message);
+    i++;
+  }
+
+  free(bin_file->file_errors);
+  bin_file->file_errors = NULL;
+  bin_file->num_file_errors = 0;
+}
+
+uint64_t _binout_field_to_uint64(const uint8_t *field, uint8_t size,
+                                 int big_endian) {
+  uint64_t value = 0;
+  uint8_t i = 0;
+  while (i < size) {
+    const uint8_t shift = big_endian ? (uint8_t)(size - 1 - i) : i;
+    value |= (uint64_t)field[i] << (shift * 8);
+    i++;
+  }
+  return value;
+}
+
+int _binout_host_is_big_endian(void) {
+  const uint16_t value = 1;
+  return *(const uint8_t *)&value == 0;
+}
+
+void _binout_swap_bytes(void *data, size_t size, uint8_t type_size) {
+  if (type_size < 2) {
+    return;
+  }
+
+  uint8_t *bytes = (uint8_t *)data;
+  size_t i = 0;
+  while (i + type_size <= size) {
+    uint8_t j = 0;
+    while (j < type_size / 2) {
+      const uint8_t tmp = bytes[i + j];
+      bytes[i + j] = bytes[i + type_size - 1 - j];
+      bytes[i + type_size - 1 - j] = tmp;
+      j++;
+    }
+    i += type_size;
+  }
+}
+
+void _binout_folder_remap_file_indices(binout_folder_t *folder,
+                                       const size_t *file_index_map) {
+  if (folder->num_children == 0) {
+    return;
+  }
+
+  size_t i = 0;
+  if (BINOUT_FOLDER_CHILDREN_GET_TYPE(folder) == BINOUT_FILE) {
+    binout_file_t *files = (binout_file_t *)folder->children;
+    while (i < folder->num_children) {
+      files[i].file_index = (uint8_t)file_index_map[files[i].file_index];
+      i++;
+    }
+  } else {
+    binout_folder_t *folders = (binout_folder_t *)folder->children;
+    while (i < folder->num_children) {
+      _binout_folder_remap_file_indices(&folders[i], file_index_map);
+      i++;
+    }
+  }
 }
 
 int _binout_is_d_string(const char *folder_name) {
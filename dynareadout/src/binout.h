@@ -43,6 +43,22 @@ typedef struct {
   uint8_t _unused;
 } binout_header;
 
+/* The state of parsing the records of one file of a binout family*/
+typedef struct {
+  char *file_name;
+  binout_header header;
+  uint8_t big_endian; /* Whether the records are stored in big endian*/
+  long parsed_size; /* The file position after the last parsed record*/
+  char current_path[1024]; /* The path set by the last CD record*/
+} binout_file_state;
+
+/* The reason why one file of a binout failed to open or to parse*/
+typedef struct {
+  char *file_name;
+  char *message;
+  uint8_t kind; /* One of the BINOUT_FILE_ERROR_* defines*/
+} binout_file_error;
+
 /* A binout file used to read data from a binout file*/
 typedef struct {
   /* A data structure which holds the structure of the files*/
@@ -50,13 +66,17 @@ typedef struct {
 
   multi_file_t *files;
   size_t num_files;
+  /* The parse state of every file of files*/
+  binout_file_state *file_states;
 
-  char **file_errors;
+  binout_file_error *file_errors;
   size_t num_file_errors;
 
   /* Holds errors from read and other functions that are not open. If NULL no
    * error occurred*/
   char *error_string;
+  /* The kind of error_string. One of the BINOUT_ERROR_* defines*/
+  uint8_t error_kind;
 } binout_file;
 
 #include "binout_read.h"
@@ -83,6 +103,12 @@ char **binout_get_children(binout_file *bin_file, const char *path,
                            size_t *num_children);
 /* Free the allocated memory*/
 void binout_free_children(char **children);
+/* Parses the records which have been appended to the files since the last call
+ * of binout_open or binout_refresh and adds files matching file_name (the same
+ * glob pattern as given to binout_open) which did not exist before. Incomplete
+ * records at the end of a file are not an error and will be parsed by the next
+ * refresh. The file errors are replaced by the errors of this scan.*/
+void binout_refresh(binout_file *bin_file, const char *file_name);
 /* Returns all file errors as one string. This gives information about files
  * that failed in binout_open. These errors are not fatal. If the return value
  * is NULL, no error occurred. The return value needs to be deallocated by
@@ -115,10 +141,35 @@ const char *_binout_get_command_name(const uint64_t command);
 uint8_t _binout_get_type_size(const uint64_t type_id);
 /* Returns the type id as a human readable string*/
 const char *_binout_get_type_name(const uint64_t type_id);
-/* Add to the file_errors array:
- * Example: "test_data/binout0000: Failed to open file"*/
+/* Add to the file_errors array. kind is one of the BINOUT_FILE_ERROR_*
+ * defines.*/
 void _binout_add_file_error(binout_file *bin_file, const char *file_name,
-                            const char *message);
+                            uint8_t kind, const char *message);
+/* Free the file_errors array*/
+void _binout_free_file_errors(binout_file *bin_file);
+/* Opens file_name and appends it to the files. files and file_states need to
+ * have space for one more file*/
+void _binout_add_file(binout_file *bin_file, const char *file_name);
+/* Parses the records of a file beginning after the last parsed record. Reading
+ * the header is the first step if nothing has been parsed yet. Incomplete
+ * records at the end of the file are only reported as errors if
+ * report_truncation is set. Returns 0 if the file can not be used at all (e.g.
+ * an invalid header) and 1 otherwise.*/
+int _binout_parse_file(binout_file *bin_file, size_t cur_file_index,
+                       int report_truncation);
+/* Converts a field of the record header (length, command, typeid) with the
+ * given size in bytes to a number*/
+uint64_t _binout_field_to_uint64(const uint8_t *field, uint8_t size,
+                                 int big_endian);
+/* Returns 1 if the machine stores numbers in big endian*/
+int _binout_host_is_big_endian(void);
+/* Reverses the bytes of every element of data. size is the size of data in
+ * bytes*/
+void _binout_swap_bytes(void *data, size_t size, uint8_t type_size);
+/* Replaces the file index of every file beneath folder with
+ * file_index_map[file_index]*/
+void _binout_folder_remap_file_indices(binout_folder_t *folder,
+                                       const size_t *file_index_map);
 /* Returns 1 if the given folder_name is a dxxxxxx folder and 0 otherwise. The
  * string can not be empty and must be null-terminated.*/
 int _binout_is_d_string(const char *folder_name);
@@ -52,6 +52,17 @@
 #define BINOUT_TYPE_FLOAT64 10
 #define BINOUT_TYPE_INVALID UCHAR_MAX
 
+#define BINOUT_ERROR_OTHER 0
+#define BINOUT_ERROR_PATH_NOT_FOUND 1
+#define BINOUT_ERROR_TYPE_MISMATCH 2
+
+#define BINOUT_FILE_ERROR_OTHER 0
+#define BINOUT_FILE_ERROR_NOT_FOUND 1
+#define BINOUT_FILE_ERROR_IO 2
+#define BINOUT_FILE_ERROR_HEADER 3
+#define BINOUT_FILE_ERROR_TRUNCATED 4
+#define BINOUT_FILE_ERROR_RECORD 5
+
 #define BINOUT_DATA_NAME_LENGTH 1
 #define BINOUT_DATA_POINTER_PREALLOC 100
 #define BINOUT_DATA_POINTER_ALLOC_ADV 10
@@ -61,15 +72,22 @@
 #define NEW_ERROR_STRING(message)                                              \
   if (bin_file->error_string)                                                  \
     free(bin_file->error_string);                                              \
-  bin_file->error_string = string_clone(message)
+  bin_file->error_string = string_clone(message);                              \
+  bin_file->error_kind = BINOUT_ERROR_OTHER
 
 #define NEW_ERROR_STRING_F(format_str, ...)                                    \
   char format_buffer[1024];                                                    \
   sprintf(format_buffer, format_str, __VA_ARGS__);                             \
   NEW_ERROR_STRING(format_buffer)
 
+/* Like NEW_ERROR_STRING_F, but kind is one of the BINOUT_ERROR_* defines*/
+#define NEW_KIND_ERROR_STRING_F(kind, format_str, ...)                         \
+  NEW_ERROR_STRING_F(format_str, __VA_ARGS__);                                 \
+  bin_file->error_kind = kind
+
 #define BINOUT_CLEAR_ERROR_STRING()                                            \
   free(bin_file->error_string);                                                \
-  bin_file->error_string = NULL;
+  bin_file->error_string = NULL;                                               \
+  bin_file->error_kind = BINOUT_ERROR_OTHER;
 
 #endif
@@ -41,14 +41,17 @@ void *_binout_read(binout_file *bin_file, const char *path_to_variable,
   const binout_file_t *file =
       binout_directory_get_file(&bin_file->directory, &path);
   if (!file) {
-    NEW_ERROR_STRING_F("\"%s\" has not been found", path_to_variable);
+    NEW_KIND_ERROR_STRING_F(BINOUT_ERROR_PATH_NOT_FOUND,
+                            "\"%s\" has not been found", path_to_variable);
     return NULL;
   }
 
   if (file->var_type != binout_type) {
-    NEW_ERROR_STRING_F("\"%s\" is of type %s instead of %s", path_to_variable,
-                       _binout_get_type_name(file->var_type),
-                       _binout_get_type_name((uint64_t)binout_type));
+    NEW_KIND_ERROR_STRING_F(BINOUT_ERROR_TYPE_MISMATCH,
+                            "\"%s\" is of type %s instead of %s",
+                            path_to_variable,
+                            _binout_get_type_name(file->var_type),
+                            _binout_get_type_name((uint64_t)binout_type));
     return NULL;
   }
 
@@ -87,6 +90,11 @@ void *_binout_read(binout_file *bin_file, const char *path_to_variable,
   }
   multi_file_return(multi_file, &multi_file_index);
 
+  if (bin_file->file_states[file->file_index].big_endian !=
+      _binout_host_is_big_endian()) {
+    _binout_swap_bytes(data, file->size, (uint8_t)type_size);
+  }
+
   *data_size = file->size / type_size;
   return data;
 }
@@ -111,7 +119,8 @@ binout_folder_t *_binout_search_timed(binout_file *bin_file,
       bin_file->directory.children, 0, bin_file->directory.num_children - 1,
       &path);
   if (search_index == (size_t)~0) {
-    NEW_ERROR_STRING_F("The variable \"%s\" does not exist", variable);
+    NEW_KIND_ERROR_STRING_F(BINOUT_ERROR_PATH_NOT_FOUND,
+                            "The variable \"%s\" does not exist", variable);
     return NULL;
   }
 
@@ -119,13 +128,15 @@ binout_folder_t *_binout_search_timed(binout_file *bin_file,
 
   while (path_view_advance(&path)) {
     if (folder->num_children == 0) {
-      NEW_ERROR_STRING_F("The variable \"%s\" does not exist", variable);
+      NEW_KIND_ERROR_STRING_F(BINOUT_ERROR_PATH_NOT_FOUND,
+                              "The variable \"%s\" does not exist", variable);
       return NULL;
     }
 
     if (BINOUT_FOLDER_CHILDREN_GET_TYPE(folder) == BINOUT_FILE) {
       if (path_view_advance(&path)) {
-        NEW_ERROR_STRING_F("The variable \"%s\" does not exist", variable);
+        NEW_KIND_ERROR_STRING_F(BINOUT_ERROR_PATH_NOT_FOUND,
+                                "The variable \"%s\" does not exist", variable);
         return NULL;
       }
 
@@ -158,7 +169,8 @@ binout_folder_t *_binout_search_timed(binout_file *bin_file,
           }
         }
 
-        NEW_ERROR_STRING_F("The variable \"%s\" does not exist", variable);
+        NEW_KIND_ERROR_STRING_F(BINOUT_ERROR_PATH_NOT_FOUND,
+                                "The variable \"%s\" does not exist", variable);
         return NULL;
       } else {
         folder = &((binout_folder_t *)folder->children)[search_index];
@@ -166,7 +178,8 @@ binout_folder_t *_binout_search_timed(binout_file *bin_file,
     }
   }
 
-  NEW_ERROR_STRING_F(
+  NEW_KIND_ERROR_STRING_F(
+      BINOUT_ERROR_PATH_NOT_FOUND,
       "The variable \"%s\" is either metadata (not timed) or does not exist",
       variable);
   return NULL;
@@ -269,6 +282,13 @@ void *_binout_read_timed(binout_file *bin_file, const char *variable,
 
     multi_file_return(mf, &mf_idx);
 
+    if (bin_file->file_states[df->file_index].big_endian !=
+        _binout_host_is_big_endian()) {
+      _binout_swap_bytes(&((uint8_t *)data)[(i - start_index) * df->size],
+                         df->size,
+                         _binout_get_type_size((const uint64_t)binout_type));
+    }
+
     i++;
   }
 
@@ -207,6 +207,80 @@ void d3_buffer_close(d3_buffer *buffer) {
   END_PROFILE_FUNC();
 }
 
+void d3_buffer_refresh(d3_buffer *buffer) {
+  BEGIN_PROFILE_FUNC();
+
+  /* The name of the files is built inside of root_file_name, which has space
+   * for the numbers*/
+  char *file_name = buffer->root_file_name;
+
+  /* The last files may still be growing*/
+  size_t i = 0;
+  while (i < buffer->num_files) {
+    memcpy(&file_name[buffer->root_file_name_length],
+           buffer->files[i].index_string, 4);
+    buffer->files[i].file_size = path_get_file_size(file_name);
+
+    i++;
+  }
+
+  const char *patterns[2] = {"%zu", "%02zu"};
+
+  /* Add the files which have been created since the last refresh*/
+  while (i < 1000) {
+    char index_string[4];
+    sprintf(index_string, patterns[i < 10], i);
+    memcpy(&file_name[buffer->root_file_name_length], index_string, 4);
+
+    if (!path_is_file(file_name)) {
+      break;
+    }
+
+    buffer->files = realloc(buffer->files, (i + 1) * sizeof(d3_file));
+    d3_file *file = &buffer->files[i];
+    memcpy(file->index_string, index_string, 4);
+    file->file_size = path_get_file_size(file_name);
+    file->file = multi_file_open(file_name);
+#ifdef NO_THREAD_SAFETY
+    if (!file->file) {
+      /* If the error is not 'Too many open files'*/
+      if (errno != EMFILE) {
+        ERROR_AND_RETURN_BUFFER_F_PTR("%s: %s", file_name, strerror(errno));
+      }
+    } else if (buffer->last_open_file + 1 == i) {
+      buffer->last_open_file = i;
+    } else {
+      /* Only a consecutive range of files is kept open. This file will be
+       * opened by d3_buffer_seek when it is needed.*/
+      multi_file_close(&file->file);
+    }
+#endif
+
+    buffer->num_files++;
+    i++;
+  }
+
+  END_PROFILE_FUNC();
+}
+
+size_t d3_buffer_file_end(const d3_buffer *buffer, size_t file_index) {
+  size_t num_bytes = 0;
+  size_t i = 0;
+  while (i <= file_index && i < buffer->num_files) {
+    num_bytes += buffer->files[i].file_size;
+    i++;
+  }
+
+  return num_bytes / buffer->word_size;
+}
+
+size_t d3_buffer_num_words(const d3_buffer *buffer) {
+  if (buffer->num_files == 0) {
+    return 0;
+  }
+  return d3_buffer_file_end(buffer, buffer->num_files - 1);
+}
+
 void d3_buffer_read_words(d3_buffer *buffer, d3_pointer *ptr, void *words,
                           size_t num_words) {
   BEGIN_PROFILE_FUNC();
@@ -339,6 +413,16 @@ void d3_buffer_skip_words(d3_buffer *buffer, d3_pointer *ptr,
   BEGIN_PROFILE_FUNC();
   const size_t new_cur_word = ptr->cur_word + num_words;
   d3_pointer_close(buffer, ptr);
+
+  /* Skipping to the end of the last file is not an error, since the data
+   * may end there (e.g. the last state of a family without an EOF marker).
+   * The resulting pointer can not be read from.*/
+  if (new_cur_word == d3_buffer_num_words(buffer)) {
+    ptr->cur_word = new_cur_word;
+    END_PROFILE_FUNC();
+    return;
+  }
+
   *ptr = d3_buffer_seek(buffer, new_cur_word);
   END_PROFILE_FUNC();
 }
@@ -623,8 +707,11 @@ d3_pointer d3_buffer_seek(d3_buffer *buffer, size_t word_pos) {
 void d3_pointer_close(d3_buffer *buffer, d3_pointer *ptr) {
   BEGIN_PROFILE_FUNC();
 
-  multi_file_t *file = &buffer->files[ptr->cur_file].file;
-  multi_file_return(file, &ptr->multi_file_index);
+  /* The pointer does not point into any file (e.g. after a failed seek)*/
+  if (ptr->cur_file < buffer->num_files) {
+    multi_file_t *file = &buffer->files[ptr->cur_file].file;
+    multi_file_return(file, &ptr->multi_file_index);
+  }
 #ifndef NO_THREAD_SAFETY
   ptr->multi_file_index.index = ULONG_MAX;
   ptr->multi_file_index.file_handle = NULL;
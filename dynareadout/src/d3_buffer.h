@@ -64,6 +64,14 @@ extern "C" {
 d3_buffer d3_buffer_open(const char *root_file_name);
 /* Cleans everything up. Should be called sometime after d3_buffer_open*/
 void d3_buffer_close(d3_buffer *buffer);
+/* Updates the sizes of all files and opens the files of the family which have
+ * been created since the buffer has been opened. Used to read a d3plot family
+ * which is still being written. Sets error_string on error*/
+void d3_buffer_refresh(d3_buffer *buffer);
+/* Returns the word position after the last word of the file at file_index*/
+size_t d3_buffer_file_end(const d3_buffer *buffer, size_t file_index);
+/* Returns the number of words of all files*/
+size_t d3_buffer_num_words(const d3_buffer *buffer);
 /* Read a given number of words from the current position. words already needs
  * to be allocated with at least num_words*word_size bytes. Sets error_string on
  * error*/
@@ -80,7 +88,8 @@ void d3_buffer_read_double_word(d3_buffer *buffer, d3_pointer *ptr,
 /* Sets error_string on error*/
 void d3_buffer_read_vec3(d3_buffer *buffer, d3_pointer *ptr, double *words);
 /* Skip an arbitrary amount of words. Also handles skips across multiple files.
- * Sets error_string on error*/
+ * Skipping to the end of the last file is allowed. Sets error_string on
+ * error*/
 void d3_buffer_skip_words(d3_buffer *buffer, d3_pointer *ptr, size_t num_words);
 /* Similar to d3_buffer_skip_words, but it skips bytes instead of words. Try to
  * use the words one. This function ist just for some cases where the number of
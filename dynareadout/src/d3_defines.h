@@ -219,6 +219,10 @@ typedef struct {
 #define D3_CODE_OLD_DYNA3D 2
 #define D3_CODE_NIKE3D_LS_DYNA3D_LS_NIKE3D 6
 
+#define D3PLOT_ERROR_OTHER 0
+#define D3PLOT_ERROR_STATE_OUT_OF_RANGE 1
+#define D3PLOT_ERROR_UNSUPPORTED_SECTION 2
+
 #define D3_EOF -999999.0
 
 #define D3PLT_PTR_TITLE 0
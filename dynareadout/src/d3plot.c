@@ -57,8 +57,11 @@ d3plot_file d3plot_open(const char *root_file_name) {
 
   d3plot_file plot_file;
   plot_file.error_string = NULL;
+  plot_file.error_kind = D3PLOT_ERROR_OTHER;
+  plot_file.error_section = NULL;
   plot_file.data_pointers = NULL;
   plot_file.num_states = 0;
+  plot_file.next_state_word = 0;
 
   plot_file.buffer = d3_buffer_open(root_file_name);
   if (plot_file.buffer.error_string) {
@@ -291,17 +294,17 @@ d3plot_file d3plot_open(const char *root_file_name) {
   /* We are done with CONTROL DATA now comes the real data*/
 
   if (mattyp) {
-    ERROR_AND_RETURN("MATERIAL TYPE DATA is not supported");
+    UNSUPPORTED_SECTION_AND_RETURN("MATERIAL TYPE DATA", "supported");
   }
   if (CDA.ialemat) {
-    ERROR_AND_RETURN("FLUID MATERIAL ID DATA is not implemented");
+    UNSUPPORTED_SECTION_AND_RETURN("FLUID MATERIAL ID DATA", "implemented");
   }
   if (CDA.nmsph) {
-    ERROR_AND_RETURN(
-        "SMOOTH PARTICLE HYDRODYNAMICS ELEMENT DATA FLAGS is not implemented");
+    UNSUPPORTED_SECTION_AND_RETURN(
+        "SMOOTH PARTICLE HYDRODYNAMICS ELEMENT DATA FLAGS", "implemented");
   }
   if (npefg) {
-    ERROR_AND_RETURN("PARTICLE DATA is not implemented");
+    UNSUPPORTED_SECTION_AND_RETURN("PARTICLE DATA", "implemented");
   }
 
   if (!_d3plot_read_geometry_data(&plot_file, &d3_ptr)) {
@@ -325,16 +328,17 @@ d3plot_file d3plot_open(const char *root_file_name) {
   }
 
   if (CDA.nmsph > 0) {
-    ERROR_AND_RETURN("SMOOTH PARTICLE HYDRODYNAMICS NODE AND "
-                     "MATERIAL LIST is not implemented");
+    UNSUPPORTED_SECTION_AND_RETURN("SMOOTH PARTICLE HYDRODYNAMICS NODE AND "
+                                   "MATERIAL LIST",
+                                   "implemented");
   }
 
   if (npefg > 0) {
-    ERROR_AND_RETURN("PARTICLE GEOMETRY DATA is not implemented");
+    UNSUPPORTED_SECTION_AND_RETURN("PARTICLE GEOMETRY DATA", "implemented");
   }
 
   if (CDA.ndim > 5) {
-    ERROR_AND_RETURN("RIGID ROAD SURFACE DATA is not implemented");
+    UNSUPPORTED_SECTION_AND_RETURN("RIGID ROAD SURFACE DATA", "implemented");
   }
 
   /* Read EOF marker*/
@@ -353,35 +357,49 @@ d3plot_file d3plot_open(const char *root_file_name) {
   }
 
   if (CDA.ncfdv1 == 67108864) {
-    ERROR_AND_RETURN("EXTRA DATA TYPES is not implemented");
+    UNSUPPORTED_SECTION_AND_RETURN("EXTRA DATA TYPES", "implemented");
   }
 
-  if (!d3_buffer_next_file(&plot_file.buffer, &d3_ptr)) {
-    ERROR_AND_RETURN("Too few files");
-  }
-  if (plot_file.buffer.error_string) {
-    ERROR_AND_RETURN_F("Failed to switch to the next file: %s",
-                       plot_file.buffer.error_string);
+  /* Here comes the STATE DATA. It starts in the next file, which may not
+   * have been written yet.*/
+  plot_file.next_state_word =
+      d3_buffer_file_end(&plot_file.buffer, d3_ptr.cur_file);
+  d3_pointer_close(&plot_file.buffer, &d3_ptr);
+
+  _d3plot_read_states(&plot_file, 0);
+
+  END_PROFILE_FUNC();
+  return plot_file;
+}
+
+void d3plot_refresh(d3plot_file *plot_file) {
+  BEGIN_PROFILE_FUNC();
+
+  if (!plot_file->buffer.root_file_name) {
+    ERROR_AND_NO_RETURN_PTR("The d3plot file has already been closed");
+    END_PROFILE_FUNC();
+    return;
   }
 
-  /* Here comes the STATE DATA*/
+  D3PLOT_CLEAR_ERROR_STRING();
 
-  int result = 1;
-  while (result) {
-    result = _d3plot_read_state_data(&plot_file, &d3_ptr);
-    if (result == 2) {
-      if (!d3_buffer_next_file(&plot_file.buffer, &d3_ptr)) {
-        break;
-      }
-      if (plot_file.buffer.error_string) {
-        ERROR_AND_RETURN_F("Failed to switch to the next file: %s",
-                           plot_file.buffer.error_string);
-      }
-    }
+  d3_buffer_refresh(&plot_file->buffer);
+  if (plot_file->buffer.error_string) {
+    ERROR_AND_NO_RETURN_F_PTR("Failed to refresh the files: %s",
+                              plot_file->buffer.error_string);
+    free(plot_file->buffer.error_string);
+    plot_file->buffer.error_string = NULL;
+    END_PROFILE_FUNC();
+    return;
   }
 
+  _d3plot_read_states(plot_file, 1);
+
+  /* A read error of the buffer is already part of error_string*/
+  free(plot_file->buffer.error_string);
+  plot_file->buffer.error_string = NULL;
+
   END_PROFILE_FUNC();
-  return plot_file;
 }
 
 void d3plot_close(d3plot_file *plot_file) {
@@ -392,6 +410,7 @@ void d3plot_close(d3plot_file *plot_file) {
   free(plot_file->data_pointers);
   free(plot_file->error_string);
 
+  plot_file->data_pointers = NULL;
   plot_file->num_states = 0;
   plot_file->error_string = NULL;
 
@@ -595,7 +614,7 @@ double *d3plot_read_node_coordinates(d3plot_file *plot_file, size_t state,
                                      size_t *num_nodes) {
   BEGIN_PROFILE_FUNC();
 
-  double *data = _d3plot_read_node_data(plot_file, state, num_nodes,
+  double *data = _d3plot_read_node_data(plot_file, state, NULL, 0, num_nodes,
                                         D3PLT_PTR_STATE_NODE_COORDS);
 
   END_PROFILE_FUNC();
@@ -670,7 +689,7 @@ double *d3plot_read_node_velocity(d3plot_file *plot_file, size_t state,
                                   size_t *num_nodes) {
   BEGIN_PROFILE_FUNC();
 
-  double *data = _d3plot_read_node_data(plot_file, state, num_nodes,
+  double *data = _d3plot_read_node_data(plot_file, state, NULL, 0, num_nodes,
                                         D3PLT_PTR_STATE_NODE_VEL);
 
   END_PROFILE_FUNC();
@@ -744,13 +763,57 @@ double *d3plot_read_node_acceleration(d3plot_file *plot_file, size_t state,
                                       size_t *num_nodes) {
   BEGIN_PROFILE_FUNC();
 
-  double *data = _d3plot_read_node_data(plot_file, state, num_nodes,
+  double *data = _d3plot_read_node_data(plot_file, state, NULL, 0, num_nodes,
                                         D3PLT_PTR_STATE_NODE_ACC);
 
   END_PROFILE_FUNC();
   return data;
 }
 
+double *d3plot_read_node_coordinates_subset(d3plot_file *plot_file,
+                                            size_t state,
+                                            const size_t *node_indices,
+                                            size_t num_nodes) {
+  BEGIN_PROFILE_FUNC();
+
+  size_t num_read_nodes;
+  double *data =
+      _d3plot_read_node_data(plot_file, state, node_indices, num_nodes,
+                             &num_read_nodes, D3PLT_PTR_STATE_NODE_COORDS);
+
+  END_PROFILE_FUNC();
+  return data;
+}
+
+double *d3plot_read_node_velocity_subset(d3plot_file *plot_file, size_t state,
+                                         const size_t *node_indices,
+                                         size_t num_nodes) {
+  BEGIN_PROFILE_FUNC();
+
+  size_t num_read_nodes;
+  double *data =
+      _d3plot_read_node_data(plot_file, state, node_indices, num_nodes,
+                             &num_read_nodes, D3PLT_PTR_STATE_NODE_VEL);
+
+  END_PROFILE_FUNC();
+  return data;
+}
+
+double *d3plot_read_node_acceleration_subset(d3plot_file *plot_file,
+                                             size_t state,
+                                             const size_t *node_indices,
+                                             size_t num_nodes) {
+  BEGIN_PROFILE_FUNC();
+
+  size_t num_read_nodes;
+  double *data =
+      _d3plot_read_node_data(plot_file, state, node_indices, num_nodes,
+                             &num_read_nodes, D3PLT_PTR_STATE_NODE_ACC);
+
+  END_PROFILE_FUNC();
+  return data;
+}
+
 double *d3plot_read_all_node_acceleration(d3plot_file *plot_file,
                                           size_t *num_nodes,
                                           size_t *num_time_steps) {
@@ -819,7 +882,7 @@ float *d3plot_read_node_coordinates_32(d3plot_file *plot_file, size_t state,
                                        size_t *num_nodes) {
   BEGIN_PROFILE_FUNC();
 
-  float *data = _d3plot_read_node_data_32(plot_file, state, num_nodes,
+  float *data = _d3plot_read_node_data_32(plot_file, state, NULL, 0, num_nodes,
                                           D3PLT_PTR_STATE_NODE_COORDS);
 
   END_PROFILE_FUNC();
@@ -894,7 +957,7 @@ float *d3plot_read_node_velocity_32(d3plot_file *plot_file, size_t state,
                                     size_t *num_nodes) {
   BEGIN_PROFILE_FUNC();
 
-  float *data = _d3plot_read_node_data_32(plot_file, state, num_nodes,
+  float *data = _d3plot_read_node_data_32(plot_file, state, NULL, 0, num_nodes,
                                           D3PLT_PTR_STATE_NODE_VEL);
 
   END_PROFILE_FUNC();
@@ -969,7 +1032,7 @@ float *d3plot_read_node_acceleration_32(d3plot_file *plot_file, size_t state,
                                         size_t *num_nodes) {
   BEGIN_PROFILE_FUNC();
 
-  float *data = _d3plot_read_node_data_32(plot_file, state, num_nodes,
+  float *data = _d3plot_read_node_data_32(plot_file, state, NULL, 0, num_nodes,
                                           D3PLT_PTR_STATE_NODE_ACC);
 
   END_PROFILE_FUNC();
@@ -1045,7 +1108,9 @@ double d3plot_read_time(d3plot_file *plot_file, size_t state) {
   D3PLOT_CLEAR_ERROR_STRING();
 
   if (state >= plot_file->num_states) {
-    ERROR_AND_NO_RETURN_F_PTR("%zu is out of bounds for the states", state);
+    ERROR_KIND_AND_NO_RETURN_F_PTR(D3PLOT_ERROR_STATE_OUT_OF_RANGE,
+                                   "%zu is out of bounds for the states",
+                                   state);
 
     END_PROFILE_FUNC();
     return -1.0;
@@ -1140,7 +1205,9 @@ float d3plot_read_time_32(d3plot_file *plot_file, size_t state) {
   D3PLOT_CLEAR_ERROR_STRING();
 
   if (state >= plot_file->num_states) {
-    ERROR_AND_NO_RETURN_F_PTR("%zu is out of bounds for the states", state);
+    ERROR_KIND_AND_NO_RETURN_F_PTR(D3PLOT_ERROR_STATE_OUT_OF_RANGE,
+                                   "%zu is out of bounds for the states",
+                                   state);
 
     END_PROFILE_FUNC();
     return -1.0f;
@@ -1232,17 +1299,35 @@ float *d3plot_read_all_time_32(d3plot_file *plot_file, size_t *num_states) {
 
 d3plot_solid *d3plot_read_solids_state(d3plot_file *plot_file, size_t state,
                                        size_t *num_solids) {
+  return _d3plot_read_solids_state(plot_file, state, NULL, 0, num_solids);
+}
+
+d3plot_solid *d3plot_read_solids_state_subset(d3plot_file *plot_file,
+                                              size_t state,
+                                              const size_t *solid_indices,
+                                              size_t num_solids) {
+  size_t num_read_solids;
+  return _d3plot_read_solids_state(plot_file, state, solid_indices, num_solids,
+                                   &num_read_solids);
+}
+
+d3plot_solid *_d3plot_read_solids_state(d3plot_file *plot_file, size_t state,
+                                        const size_t *indices,
+                                        size_t num_indices,
+                                        size_t *num_solids) {
   BEGIN_PROFILE_FUNC();
   D3PLOT_CLEAR_ERROR_STRING();
 
-  *num_solids = plot_file->control_data.nel8;
+  *num_solids = indices ? num_indices : plot_file->control_data.nel8;
   if (*num_solids == 0) {
     END_PROFILE_FUNC();
     return NULL;
   }
 
   if (state >= plot_file->num_states) {
-    ERROR_AND_NO_RETURN_F_PTR("%zu is out of bounds for the states", state);
+    ERROR_KIND_AND_NO_RETURN_F_PTR(D3PLOT_ERROR_STATE_OUT_OF_RANGE,
+                                   "%zu is out of bounds for the states",
+                                   state);
     *num_solids = 0;
 
     END_PROFILE_FUNC();
@@ -1252,18 +1337,12 @@ d3plot_solid *d3plot_read_solids_state(d3plot_file *plot_file, size_t state,
   d3plot_solid *solids = malloc(*num_solids * sizeof(d3plot_solid));
   if (plot_file->buffer.word_size == 4) {
     float *data =
-        malloc((plot_file->control_data.nel8 * plot_file->control_data.nv3d) *
-               sizeof(float));
+        malloc(*num_solids * plot_file->control_data.nv3d * sizeof(float));
 
-    d3_pointer d3_ptr = d3_buffer_read_words_at(
-        &plot_file->buffer, data,
-        plot_file->control_data.nel8 * plot_file->control_data.nv3d,
-        plot_file->data_pointers[D3PLT_PTR_STATES + state] +
-            plot_file->data_pointers[D3PLT_PTR_STATE_ELEMENT_SOLID]);
-    d3_pointer_close(&plot_file->buffer, &d3_ptr);
-    if (plot_file->buffer.error_string) {
-      ERROR_AND_NO_RETURN_F_PTR("Failed to read words: %s",
-                                plot_file->buffer.error_string);
+    if (!_d3plot_read_state_entries(
+            plot_file, state, D3PLT_PTR_STATE_ELEMENT_SOLID,
+            plot_file->control_data.nv3d, plot_file->control_data.nel8,
+            indices, num_indices, data)) {
       *num_solids = 0;
       free(data);
       free(solids);
@@ -1317,18 +1396,12 @@ d3plot_solid *d3plot_read_solids_state(d3plot_file *plot_file, size_t state,
     free(data);
   } else {
     double *data =
-        malloc((plot_file->control_data.nel8 * plot_file->control_data.nv3d) *
-               sizeof(double));
+        malloc(*num_solids * plot_file->control_data.nv3d * sizeof(double));
 
-    d3_pointer d3_ptr = d3_buffer_read_words_at(
-        &plot_file->buffer, data,
-        plot_file->control_data.nel8 * plot_file->control_data.nv3d,
-        plot_file->data_pointers[D3PLT_PTR_STATES + state] +
-            plot_file->data_pointers[D3PLT_PTR_STATE_ELEMENT_SOLID]);
-    d3_pointer_close(&plot_file->buffer, &d3_ptr);
-    if (plot_file->buffer.error_string) {
-      ERROR_AND_NO_RETURN_F_PTR("Failed to read words: %s",
-                                plot_file->buffer.error_string);
+    if (!_d3plot_read_state_entries(
+            plot_file, state, D3PLT_PTR_STATE_ELEMENT_SOLID,
+            plot_file->control_data.nv3d, plot_file->control_data.nel8,
+            indices, num_indices, data)) {
       *num_solids = 0;
       free(data);
       free(solids);
@@ -1370,10 +1443,29 @@ d3plot_thick_shell *
 d3plot_read_thick_shells_state(d3plot_file *plot_file, size_t state,
                                size_t *num_thick_shells,
                                size_t *num_history_variables) {
+  return _d3plot_read_thick_shells_state(plot_file, state, NULL, 0,
+                                         num_thick_shells,
+                                         num_history_variables);
+}
+
+d3plot_thick_shell *d3plot_read_thick_shells_state_subset(
+    d3plot_file *plot_file, size_t state, const size_t *thick_shell_indices,
+    size_t num_thick_shells, size_t *num_history_variables) {
+  size_t num_read_thick_shells;
+  return _d3plot_read_thick_shells_state(
+      plot_file, state, thick_shell_indices, num_thick_shells,
+      &num_read_thick_shells, num_history_variables);
+}
+
+d3plot_thick_shell *
+_d3plot_read_thick_shells_state(d3plot_file *plot_file, size_t state,
+                                const size_t *indices, size_t num_indices,
+                                size_t *num_thick_shells,
+                                size_t *num_history_variables) {
   BEGIN_PROFILE_FUNC();
   D3PLOT_CLEAR_ERROR_STRING();
 
-  *num_thick_shells = plot_file->control_data.nelt;
+  *num_thick_shells = indices ? num_indices : plot_file->control_data.nelt;
   if (*num_thick_shells == 0) {
     *num_history_variables = 0;
     END_PROFILE_FUNC();
@@ -1381,7 +1473,9 @@ d3plot_read_thick_shells_state(d3plot_file *plot_file, size_t state,
   }
 
   if (state >= plot_file->num_states) {
-    ERROR_AND_NO_RETURN_F_PTR("%zu is out of bounds for the states", state);
+    ERROR_KIND_AND_NO_RETURN_F_PTR(D3PLOT_ERROR_STATE_OUT_OF_RANGE,
+                                   "%zu is out of bounds for the states",
+                                   state);
     *num_thick_shells = 0;
 
     END_PROFILE_FUNC();
@@ -1396,21 +1490,17 @@ d3plot_read_thick_shells_state(d3plot_file *plot_file, size_t state,
   d3plot_thick_shell *thick_shells =
       malloc(*num_thick_shells * sizeof(d3plot_thick_shell));
   if (plot_file->buffer.word_size == 4) {
-    float *data = malloc(plot_file->control_data.nelt *
-                         plot_file->control_data.nv3dt * sizeof(float));
+    float *data = malloc(*num_thick_shells * plot_file->control_data.nv3dt *
+                         sizeof(float));
 
-    d3_pointer d3_ptr = d3_buffer_read_words_at(
-        &plot_file->buffer, data,
-        plot_file->control_data.nelt * plot_file->control_data.nv3dt,
-        plot_file->data_pointers[D3PLT_PTR_STATES + state] +
-            plot_file->data_pointers[D3PLT_PTR_STATE_ELEMENT_THICK_SHELL]);
-    d3_pointer_close(&plot_file->buffer, &d3_ptr);
-    if (plot_file->buffer.error_string) {
-      ERROR_AND_NO_RETURN_F_PTR("Failed to read words: %s",
-                                plot_file->buffer.error_string);
+    if (!_d3plot_read_state_entries(
+            plot_file, state, D3PLT_PTR_STATE_ELEMENT_THICK_SHELL,
+            plot_file->control_data.nv3dt, plot_file->control_data.nelt,
+            indices, num_indices, data)) {
       *num_thick_shells = 0;
       free(data);
       free(thick_shells);
+      free(history_variables);
 
       END_PROFILE_FUNC();
       return NULL;
@@ -1514,21 +1604,17 @@ d3plot_read_thick_shells_state(d3plot_file *plot_file, size_t state,
 
     free(data);
   } else {
-    double *data = malloc(plot_file->control_data.nelt *
-                          plot_file->control_data.nv3dt * sizeof(double));
+    double *data = malloc(*num_thick_shells * plot_file->control_data.nv3dt *
+                          sizeof(double));
 
-    d3_pointer d3_ptr = d3_buffer_read_words_at(
-        &plot_file->buffer, data,
-        plot_file->control_data.nelt * plot_file->control_data.nv3dt,
-        plot_file->data_pointers[D3PLT_PTR_STATES + state] +
-            plot_file->data_pointers[D3PLT_PTR_STATE_ELEMENT_THICK_SHELL]);
-    d3_pointer_close(&plot_file->buffer, &d3_ptr);
-    if (plot_file->buffer.error_string) {
-      ERROR_AND_NO_RETURN_F_PTR("Failed to read words: %s",
-                                plot_file->buffer.error_string);
+    if (!_d3plot_read_state_entries(
+            plot_file, state, D3PLT_PTR_STATE_ELEMENT_THICK_SHELL,
+            plot_file->control_data.nv3dt, plot_file->control_data.nelt,
+            indices, num_indices, data)) {
       *num_thick_shells = 0;
       free(data);
       free(thick_shells);
+      free(history_variables);
 
       END_PROFILE_FUNC();
       return NULL;
@@ -1615,17 +1701,34 @@ d3plot_read_thick_shells_state(d3plot_file *plot_file, size_t state,
 
 d3plot_beam *d3plot_read_beams_state(d3plot_file *plot_file, size_t state,
                                      size_t *num_beams) {
+  return _d3plot_read_beams_state(plot_file, state, NULL, 0, num_beams);
+}
+
+d3plot_beam *d3plot_read_beams_state_subset(d3plot_file *plot_file,
+                                            size_t state,
+                                            const size_t *beam_indices,
+                                            size_t num_beams) {
+  size_t num_read_beams;
+  return _d3plot_read_beams_state(plot_file, state, beam_indices, num_beams,
+                                  &num_read_beams);
+}
+
+d3plot_beam *_d3plot_read_beams_state(d3plot_file *plot_file, size_t state,
+                                      const size_t *indices,
+                                      size_t num_indices, size_t *num_beams) {
   BEGIN_PROFILE_FUNC();
   D3PLOT_CLEAR_ERROR_STRING();
 
-  *num_beams = plot_file->control_data.nel2;
+  *num_beams = indices ? num_indices : plot_file->control_data.nel2;
   if (*num_beams == 0) {
     END_PROFILE_FUNC();
     return NULL;
   }
 
   if (state >= plot_file->num_states) {
-    ERROR_AND_NO_RETURN_F_PTR("%zu is out of bounds for the states", state);
+    ERROR_KIND_AND_NO_RETURN_F_PTR(D3PLOT_ERROR_STATE_OUT_OF_RANGE,
+                                   "%zu is out of bounds for the states",
+                                   state);
     *num_beams = 0;
 
     END_PROFILE_FUNC();
@@ -1634,18 +1737,13 @@ d3plot_beam *d3plot_read_beams_state(d3plot_file *plot_file, size_t state,
 
   d3plot_beam *beams = malloc(*num_beams * sizeof(d3plot_beam));
   if (plot_file->buffer.word_size == 4) {
-    float *data = malloc(plot_file->control_data.nel2 *
-                         plot_file->control_data.nv1d * sizeof(float));
+    float *data = malloc(*num_beams * plot_file->control_data.nv1d *
+                         sizeof(float));
 
-    d3_pointer d3_ptr = d3_buffer_read_words_at(
-        &plot_file->buffer, data,
-        plot_file->control_data.nel2 * plot_file->control_data.nv1d,
-        plot_file->data_pointers[D3PLT_PTR_STATES + state] +
-            plot_file->data_pointers[D3PLT_PTR_STATE_ELEMENT_BEAM]);
-    d3_pointer_close(&plot_file->buffer, &d3_ptr);
-    if (plot_file->buffer.error_string) {
-      ERROR_AND_NO_RETURN_F_PTR("Failed to read words: %s",
-                                plot_file->buffer.error_string);
+    if (!_d3plot_read_state_entries(
+            plot_file, state, D3PLT_PTR_STATE_ELEMENT_BEAM,
+            plot_file->control_data.nv1d, plot_file->control_data.nel2,
+            indices, num_indices, data)) {
       *num_beams = 0;
       free(data);
       free(beams);
@@ -1674,18 +1772,13 @@ d3plot_beam *d3plot_read_beams_state(d3plot_file *plot_file, size_t state,
 
     free(data);
   } else {
-    double *data = malloc(plot_file->control_data.nel2 *
-                          plot_file->control_data.nv1d * sizeof(double));
+    double *data = malloc(*num_beams * plot_file->control_data.nv1d *
+                          sizeof(double));
 
-    d3_pointer d3_ptr = d3_buffer_read_words_at(
-        &plot_file->buffer, data,
-        plot_file->control_data.nel2 * plot_file->control_data.nv1d,
-        plot_file->data_pointers[D3PLT_PTR_STATES + state] +
-            plot_file->data_pointers[D3PLT_PTR_STATE_ELEMENT_BEAM]);
-    d3_pointer_close(&plot_file->buffer, &d3_ptr);
-    if (plot_file->buffer.error_string) {
-      ERROR_AND_NO_RETURN_F_PTR("Failed to read words: %s",
-                                plot_file->buffer.error_string);
+    if (!_d3plot_read_state_entries(
+            plot_file, state, D3PLT_PTR_STATE_ELEMENT_BEAM,
+            plot_file->control_data.nv1d, plot_file->control_data.nel2,
+            indices, num_indices, data)) {
       *num_beams = 0;
       free(data);
       free(beams);
@@ -1718,10 +1811,28 @@ d3plot_beam *d3plot_read_beams_state(d3plot_file *plot_file, size_t state,
 d3plot_shell *d3plot_read_shells_state(d3plot_file *plot_file, size_t state,
                                        size_t *num_shells,
                                        size_t *num_history_variables) {
+  return _d3plot_read_shells_state(plot_file, state, NULL, 0, num_shells,
+                                   num_history_variables);
+}
+
+d3plot_shell *d3plot_read_shells_state_subset(d3plot_file *plot_file,
+                                              size_t state,
+                                              const size_t *shell_indices,
+                                              size_t num_shells,
+                                              size_t *num_history_variables) {
+  size_t num_read_shells;
+  return _d3plot_read_shells_state(plot_file, state, shell_indices, num_shells,
+                                   &num_read_shells, num_history_variables);
+}
+
+d3plot_shell *_d3plot_read_shells_state(d3plot_file *plot_file, size_t state,
+                                        const size_t *indices,
+                                        size_t num_indices, size_t *num_shells,
+                                        size_t *num_history_variables) {
   BEGIN_PROFILE_FUNC();
   D3PLOT_CLEAR_ERROR_STRING();
 
-  *num_shells = plot_file->control_data.nel4;
+  *num_shells = indices ? num_indices : plot_file->control_data.nel4;
   if (*num_shells == 0) {
     *num_history_variables = 0;
     END_PROFILE_FUNC();
@@ -1729,7 +1840,9 @@ d3plot_shell *d3plot_read_shells_state(d3plot_file *plot_file, size_t state,
   }
 
   if (state >= plot_file->num_states) {
-    ERROR_AND_NO_RETURN_F_PTR("%zu is out of bounds for the states", state);
+    ERROR_KIND_AND_NO_RETURN_F_PTR(D3PLOT_ERROR_STATE_OUT_OF_RANGE,
+                                   "%zu is out of bounds for the states",
+                                   state);
     *num_shells = 0;
 
     END_PROFILE_FUNC();
@@ -1743,21 +1856,17 @@ d3plot_shell *d3plot_read_shells_state(d3plot_file *plot_file, size_t state,
 
   d3plot_shell *shells = malloc(*num_shells * sizeof(d3plot_shell));
   if (plot_file->buffer.word_size == 4) {
-    float *data = malloc(plot_file->control_data.nel4 *
-                         plot_file->control_data.nv2d * sizeof(float));
+    float *data = malloc(*num_shells * plot_file->control_data.nv2d *
+                         sizeof(float));
 
-    d3_pointer d3_ptr = d3_buffer_read_words_at(
-        &plot_file->buffer, data,
-        plot_file->control_data.nel4 * plot_file->control_data.nv2d,
-        plot_file->data_pointers[D3PLT_PTR_STATES + state] +
-            plot_file->data_pointers[D3PLT_PTR_STATE_ELEMENT_SHELL]);
-    d3_pointer_close(&plot_file->buffer, &d3_ptr);
-    if (plot_file->buffer.error_string) {
-      ERROR_AND_NO_RETURN_F_PTR("Failed to read words: %s",
-                                plot_file->buffer.error_string);
+    if (!_d3plot_read_state_entries(
+            plot_file, state, D3PLT_PTR_STATE_ELEMENT_SHELL,
+            plot_file->control_data.nv2d, plot_file->control_data.nel4,
+            indices, num_indices, data)) {
       *num_shells = 0;
       free(data);
       free(shells);
+      free(history_variables);
 
       END_PROFILE_FUNC();
       return NULL;
@@ -1882,21 +1991,17 @@ d3plot_shell *d3plot_read_shells_state(d3plot_file *plot_file, size_t state,
 
     free(data);
   } else {
-    double *data = malloc(plot_file->control_data.nel4 *
-                          plot_file->control_data.nv2d * sizeof(double));
+    double *data = malloc(*num_shells * plot_file->control_data.nv2d *
+                          sizeof(double));
 
-    d3_pointer d3_ptr = d3_buffer_read_words_at(
-        &plot_file->buffer, data,
-        plot_file->control_data.nel4 * plot_file->control_data.nv2d,
-        plot_file->data_pointers[D3PLT_PTR_STATES + state] +
-            plot_file->data_pointers[D3PLT_PTR_STATE_ELEMENT_SHELL]);
-    d3_pointer_close(&plot_file->buffer, &d3_ptr);
-    if (plot_file->buffer.error_string) {
-      ERROR_AND_NO_RETURN_F_PTR("Failed to read words: %s",
-                                plot_file->buffer.error_string);
+    if (!_d3plot_read_state_entries(
+            plot_file, state, D3PLT_PTR_STATE_ELEMENT_SHELL,
+            plot_file->control_data.nv2d, plot_file->control_data.nel4,
+            indices, num_indices, data)) {
       *num_shells = 0;
       free(data);
       free(shells);
+      free(history_variables);
 
       END_PROFILE_FUNC();
       return NULL;
@@ -2573,12 +2678,13 @@ int _get_nth_digit(d3_word value, int n) {
 }
 
 double *_d3plot_read_node_data(d3plot_file *plot_file, size_t state,
+                               const size_t *indices, size_t num_indices,
                                size_t *num_nodes, size_t data_type) {
   D3PLOT_CLEAR_ERROR_STRING();
 
   if (plot_file->buffer.word_size == 4) {
-    float *coords32 =
-        _d3plot_read_node_data_32(plot_file, state, num_nodes, data_type);
+    float *coords32 = _d3plot_read_node_data_32(
+        plot_file, state, indices, num_indices, num_nodes, data_type);
     if (!coords32) {
       return NULL;
     }
@@ -2599,21 +2705,18 @@ double *_d3plot_read_node_data(d3plot_file *plot_file, size_t state,
   }
 
   if (state >= plot_file->num_states) {
-    ERROR_AND_NO_RETURN_F_PTR("%zu is out of bounds for the states", state);
+    ERROR_KIND_AND_NO_RETURN_F_PTR(D3PLOT_ERROR_STATE_OUT_OF_RANGE,
+                                   "%zu is out of bounds for the states",
+                                   state);
     return NULL;
   }
 
-  *num_nodes = plot_file->control_data.numnp;
+  *num_nodes = indices ? num_indices : plot_file->control_data.numnp;
   double *coords = malloc(*num_nodes * 3 * sizeof(double));
 
-  d3_pointer d3_ptr = d3_buffer_read_words_at(
-      &plot_file->buffer, coords, *num_nodes * 3,
-      plot_file->data_pointers[D3PLT_PTR_STATES + state] +
-          plot_file->data_pointers[data_type]);
-  d3_pointer_close(&plot_file->buffer, &d3_ptr);
-  if (plot_file->buffer.error_string) {
-    ERROR_AND_NO_RETURN_F_PTR("Failed to read words: %s",
-                              plot_file->buffer.error_string);
+  if (!_d3plot_read_state_entries(plot_file, state, data_type, 3,
+                                  plot_file->control_data.numnp, indices,
+                                  num_indices, coords)) {
     *num_nodes = 0;
     free(coords);
     return NULL;
@@ -2623,12 +2726,13 @@ double *_d3plot_read_node_data(d3plot_file *plot_file, size_t state,
 }
 
 float *_d3plot_read_node_data_32(d3plot_file *plot_file, size_t state,
+                                 const size_t *indices, size_t num_indices,
                                  size_t *num_nodes, size_t data_type) {
   D3PLOT_CLEAR_ERROR_STRING();
 
   if (plot_file->buffer.word_size == 8) {
-    double *coords64 =
-        _d3plot_read_node_data(plot_file, state, num_nodes, data_type);
+    double *coords64 = _d3plot_read_node_data(
+        plot_file, state, indices, num_indices, num_nodes, data_type);
     if (!coords64) {
       return NULL;
     }
@@ -2649,21 +2753,18 @@ float *_d3plot_read_node_data_32(d3plot_file *plot_file, size_t state,
   }
 
   if (state >= plot_file->num_states) {
-    ERROR_AND_NO_RETURN_F_PTR("%zu is out of bounds for the states", state);
+    ERROR_KIND_AND_NO_RETURN_F_PTR(D3PLOT_ERROR_STATE_OUT_OF_RANGE,
+                                   "%zu is out of bounds for the states",
+                                   state);
     return NULL;
   }
 
-  *num_nodes = plot_file->control_data.numnp;
+  *num_nodes = indices ? num_indices : plot_file->control_data.numnp;
   float *coords = malloc(*num_nodes * 3 * sizeof(float));
 
-  d3_pointer d3_ptr = d3_buffer_read_words_at(
-      &plot_file->buffer, coords, *num_nodes * 3,
-      plot_file->data_pointers[D3PLT_PTR_STATES + state] +
-          plot_file->data_pointers[data_type]);
-  d3_pointer_close(&plot_file->buffer, &d3_ptr);
-  if (plot_file->buffer.error_string) {
-    ERROR_AND_NO_RETURN_F_PTR("Failed to read words: %s",
-                              plot_file->buffer.error_string);
+  if (!_d3plot_read_state_entries(plot_file, state, data_type, 3,
+                                  plot_file->control_data.numnp, indices,
+                                  num_indices, coords)) {
     *num_nodes = 0;
     free(coords);
     return NULL;
@@ -2672,6 +2773,48 @@ float *_d3plot_read_node_data_32(d3plot_file *plot_file, size_t state,
   return coords;
 }
 
+int _d3plot_read_state_entries(d3plot_file *plot_file, size_t state,
+                               size_t data_type, size_t words_per_entry,
+                               size_t num_entries, const size_t *indices,
+                               size_t num_indices, void *words) {
+  const size_t word_pos = plot_file->data_pointers[D3PLT_PTR_STATES + state] +
+                          plot_file->data_pointers[data_type];
+
+  if (!indices) {
+    d3_pointer d3_ptr =
+        d3_buffer_read_words_at(&plot_file->buffer, words,
+                                num_entries * words_per_entry, word_pos);
+    d3_pointer_close(&plot_file->buffer, &d3_ptr);
+  } else {
+    uint8_t *entry = words;
+    size_t i = 0;
+    while (i < num_indices && !plot_file->buffer.error_string) {
+      if (indices[i] >= num_entries) {
+        ERROR_AND_NO_RETURN_F_PTR("The index %zu is out of bounds for %zu "
+                                  "entries",
+                                  indices[i], num_entries);
+        return 0;
+      }
+
+      d3_pointer d3_ptr = d3_buffer_read_words_at(
+          &plot_file->buffer, entry, words_per_entry,
+          word_pos + indices[i] * words_per_entry);
+      d3_pointer_close(&plot_file->buffer, &d3_ptr);
+
+      entry += words_per_entry * plot_file->buffer.word_size;
+      i++;
+    }
+  }
+
+  if (plot_file->buffer.error_string) {
+    ERROR_AND_NO_RETURN_F_PTR("Failed to read words: %s",
+                              plot_file->buffer.error_string);
+    return 0;
+  }
+
+  return 1;
+}
+
 d3_word *_d3plot_read_ids(d3plot_file *plot_file, size_t *num_ids,
                           size_t data_type, size_t num_ids_value) {
   D3PLOT_CLEAR_ERROR_STRING();
@@ -92,12 +92,28 @@ typedef struct {
   /* This array holds the word locations of different data*/
   size_t *data_pointers;
   size_t num_states;
+  /* The word position at which the next state is expected*/
+  size_t next_state_word;
 
   d3_buffer buffer;
   /* This holds an error after calling some functions*/
   char *error_string;
+  /* The kind of error_string. One of the D3PLOT_ERROR_* defines*/
+  uint8_t error_kind;
+  /* The name of the section if error_kind is D3PLOT_ERROR_UNSUPPORTED_SECTION*/
+  const char *error_section;
 } d3plot_file;
 
+/* The number of words of the sections of one state. The time is not part of
+ * any section.*/
+typedef struct {
+  size_t global;
+  size_t node_data;
+  size_t therm_data;
+  size_t elem_data;
+  size_t element_deletion;
+} d3plot_state_layout;
+
 #ifdef __cplusplus
 extern "C" {
 #endif
@@ -107,6 +123,12 @@ extern "C" {
 d3plot_file d3plot_open(const char *root_file_name);
 /* Close a d3plot_file and deallocate all the memory*/
 void d3plot_close(d3plot_file *plot_file);
+/* Reads the states which have been written since the d3plot_file has been
+ * opened or refreshed. This includes new files of the family. A state is only
+ * read once the word after it has been written (the next state or the EOF
+ * marker), so that a state which is still being written is never read. Sets
+ * error_string on error*/
+void d3plot_refresh(d3plot_file *plot_file);
 /* Read all ids of the nodes. The return value needs to be deallocated by free*/
 d3_word *d3plot_read_node_ids(d3plot_file *plot_file, size_t *num_ids);
 /* Read all ids of the solid elements. The return value needs to be deallocated
@@ -160,6 +182,22 @@ double *d3plot_read_node_acceleration(d3plot_file *plot_file, size_t state,
 double *d3plot_read_all_node_acceleration(d3plot_file *plot_file,
                                           size_t *num_nodes,
                                           size_t *num_time_steps);
+/* The same as d3plot_read_node_coordinates, but only reads the num_nodes nodes
+ * at node_indices (e.g. of d3plot_part_get_node_indices) in their order. The
+ * return value needs to deallocated by free*/
+double *d3plot_read_node_coordinates_subset(d3plot_file *plot_file,
+                                            size_t state,
+                                            const size_t *node_indices,
+                                            size_t num_nodes);
+/* The same as d3plot_read_node_coordinates_subset, but for the velocity*/
+double *d3plot_read_node_velocity_subset(d3plot_file *plot_file, size_t state,
+                                         const size_t *node_indices,
+                                         size_t num_nodes);
+/* The same as d3plot_read_node_coordinates_subset, but for the acceleration*/
+double *d3plot_read_node_acceleration_subset(d3plot_file *plot_file,
+                                             size_t state,
+                                             const size_t *node_indices,
+                                             size_t num_nodes);
 /* The same as d3plot_read_node_coordinates but it does not convert floats to
  * double. It does the opposite if the word size is 8*/
 float *d3plot_read_node_coordinates_32(d3plot_file *plot_file, size_t state,
@@ -223,6 +261,30 @@ d3plot_beam *d3plot_read_beams_state(d3plot_file *plot_file, size_t state,
 d3plot_shell *d3plot_read_shells_state(d3plot_file *plot_file, size_t state,
                                        size_t *num_shells,
                                        size_t *num_history_variables);
+/* The same as d3plot_read_solids_state, but only reads the num_solids solids
+ * at solid_indices (e.g. of a d3plot_part) in their order*/
+d3plot_solid *d3plot_read_solids_state_subset(d3plot_file *plot_file,
+                                              size_t state,
+                                              const size_t *solid_indices,
+                                              size_t num_solids);
+/* The same as d3plot_read_thick_shells_state, but only reads the
+ * num_thick_shells thick shells at thick_shell_indices in their order*/
+d3plot_thick_shell *d3plot_read_thick_shells_state_subset(
+    d3plot_file *plot_file, size_t state, const size_t *thick_shell_indices,
+    size_t num_thick_shells, size_t *num_history_variables);
+/* The same as d3plot_read_beams_state, but only reads the num_beams beams at
+ * beam_indices in their order*/
+d3plot_beam *d3plot_read_beams_state_subset(d3plot_file *plot_file,
+                                            size_t state,
+                                            const size_t *beam_indices,
+                                            size_t num_beams);
+/* The same as d3plot_read_shells_state, but only reads the num_shells shells
+ * at shell_indices in their order*/
+d3plot_shell *d3plot_read_shells_state_subset(d3plot_file *plot_file,
+                                              size_t state,
+                                              const size_t *shell_indices,
+                                              size_t num_shells,
+                                              size_t *num_history_variables);
 /* Returns the node connectivity + material number of all 8 node solid
  * elements. The return value needs to be deallocated by free*/
 d3plot_solid_con *d3plot_read_solid_elements(d3plot_file *plot_file,
@@ -276,7 +338,16 @@ int _d3plot_read_adapted_element_parent_list(d3plot_file *plot_file,
 /* HEADER, PART & CONTACT INTERFACE TITLES pg. 22*/
 int _d3plot_read_header(d3plot_file *plot_file, d3_pointer *d3_ptr);
 /* STATE DATA pg. 31*/
-int _d3plot_read_state_data(d3plot_file *plot_file, d3_pointer *d3_ptr);
+int _d3plot_read_state_data(d3plot_file *plot_file, d3_pointer *d3_ptr,
+                            const d3plot_state_layout *layout);
+/* Computes the layout of the states from the control data. Returns 0 on
+ * error*/
+int _d3plot_state_layout(d3plot_file *plot_file, d3plot_state_layout *layout);
+/* Returns the number of words of one state including the time*/
+size_t _d3plot_state_size(const d3plot_state_layout *layout);
+/* Reads all complete states beginning at next_state_word. If refresh is set
+ * the files may still be written. Returns 0 on error*/
+int _d3plot_read_states(d3plot_file *plot_file, int refresh);
 /***************************/
 
 /***** Private Functions ********/
@@ -287,13 +358,42 @@ const char *_d3plot_get_file_type_name(d3_word file_type);
  * value=82376345, n=5 -> rv=3*/
 int _get_nth_digit(d3_word value, int n);
 /* A nice function to read node coordinates, velocity and acceleration.
- * data_type is one of the D3PLT_PTR values*/
+ * data_type is one of the D3PLT_PTR values. If indices is not NULL only the
+ * num_indices nodes at indices are read*/
 double *_d3plot_read_node_data(d3plot_file *plot_file, size_t state,
+                               const size_t *indices, size_t num_indices,
                                size_t *num_nodes, size_t data_type);
 /* The same as _d3plot_read_node_data but it does not convert floats to
  * double. It does the opposite if the word size is 8*/
 float *_d3plot_read_node_data_32(d3plot_file *plot_file, size_t state,
+                                 const size_t *indices, size_t num_indices,
                                  size_t *num_nodes, size_t data_type);
+/* Reads the entries of words_per_entry words each of the section data_type
+ * (one of the D3PLT_PTR_STATE values) of a state into words. If indices is
+ * NULL all num_entries entries are read, otherwise only the num_indices
+ * entries at indices in their order. Returns 0 on error*/
+int _d3plot_read_state_entries(d3plot_file *plot_file, size_t state,
+                               size_t data_type, size_t words_per_entry,
+                               size_t num_entries, const size_t *indices,
+                               size_t num_indices, void *words);
+/* The element readers of one state. If indices is not NULL only the
+ * num_indices elements at indices are read*/
+d3plot_solid *_d3plot_read_solids_state(d3plot_file *plot_file, size_t state,
+                                        const size_t *indices,
+                                        size_t num_indices,
+                                        size_t *num_solids);
+d3plot_thick_shell *
+_d3plot_read_thick_shells_state(d3plot_file *plot_file, size_t state,
+                                const size_t *indices, size_t num_indices,
+                                size_t *num_thick_shells,
+                                size_t *num_history_variables);
+d3plot_beam *_d3plot_read_beams_state(d3plot_file *plot_file, size_t state,
+                                      const size_t *indices,
+                                      size_t num_indices, size_t *num_beams);
+d3plot_shell *_d3plot_read_shells_state(d3plot_file *plot_file, size_t state,
+                                        const size_t *indices,
+                                        size_t num_indices, size_t *num_shells,
+                                        size_t *num_history_variables);
 /* A nice function to read node and element ids*/
 d3_word *_d3plot_read_ids(d3plot_file *plot_file, size_t *num_ids,
                           size_t data_type, size_t num_ids_value);
@@ -48,7 +48,8 @@ int _d3plot_read_geometry_data(d3plot_file *plot_file, d3_pointer *d3_ptr) {
   size_t data_pointer = geometry_start_word;
 
   if (CDP.element_connectivity_packed) {
-    ERROR_AND_NO_RETURN_PTR("Packed Element Connectivity is not supported");
+    UNSUPPORTED_SECTION_AND_NO_RETURN_PTR("Packed Element Connectivity",
+                                          "supported");
     END_PROFILE_FUNC();
     return 0;
   }
@@ -30,23 +30,30 @@
 #include <stdlib.h>
 #include <string.h>
 
+/* Sets the error of pf (plot_file or *plot_file). kind is one of the
+ * D3PLOT_ERROR_* defines and section the name of the unsupported section or
+ * NULL*/
+#define D3PLOT_SET_ERROR(pf, msg, kind, section)                               \
+  if ((pf).error_string)                                                       \
+    free((pf).error_string);                                                   \
+  (pf).error_string = malloc(strlen(msg) + 1);                                 \
+  sprintf((pf).error_string, "%s", msg);                                       \
+  (pf).error_kind = kind;                                                      \
+  (pf).error_section = section;
+
 #define ERROR_AND_NO_RETURN_PTR(msg)                                           \
-  if (plot_file->error_string)                                                 \
-    free(plot_file->error_string);                                             \
-  plot_file->error_string = malloc(strlen(msg) + 1);                           \
-  sprintf(plot_file->error_string, "%s", msg);
+  D3PLOT_SET_ERROR(*plot_file, msg, D3PLOT_ERROR_OTHER, NULL)
 #define ERROR_AND_NO_RETURN_F_PTR(format_str, ...)                             \
+  ERROR_KIND_AND_NO_RETURN_F_PTR(D3PLOT_ERROR_OTHER, format_str, __VA_ARGS__)
+#define ERROR_KIND_AND_NO_RETURN_F_PTR(kind, format_str, ...)                  \
   {                                                                            \
     char format_buffer[1024];                                                  \
     sprintf(format_buffer, format_str, __VA_ARGS__);                           \
-    ERROR_AND_NO_RETURN_PTR(format_buffer);                                    \
+    D3PLOT_SET_ERROR(*plot_file, format_buffer, kind, NULL);                   \
   }
 #define ERROR_AND_RETURN(msg)                                                  \
   d3_pointer_close(&plot_file.buffer, &d3_ptr);                                \
-  if (plot_file.error_string)                                                  \
-    free(plot_file.error_string);                                              \
-  plot_file.error_string = malloc(strlen(msg) + 1);                            \
-  sprintf(plot_file.error_string, "%s", msg);                                  \
+  D3PLOT_SET_ERROR(plot_file, msg, D3PLOT_ERROR_OTHER, NULL);                  \
   END_PROFILE_FUNC();                                                          \
   return plot_file;
 #define ERROR_AND_RETURN_F(format_str, ...)                                    \
@@ -56,10 +63,7 @@
     ERROR_AND_RETURN(format_buffer);                                           \
   }
 #define ERROR_AND_RETURN_PTR(msg)                                              \
-  if (plot_file->error_string)                                                 \
-    free(plot_file->error_string);                                             \
-  plot_file->error_string = malloc(strlen(msg) + 1);                           \
-  sprintf(plot_file->error_string, "%s", msg);                                 \
+  D3PLOT_SET_ERROR(*plot_file, msg, D3PLOT_ERROR_OTHER, NULL);                 \
   return;
 #define ERROR_AND_RETURN_F_PTR(format_str, ...)                                \
   {                                                                            \
@@ -67,8 +71,21 @@
     sprintf(format_buffer, format_str, __VA_ARGS__);                           \
     ERROR_AND_RETURN_PTR(format_buffer);                                       \
   }
+/* section needs to be a string literal. reason is "supported" or
+ * "implemented".*/
+#define UNSUPPORTED_SECTION_AND_RETURN(section, reason)                        \
+  d3_pointer_close(&plot_file.buffer, &d3_ptr);                                \
+  D3PLOT_SET_ERROR(plot_file, section " is not " reason,                       \
+                   D3PLOT_ERROR_UNSUPPORTED_SECTION, section);                 \
+  END_PROFILE_FUNC();                                                          \
+  return plot_file;
+#define UNSUPPORTED_SECTION_AND_NO_RETURN_PTR(section, reason)                 \
+  D3PLOT_SET_ERROR(*plot_file, section " is not " reason,                      \
+                   D3PLOT_ERROR_UNSUPPORTED_SECTION, section)
 #define D3PLOT_CLEAR_ERROR_STRING()                                            \
   free(plot_file->error_string);                                               \
-  plot_file->error_string = NULL;
+  plot_file->error_string = NULL;                                              \
+  plot_file->error_kind = D3PLOT_ERROR_OTHER;                                  \
+  plot_file->error_section = NULL;
 
 #endif
@@ -39,7 +39,8 @@
 
 #include "d3plot_error_macros.h"
 
-int _d3plot_read_state_data(d3plot_file *plot_file, d3_pointer *d3_ptr) {
+int _d3plot_read_state_data(d3plot_file *plot_file, d3_pointer *d3_ptr,
+                            const d3plot_state_layout *layout) {
   BEGIN_PROFILE_FUNC();
 
   const size_t state_start = d3_ptr->cur_word;
@@ -183,9 +184,9 @@ int _d3plot_read_state_data(d3plot_file *plot_file, d3_pointer *d3_ptr) {
   const size_t global_end = d3_ptr->cur_word;
   const size_t global_size = global_end - global_start;
 
-  if (global_size != CDP.nglbv) {
-    ERROR_AND_NO_RETURN_F_PTR("Size of GLOBAL is %zu instead of %llu",
-                              global_size, CDP.nglbv);
+  if (global_size != layout->global) {
+    ERROR_AND_NO_RETURN_F_PTR("Size of GLOBAL is %zu instead of %zu",
+                              global_size, layout->global);
     END_PROFILE_FUNC();
     return 0;
   }
@@ -205,9 +206,6 @@ int _d3plot_read_state_data(d3plot_file *plot_file, d3_pointer *d3_ptr) {
   }
   const uint8_t mass_N = _get_nth_digit(CDP.it, 1) == 1;
 
-  const size_t NND =
-      ((it + N + mass_N) + CDP.ndim * (CDP.iu + CDP.iv + CDP.ia)) * CDP.numnp;
-
   if (it > 0) {
     d3_buffer_skip_words(&plot_file->buffer, d3_ptr, it * CDP.numnp);
     /* TODO: read function for IT data*/
@@ -247,15 +245,15 @@ int _d3plot_read_state_data(d3plot_file *plot_file, d3_pointer *d3_ptr) {
 
   const size_t node_data_end = d3_ptr->cur_word;
   const size_t node_data_size = node_data_end - node_data_start;
-  if (node_data_size != NND) {
-    ERROR_AND_NO_RETURN_F_PTR("NODEDATA should be %zu instead of %zu", NND,
-                              node_data_size);
+  if (node_data_size != layout->node_data) {
+    ERROR_AND_NO_RETURN_F_PTR("NODEDATA should be %zu instead of %zu",
+                              layout->node_data, node_data_size);
     END_PROFILE_FUNC();
     return 0;
   }
 
   /* THERMDATA*/
-  d3_buffer_skip_words(&plot_file->buffer, d3_ptr, CDP.nt3d * CDP.nel8);
+  d3_buffer_skip_words(&plot_file->buffer, d3_ptr, layout->therm_data);
   /* TODO: read function for nt3d data*/
 
   if (plot_file->buffer.error_string) {
@@ -268,10 +266,6 @@ int _d3plot_read_state_data(d3plot_file *plot_file, d3_pointer *d3_ptr) {
   /* CFDDATA is no longer output*/
 
   /* ELEMDATA*/
-  const size_t ENN =
-      CDP.nel8 * CDP.nv3d + CDP.nelt * CDP.nv3dt + CDP.nel2 * CDP.nv1d +
-      CDP.nel4 * CDP.nv2d +
-      CDP.nmsph * 0; /* We don't support SMOOTH PARTICLE HYDRODYNAMICS*/
   const size_t elem_data_start = d3_ptr->cur_word;
 
   DT_PTR_SET(D3PLT_PTR_STATE_ELEMENT_SOLID);
@@ -297,40 +291,138 @@ int _d3plot_read_state_data(d3plot_file *plot_file, d3_pointer *d3_ptr) {
 
   const size_t elem_data_end = d3_ptr->cur_word;
   const size_t elem_data_size = elem_data_end - elem_data_start;
-  if (elem_data_size < ENN) {
-    ERROR_AND_NO_RETURN_F_PTR("ELEMDATA should be %zu instead of %zu", ENN,
-                              elem_data_size);
+  if (elem_data_size != layout->elem_data) {
+    ERROR_AND_NO_RETURN_F_PTR("ELEMDATA should be %zu instead of %zu",
+                              layout->elem_data, elem_data_size);
     END_PROFILE_FUNC();
     return 0;
   }
 
   /* Element Deletion Option*/
-  size_t skip_words;
+  if (layout->element_deletion > 0) {
+    d3_buffer_skip_words(&plot_file->buffer, d3_ptr, layout->element_deletion);
+    if (plot_file->buffer.error_string) {
+      ERROR_AND_NO_RETURN_F_PTR("Failed to skip Element Deletion Option: %s",
+                                plot_file->buffer.error_string);
+      END_PROFILE_FUNC();
+      return 0;
+    }
+  }
+
+  const size_t state_end = d3_ptr->cur_word;
+  const size_t state_size =
+      (state_end - state_start) * plot_file->buffer.word_size;
+
+  END_PROFILE_FUNC();
+  return 1;
+}
+
+int _d3plot_state_layout(d3plot_file *plot_file, d3plot_state_layout *layout) {
+  layout->global = CDP.nglbv;
+
+  /* NODEDATA*/
+  uint8_t it = _get_nth_digit(CDP.it, 0);
+  uint8_t N = it * (it > 1);
+  if (N == 2) {
+    it = 1;
+    N = 3;
+  }
+  const uint8_t mass_N = _get_nth_digit(CDP.it, 1) == 1;
+
+  layout->node_data =
+      ((it + N + mass_N) + CDP.ndim * (CDP.iu + CDP.iv + CDP.ia)) * CDP.numnp;
+
+  /* THERMDATA*/
+  layout->therm_data = CDP.nt3d * CDP.nel8;
+
+  /* ELEMDATA*/
+  layout->elem_data =
+      CDP.nel8 * CDP.nv3d + CDP.nelt * CDP.nv3dt + CDP.nel2 * CDP.nv1d +
+      CDP.nel4 * CDP.nv2d +
+      CDP.nmsph * 0; /* We don't support SMOOTH PARTICLE HYDRODYNAMICS*/
+
+  /* Element Deletion Option*/
   if (CDP.mdlopt == 0) {
-    skip_words = 0;
+    layout->element_deletion = 0;
   } else if (CDP.mdlopt == 1) {
-    skip_words = CDP.numnp;
+    layout->element_deletion = CDP.numnp;
   } else if (CDP.mdlopt == 2) {
-    skip_words = CDP.nel8 + CDP.nelt + CDP.nel4 + CDP.nel2;
+    layout->element_deletion = CDP.nel8 + CDP.nelt + CDP.nel4 + CDP.nel2;
   } else {
     ERROR_AND_NO_RETURN_F_PTR("The value of MDLOPT is invalid: %d", CDP.mdlopt);
+    return 0;
+  }
+
+  return 1;
+}
+
+size_t _d3plot_state_size(const d3plot_state_layout *layout) {
+  /* The time is one word*/
+  return 1 + layout->global + layout->node_data + layout->therm_data +
+         layout->elem_data + layout->element_deletion;
+}
+
+int _d3plot_read_states(d3plot_file *plot_file, int refresh) {
+  BEGIN_PROFILE_FUNC();
+
+  d3plot_state_layout layout;
+  if (!_d3plot_state_layout(plot_file, &layout)) {
     END_PROFILE_FUNC();
     return 0;
   }
+  const size_t state_size = _d3plot_state_size(&layout);
 
-  if (skip_words > 0) {
-    d3_buffer_skip_words(&plot_file->buffer, d3_ptr, skip_words);
+  while (1) {
+    const size_t num_words = d3_buffer_num_words(&plot_file->buffer);
+    if (plot_file->next_state_word >= num_words) {
+      break;
+    }
+
+    d3_pointer d3_ptr =
+        d3_buffer_seek(&plot_file->buffer, plot_file->next_state_word);
+    double time;
+    d3_buffer_read_double_word(&plot_file->buffer, &d3_ptr, &time);
     if (plot_file->buffer.error_string) {
-      ERROR_AND_NO_RETURN_F_PTR("Failed to skip Element Deletion Option: %s",
+      ERROR_AND_NO_RETURN_F_PTR("Failed to read time: %s",
                                 plot_file->buffer.error_string);
+      d3_pointer_close(&plot_file->buffer, &d3_ptr);
       END_PROFILE_FUNC();
       return 0;
     }
-  }
 
-  const size_t state_end = d3_ptr->cur_word;
-  const size_t state_size =
-      (state_end - state_start) * plot_file->buffer.word_size;
+    /* The EOF marker ends the file and the states continue in the next one*/
+    if (time == D3_EOF) {
+      plot_file->next_state_word =
+          d3_buffer_file_end(&plot_file->buffer, d3_ptr.cur_file);
+      d3_pointer_close(&plot_file->buffer, &d3_ptr);
+      continue;
+    }
+    d3_pointer_close(&plot_file->buffer, &d3_ptr);
+
+    /* An incomplete state will be read by the next refresh*/
+    if (plot_file->next_state_word + state_size > num_words) {
+      break;
+    }
+    /* A file which is still being written may already have been extended
+     * before all of its words have been written. So while refreshing a state
+     * is only read once the word after it (the time of the next state or the
+     * EOF marker) exists.*/
+    if (refresh && plot_file->next_state_word + state_size == num_words) {
+      break;
+    }
+
+    d3_ptr = d3_buffer_seek(&plot_file->buffer, plot_file->next_state_word);
+    const int result =
+        _d3plot_read_state_data(plot_file, &d3_ptr, &layout);
+    if (result == 0) {
+      d3_pointer_close(&plot_file->buffer, &d3_ptr);
+      END_PROFILE_FUNC();
+      return 0;
+    }
+
+    plot_file->next_state_word = d3_ptr.cur_word;
+    d3_pointer_close(&plot_file->buffer, &d3_ptr);
+  }
 
   END_PROFILE_FUNC();
   return 1;
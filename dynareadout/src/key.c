@@ -54,8 +54,25 @@ void _message_stack_push_f(string_builder_t *stack, const char *f, ...) {
   _message_stack_push(stack, buffer);
 }
 
-#define ERROR_MSG(msg) _message_stack_push(&error_stack, msg)
-#define ERROR_F(f, ...) _message_stack_push_f(&error_stack, f, __VA_ARGS__)
+void _key_parse_errors_push_f(key_parse_error_t **errors, size_t *num_errors,
+                              const char *f, ...) {
+  va_list args;
+
+  char buffer[1024];
+
+  va_start(args, f);
+  vsprintf(buffer, f, args);
+  va_end(args);
+
+  _key_parse_errors_push(errors, num_errors, KEY_ERROR_OTHER, buffer, NULL, 0,
+                         NULL);
+}
+
+#define ERROR_MSG(msg)                                                         \
+  _key_parse_errors_push(errors, num_errors, KEY_ERROR_OTHER, msg, NULL, 0,    \
+                         NULL)
+#define ERROR_F(f, ...)                                                        \
+  _key_parse_errors_push_f(errors, num_errors, f, __VA_ARGS__)
 #define ERROR_ERRNO(msg) ERROR_F(msg, strerror(errno));
 #define WARNING_MSG(msg) _message_stack_push(&warning_stack, msg)
 #define WARNING_F(f, ...) _message_stack_push_f(&warning_stack, f, __VA_ARGS__)
@@ -143,6 +160,24 @@ void key_file_parse_callback(key_parse_info_t info, const char *keyword_name,
 keyword_t *key_file_parse(const char *file_name, size_t *num_keywords,
                           const key_parse_config_t *parse_config,
                           char **error_string, char **warning_string) {
+  key_parse_error_t *errors;
+  size_t num_errors;
+
+  keyword_t *keywords = key_file_parse2(file_name, num_keywords, parse_config,
+                                        &errors, &num_errors, warning_string);
+
+  if (error_string) {
+    *error_string = _key_parse_errors_join(errors, num_errors);
+  }
+  key_free_parse_errors(errors, num_errors);
+
+  return keywords;
+}
+
+keyword_t *key_file_parse2(const char *file_name, size_t *num_keywords,
+                           const key_parse_config_t *parse_config,
+                           key_parse_error_t **errors, size_t *num_errors,
+                           char **warning_string) {
   BEGIN_PROFILE_FUNC();
 
   key_file_parse_data data;
@@ -151,24 +186,15 @@ keyword_t *key_file_parse(const char *file_name, size_t *num_keywords,
   data.num_keywords = num_keywords;
   *num_keywords = 0;
 
-  char *internal_error_string;
-
-  key_file_parse_with_callback(file_name, key_file_parse_callback, parse_config,
-                               &internal_error_string, warning_string, &data,
-                               NULL);
+  key_file_parse_with_callback2(file_name, key_file_parse_callback,
+                                parse_config, errors, num_errors,
+                                warning_string, &data);
 
   /* Deallocate the memory if an error occurred*/
-  if (internal_error_string) {
+  if (*num_errors != 0) {
     key_file_free(data.keywords, *data.num_keywords);
     data.keywords = NULL;
     *data.num_keywords = 0;
-    if (error_string) {
-      *error_string = internal_error_string;
-    } else {
-      free(internal_error_string);
-    }
-  } else if (error_string) {
-    *error_string = NULL;
   }
 
   END_PROFILE_FUNC();
@@ -177,31 +203,66 @@ keyword_t *key_file_parse(const char *file_name, size_t *num_keywords,
 
 void key_file_parse_with_callback(const char *file_name,
                                   key_file_callback callback,
-                                  const key_parse_config_t *_parse_config,
+                                  const key_parse_config_t *parse_config,
                                   char **error_string, char **warning_string,
                                   void *user_data, key_parse_recursion_t *rec) {
-  BEGIN_PROFILE_FUNC();
+  key_parse_error_t *errors = NULL;
+  size_t num_errors = 0;
+
+  _key_file_parse_with_callback(file_name, callback, parse_config, &errors,
+                                &num_errors, warning_string, user_data, rec);
 
   if (error_string) {
-    *error_string = NULL;
+    *error_string = _key_parse_errors_join(errors, num_errors);
+  }
+  key_free_parse_errors(errors, num_errors);
+}
+
+void key_file_parse_with_callback2(const char *file_name,
+                                   key_file_callback callback,
+                                   const key_parse_config_t *parse_config,
+                                   key_parse_error_t **errors,
+                                   size_t *num_errors, char **warning_string,
+                                   void *user_data) {
+  *errors = NULL;
+  *num_errors = 0;
+
+  _key_file_parse_with_callback(file_name, callback, parse_config, errors,
+                                num_errors, warning_string, user_data, NULL);
+}
+
+void key_free_parse_errors(key_parse_error_t *errors, size_t num_errors) {
+  size_t i = 0;
+  while (i < num_errors) {
+    free(errors[i].message);
+    free(errors[i].file_name);
+    free(errors[i].include_name);
+
+    i++;
   }
+
+  free(errors);
+}
+
+void _key_file_parse_with_callback(const char *file_name,
+                                   key_file_callback callback,
+                                   const key_parse_config_t *_parse_config,
+                                   key_parse_error_t **errors,
+                                   size_t *num_errors, char **warning_string,
+                                   void *user_data,
+                                   key_parse_recursion_t *rec) {
+  BEGIN_PROFILE_FUNC();
+
   if (warning_string) {
     *warning_string = NULL;
   }
 
-  /* Variables to stack multiple errors*/
-  string_builder_t error_stack = string_builder_new(),
-                   warning_stack = string_builder_new();
+  /* Variable to stack multiple warnings*/
+  string_builder_t warning_stack = string_builder_new();
 
   FILE *file = fopen(file_name, "rb");
   if (!file) {
-    if (error_string) {
-      ERROR_ERRNO("Failed to open key file: %s");
-      *error_string = string_builder_move(&error_stack);
-    }
-    if (warning_string) {
-      *warning_string = NULL;
-    }
+    ERROR_ERRNO("Failed to open key file: %s");
     END_PROFILE_FUNC();
     return;
   }
@@ -307,6 +368,11 @@ void key_file_parse_with_callback(const char *file_name,
 
     /* ------ 🔑 Keyword Parsing 🔑 --------- */
     if (is_keyword) {
+      if (parse_config.interrupt && *parse_config.interrupt) {
+        ERROR_F("%s:%zu: Parsing has been interrupted", file_name, line_count);
+        break;
+      }
+
       /* If we already read a keyword we need to call the callback if the
        * keyword had no cards*/
       if (current_keyword_length != 0 && card_index == 0) {
@@ -397,20 +463,20 @@ void key_file_parse_with_callback(const char *file_name,
                 i++;
               }
 
-              if (full_include_file_name) {
-                char *include_error, *include_warning;
-                /* Call the function recursively*/
-                key_file_parse_with_callback(
-                    full_include_file_name, callback, &parse_config,
-                    &include_error, &include_warning, user_data, rec_ptr);
+              if (parse_config.interrupt && *parse_config.interrupt) {
+                free(full_include_file_name);
+                full_include_file_name = NULL;
+                ERROR_F("%s:%zu: Parsing has been interrupted", file_name,
+                        line_count);
+              } else if (full_include_file_name) {
+                char *include_warning;
+                /* Call the function recursively. The errors of the include
+                 * file are appended to errors*/
+                _key_file_parse_with_callback(
+                    full_include_file_name, callback, &parse_config, errors,
+                    num_errors, &include_warning, user_data, rec_ptr);
                 free(full_include_file_name);
 
-                /* Add the error to the error stack if an error occurred in
-                 * the recursive call*/
-                if (include_error != NULL) {
-                  ERROR_MSG(include_error);
-                  free(include_error);
-                }
                 /* Add the warning to the warning stack if a warning occurred
                  * in the recursive call*/
                 if (include_warning != NULL) {
@@ -418,14 +484,18 @@ void key_file_parse_with_callback(const char *file_name,
                   free(include_warning);
                 }
               } else {
-                const char *format_str = "%s:%zu: \"%s\" could not be found";
+                char message[1024];
+                sprintf(message, "%s:%zu: \"%s\" could not be found",
+                        file_name, line_count,
+                        current_multi_line_string.buffer);
 
                 if (parse_config.ignore_not_found_includes) {
-                  WARNING_F(format_str, file_name, line_count,
-                            current_multi_line_string.buffer);
+                  WARNING_MSG(message);
                 } else {
-                  ERROR_F(format_str, file_name, line_count,
-                          current_multi_line_string.buffer);
+                  _key_parse_errors_push(errors, num_errors,
+                                         KEY_ERROR_INCLUDE_NOT_FOUND, message,
+                                         file_name, line_count,
+                                         current_multi_line_string.buffer);
                 }
               }
 
@@ -671,22 +741,48 @@ void key_file_parse_with_callback(const char *file_name,
   free_line_reader(line_reader);
   fclose(file);
 
-  /* Convert the error stack into an error string*/
-  if (error_stack.buffer && error_string) {
-    *error_string = string_builder_move(&error_stack);
-  }
-
   /* Convert the warning stack into a warning string*/
   if (warning_stack.buffer && warning_string) {
     *warning_string = string_builder_move(&warning_stack);
   }
 
-  string_builder_free(&error_stack);
   string_builder_free(&warning_stack);
 
   END_PROFILE_FUNC();
 }
 
+void _key_parse_errors_push(key_parse_error_t **errors, size_t *num_errors,
+                            uint8_t kind, const char *message,
+                            const char *file_name, size_t line_number,
+                            const char *include_name) {
+  *errors = realloc(*errors, (*num_errors + 1) * sizeof(key_parse_error_t));
+  key_parse_error_t *error = &(*errors)[*num_errors];
+  (*num_errors)++;
+
+  error->kind = kind;
+  error->message = string_clone(message);
+  error->file_name = file_name ? string_clone(file_name) : NULL;
+  error->line_number = line_number;
+  error->include_name = include_name ? string_clone(include_name) : NULL;
+}
+
+char *_key_parse_errors_join(const key_parse_error_t *errors,
+                             size_t num_errors) {
+  if (num_errors == 0) {
+    return NULL;
+  }
+
+  string_builder_t error_stack = string_builder_new();
+  size_t i = 0;
+  while (i < num_errors) {
+    _message_stack_push(&error_stack, errors[i].message);
+
+    i++;
+  }
+
+  return string_builder_move(&error_stack);
+}
+
 void key_file_free(keyword_t *keywords, size_t num_keywords) {
   BEGIN_PROFILE_FUNC();
 
@@ -57,6 +57,22 @@ typedef struct {
   size_t num_cards; /* The number of cards in the array*/
 } keyword_t;
 
+/* The kinds of the errors of the key file parsing*/
+#define KEY_ERROR_OTHER 0
+#define KEY_ERROR_INCLUDE_NOT_FOUND 1
+
+/* One error of the key file parsing*/
+typedef struct {
+  uint8_t kind;       /* One of the KEY_ERROR values*/
+  char *message;      /* The message of the error*/
+  char *file_name;    /* KEY_ERROR_INCLUDE_NOT_FOUND: The file containing the
+                         INCLUDE keyword. Otherwise NULL*/
+  size_t line_number; /* KEY_ERROR_INCLUDE_NOT_FOUND: The line of the include
+                         file name. Otherwise 0*/
+  char *include_name; /* KEY_ERROR_INCLUDE_NOT_FOUND: The include file name
+                         which could not be found. Otherwise NULL*/
+} key_parse_error_t;
+
 /* Contains options to configure how a key file is parsed*/
 typedef struct {
   int parse_includes; /* Wether to parse supported INCLUDE keywords and
@@ -69,6 +85,10 @@ typedef struct {
                                  keyword and such*/
   size_t num_extra_include_paths; /* The number of strings in the
                                      extra_include_paths array*/
+  const volatile int *interrupt; /* If not NULL parsing stops with an error at
+                                    the next keyword or include file as soon as
+                                    it points to a non zero value. Default:
+                                    NULL*/
 } key_parse_config_t;
 
 /* Holds all variables used for recursion*/
@@ -128,6 +148,24 @@ void key_file_parse_with_callback(const char *file_name,
                                   const key_parse_config_t *parse_config,
                                   char **error_string, char **warning_string,
                                   void *user_data, key_parse_recursion_t *rec);
+/* Same as key_file_parse, but returns every error as a key_parse_error_t
+ * instead of one string. errors is set to an array of num_errors errors, which
+ * needs to be deallocated by key_free_parse_errors.*/
+keyword_t *key_file_parse2(const char *file_name, size_t *num_keywords,
+                           const key_parse_config_t *parse_config,
+                           key_parse_error_t **errors, size_t *num_errors,
+                           char **warning_string);
+/* Same as key_file_parse_with_callback, but returns every error as a
+ * key_parse_error_t (see key_file_parse2)*/
+void key_file_parse_with_callback2(const char *file_name,
+                                   key_file_callback callback,
+                                   const key_parse_config_t *parse_config,
+                                   key_parse_error_t **errors,
+                                   size_t *num_errors, char **warning_string,
+                                   void *user_data);
+/* Deallocates the errors returned by key_file_parse2 and
+ * key_file_parse_with_callback2*/
+void key_free_parse_errors(key_parse_error_t *errors, size_t num_errors);
 /* Deallocates the data returned by key_file_parse*/
 void key_file_free(keyword_t *keywords, size_t num_keywords);
 /* Returns a certain keyword with name. If the key file contains more keywords
@@ -232,6 +270,23 @@ card_parse_type card_parse_get_type_width(const card_t *card,
                                           uint8_t value_width);
 
 /* ----- Private Functions -----*/
+/* The implementation of key_file_parse_with_callback2, which appends the
+ * errors to errors instead of overwriting them*/
+void _key_file_parse_with_callback(const char *file_name,
+                                   key_file_callback callback,
+                                   const key_parse_config_t *parse_config,
+                                   key_parse_error_t **errors,
+                                   size_t *num_errors, char **warning_string,
+                                   void *user_data, key_parse_recursion_t *rec);
+/* Appends one error to errors. file_name and include_name may be NULL*/
+void _key_parse_errors_push(key_parse_error_t **errors, size_t *num_errors,
+                            uint8_t kind, const char *message,
+                            const char *file_name, size_t line_number,
+                            const char *include_name);
+/* Returns the messages of all errors separated by new lines or NULL if there
+ * are no errors. The return value needs to be deallocated by free*/
+char *_key_parse_errors_join(const key_parse_error_t *errors,
+                             size_t num_errors);
 /* Copy the contents of the card as a string directly into dst.*/
 void _card_cpy(const card_t *card, char *dst, size_t len);
 /* Handles the parsing of multi line string for include file names. Returns
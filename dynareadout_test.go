@@ -1,9 +1,18 @@
 package dynareadout
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/csv"
 	"fmt"
 	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -110,6 +119,1206 @@ func TestBinout(t *testing.T) {
 	assert.Len(t, yDisp[0], 1)
 }
 
+func TestNodout(t *testing.T) {
+	binFile, err := BinoutOpen("test_data/binout0*")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer binFile.Close()
+
+	nodout, err := binFile.Nodout()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	ids := nodout.IDs()
+	if !assert.Len(t, ids, 1) {
+		return
+	}
+
+	index, ok := nodout.Index(ids[0])
+	assert.True(t, ok)
+	assert.Equal(t, 0, index)
+
+	time, err := nodout.Time()
+	assert.Nil(t, err)
+	assert.Len(t, time, 14998)
+
+	disp, err := nodout.Displacement(ids[0])
+	assert.Nil(t, err)
+	assert.Len(t, disp, 14998)
+
+	_, err = nodout.Displacement(ids[0] + 1)
+	assert.NotNil(t, err)
+}
+
+func TestRcforc(t *testing.T) {
+	binFile, err := BinoutOpen("test_data/binout0*")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer binFile.Close()
+
+	rcforc, err := binFile.Rcforc()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	interfaceIDs := rcforc.InterfaceIDs()
+	if !assert.NotEmpty(t, interfaceIDs) {
+		return
+	}
+
+	for _, id := range interfaceIDs {
+		for _, side := range []int{RcforcSideSlave, RcforcSideMaster} {
+			if !rcforc.HasSide(id, side) {
+				continue
+			}
+
+			force, err := rcforc.Force(id, side)
+			assert.Nil(t, err)
+			resultant, err := rcforc.ResultantForce(id, side)
+			assert.Nil(t, err)
+			assert.Len(t, resultant, len(force))
+		}
+	}
+}
+
+func TestBndoutSleout(t *testing.T) {
+	binFile, err := BinoutOpen("test_data/binout0*")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer binFile.Close()
+
+	databases := binFile.BndoutDatabases()
+	if !assert.NotEmpty(t, databases) {
+		return
+	}
+
+	bndout, err := binFile.Bndout(databases[0])
+	if assert.Nil(t, err) {
+		time, err := bndout.Time()
+		assert.Nil(t, err)
+		assert.NotEmpty(t, time)
+	}
+
+	sleout, err := binFile.Sleout()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.NotEmpty(t, sleout.IDs())
+}
+
+func TestBinoutExportCSV(t *testing.T) {
+	binFile, err := BinoutOpen("test_data/binout0*")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer binFile.Close()
+
+	var buffer bytes.Buffer
+	err = binFile.ExportCSV(&buffer, "nodout/x_displacement", "nodout/y_displacement")
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	records, err := csv.NewReader(&buffer).ReadAll()
+	if !assert.Nil(t, err) {
+		return
+	}
+	if !assert.Len(t, records, 14999) {
+		return
+	}
+	assert.Len(t, records[0], 3)
+	assert.Equal(t, "time", records[0][0])
+
+	err = binFile.ExportCSV(&buffer, "nodout/x_displacement", "glstat/kinetic_energy")
+	assert.NotNil(t, err)
+}
+
+func TestBinoutExportCSVChangingIDs(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "binout")
+	writer, err := BinoutCreate(fileName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Nil(t, writer.Cd("/nodout/metadata"))
+	assert.Nil(t, writer.WriteInt32("ids", []int32{1, 2}))
+	for step, values := range [][]float32{{1, 2}, {3, 4}, {5, 6, 7}} {
+		_, err = writer.BeginTimestep("/nodout", float64(step))
+		assert.Nil(t, err)
+		assert.Nil(t, writer.WriteFloat32("x_displacement", values))
+	}
+	if !assert.Nil(t, writer.Close()) {
+		return
+	}
+
+	binFile, err := BinoutOpen(fileName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer binFile.Close()
+
+	// A node has been added in the last timestep
+	var buffer bytes.Buffer
+	err = binFile.ExportCSV(&buffer, "nodout/x_displacement")
+	assert.ErrorContains(t, err, "\"/nodout/d000003/x_displacement\" contains 3 values instead of 2")
+}
+
+func TestBinoutWriter(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "binout")
+
+	writer, err := BinoutCreate(fileName)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Nil(t, writer.Cd("/nodout/metadata"))
+	assert.Nil(t, writer.WriteInt32("ids", []int32{10, 20}))
+	assert.Nil(t, writer.WriteString("title", "Writer Test"))
+	assert.Nil(t, writer.Cd("/"))
+	assert.NotNil(t, writer.WriteFloat64("time", []float64{0.0}))
+
+	for i := 0; i < 3; i++ {
+		folder, err := writer.BeginTimestep("nodout", float64(i)*0.5)
+		assert.Nil(t, err)
+		assert.Equal(t, fmt.Sprintf("/nodout/d%06d", i+1), folder)
+		assert.Nil(t, writer.WriteFloat32("x_displacement", []float32{float32(i), float32(2 * i)}))
+	}
+
+	assert.Nil(t, writer.Cd("../../glstat/metadata"))
+	assert.Equal(t, "/glstat/metadata", writer.Path())
+	assert.Nil(t, writer.WriteUint64("ids", []uint64{1}))
+	assert.Nil(t, writer.Close())
+	assert.NotNil(t, writer.Close())
+
+	binFile, err := BinoutOpen(fileName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer binFile.Close()
+
+	assert.Equal(t, []string{"glstat", "nodout"}, binFile.GetChildren("/"))
+
+	numTimesteps, err := binFile.GetNumTimesteps("/nodout")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), numTimesteps)
+
+	title, err := binFile.ReadString("/nodout/metadata/title")
+	assert.Nil(t, err)
+	assert.Equal(t, "Writer Test", title)
+
+	nodout, err := binFile.Nodout()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, []int64{10, 20}, nodout.IDs())
+
+	time, err := nodout.Time()
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{0.0, 0.5, 1.0}, time)
+
+	x, err := nodout.ReadID("x_displacement", 20)
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{0.0, 2.0, 4.0}, x)
+
+	ids, err := binFile.ReadUint64("/glstat/metadata/ids")
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{1}, ids)
+
+	// The symbol table of the default layout: 8 byte length, 1 byte command,
+	// 1 byte type id and 8 byte offset
+	data, err := os.ReadFile(fileName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	type record struct {
+		offset  uint64
+		command byte
+		data    []byte
+	}
+	var records []record
+	for offset := uint64(8); offset < uint64(len(data)); {
+		length := binary.LittleEndian.Uint64(data[offset:])
+		records = append(records, record{offset, data[offset+8], data[offset+9 : offset+length]})
+		offset += length
+	}
+	if !assert.Greater(t, len(records), 2) {
+		return
+	}
+
+	assert.Equal(t, byte(7), records[0].command)
+	tableOffset := binary.LittleEndian.Uint64(records[0].data)
+	variables := make(map[string]record)
+	folder := ""
+	inTable := false
+	for _, rec := range records {
+		switch {
+		case rec.offset == tableOffset:
+			assert.Equal(t, byte(5), rec.command)
+			inTable = true
+		case !inTable:
+		case rec.command == 2:
+			folder = string(rec.data)
+		case rec.command == 4:
+			name := string(rec.data[1 : 1+rec.data[0]])
+			variables[folder+"/"+name] = rec
+		default:
+			assert.Equal(t, byte(6), rec.command)
+			assert.Equal(t, make([]byte, 8), rec.data)
+		}
+	}
+	assert.Equal(t, records[len(records)-1].command, byte(6))
+	assert.Len(t, variables, 9)
+
+	if variable, ok := variables["/nodout/d000002/x_displacement"]; assert.True(t, ok) {
+		fields := variable.data[1+len("x_displacement"):]
+		assert.Equal(t, byte(BinoutTypeFloat32), fields[0])
+		assert.Equal(t, uint64(2), binary.LittleEndian.Uint64(fields[9:]))
+
+		// The offset points to the DATA record of the variable
+		dataOffset := binary.LittleEndian.Uint64(fields[1:])
+		found := false
+		for _, rec := range records {
+			if rec.offset == dataOffset {
+				found = true
+				assert.Equal(t, byte(3), rec.command)
+				assert.Equal(t, "x_displacement", string(rec.data[2:2+rec.data[1]]))
+			}
+		}
+		assert.True(t, found)
+	}
+}
+
+func TestBinoutExtract(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "binout")
+	if !writeTestBinout(t, fileName) {
+		return
+	}
+
+	binFile, err := BinoutOpen(fileName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer binFile.Close()
+
+	extractName := filepath.Join(dir, "extract")
+	err = binFile.ExtractFile(extractName,
+		BinoutSelection{Path: "/nodout", IDs: []int64{30, 10}},
+		BinoutSelection{Path: "glstat/kinetic_energy"},
+	)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	extract, err := BinoutOpen(extractName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer extract.Close()
+
+	nodout, err := extract.Nodout()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, []int64{10, 30}, nodout.IDs())
+	assert.Equal(t, "Node 30", nodout.Title(30))
+	assert.Equal(t, "", nodout.Title(20))
+
+	x, err := nodout.ReadVariable("x_displacement")
+	assert.Nil(t, err)
+	assert.Equal(t, [][]float64{{0.0, 0.0}, {1.0, 3.0}, {2.0, 6.0}}, x)
+
+	numTimesteps, err := extract.GetNumTimesteps("/glstat")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), numTimesteps)
+	assert.True(t, extract.VariableExists("/glstat/d000002/kinetic_energy"))
+	assert.False(t, extract.VariableExists("/glstat/d000002/internal_energy"))
+
+	err = binFile.ExtractFile(extractName, BinoutSelection{Path: "/nodout", IDs: []int64{40}})
+	assert.NotNil(t, err)
+
+	// 4 values can not be distributed onto 3 ids
+	mismatchName := filepath.Join(dir, "mismatch")
+	file, err := os.Create(mismatchName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer file.Close()
+	writer, err := NewBinoutWriter(file)
+	if !assert.Nil(t, err) {
+		return
+	}
+	writeTestDatabase(t, writer, "/nodout", []int32{10, 20, 30}, 1)
+	assert.Nil(t, writer.WriteFloat32("x_displacement", []float32{1.0, 2.0, 3.0, 4.0}))
+	if !assert.Nil(t, writer.Close()) {
+		return
+	}
+
+	mismatch, err := BinoutOpen(mismatchName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer mismatch.Close()
+
+	err = mismatch.ExtractFile(extractName, BinoutSelection{Path: "/nodout/x_displacement", IDs: []int64{20}})
+	assert.ErrorContains(t, err, "x_displacement")
+}
+
+// writeTestBinout writes a small binout with nodout and glstat
+func writeTestBinout(t *testing.T, fileName string) bool {
+	return writeTestBinoutWithOptions(t, fileName, DefaultBinoutWriterOptions())
+}
+
+func writeTestBinoutWithOptions(t *testing.T, fileName string, options BinoutWriterOptions) bool {
+	file, err := os.Create(fileName)
+	if !assert.Nil(t, err) {
+		return false
+	}
+	defer file.Close()
+
+	writer, err := NewBinoutWriterWithOptions(file, options)
+	if !assert.Nil(t, err) {
+		return false
+	}
+
+	assert.Nil(t, writer.Cd("/nodout/metadata"))
+	assert.Nil(t, writer.WriteInt32("ids", []int32{10, 20, 30}))
+	assert.Nil(t, writer.WriteInt32("legend_ids", []int32{10, 20, 30}))
+	assert.Nil(t, writer.WriteString("legend", fmt.Sprintf("%-80s%-80s%-80s", "Node 10", "Node 20", "Node 30")))
+	assert.Nil(t, writer.Cd("/glstat/metadata"))
+	assert.Nil(t, writer.WriteString("title", "Test"))
+
+	for i := 0; i < 3; i++ {
+		time := float64(i) * 0.5
+		_, err = writer.BeginTimestep("/nodout", time)
+		assert.Nil(t, err)
+		assert.Nil(t, writer.WriteFloat32("x_displacement", []float32{float32(i), float32(2 * i), float32(3 * i)}))
+
+		_, err = writer.BeginTimestep("/glstat", time)
+		assert.Nil(t, err)
+		assert.Nil(t, writer.WriteFloat64("kinetic_energy", []float64{time * 10.0}))
+		assert.Nil(t, writer.WriteFloat64("internal_energy", []float64{time * 5.0}))
+	}
+
+	return assert.Nil(t, writer.Close())
+}
+
+// writeTestDatabase writes ids and three timesteps of variables into the
+// database at path. Every id has pointsPerID values per variable and the value
+// is testDatabaseValue of the timestep, the variable and the value index.
+func writeTestDatabase(t *testing.T, writer *BinoutWriter, path string, ids []int32, pointsPerID int, variables ...string) {
+	assert.Nil(t, writer.Cd(path+"/metadata"))
+	assert.Nil(t, writer.WriteInt32("ids", ids))
+
+	for step := 0; step < 3; step++ {
+		_, err := writer.BeginTimestep(path, float64(step))
+		assert.Nil(t, err)
+		for v, variable := range variables {
+			values := make([]float32, len(ids)*pointsPerID)
+			for i := range values {
+				values[i] = float32(testDatabaseValue(step, v, i))
+			}
+			assert.Nil(t, writer.WriteFloat32(variable, values))
+		}
+	}
+}
+
+func testDatabaseValue(step, variable, index int) float64 {
+	return float64(step*1000 + variable*100 + index)
+}
+
+// testDatabaseVector returns the values of writeTestDatabase of the variables
+// x, y and z at index over all timesteps
+func testDatabaseVector(x, index int) [][3]float64 {
+	vec := make([][3]float64, 3)
+	for step := range vec {
+		for c := range vec[step] {
+			vec[step][c] = testDatabaseValue(step, x+c, index)
+		}
+	}
+	return vec
+}
+
+func testDatabaseColumn(variable, index int) []float64 {
+	column := make([]float64, 3)
+	for step := range column {
+		column[step] = testDatabaseValue(step, variable, index)
+	}
+	return column
+}
+
+func TestElout(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "binout")
+	writer, err := BinoutCreate(fileName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	// Two integration points per solid
+	writeTestDatabase(t, writer, "/elout/solid", []int32{1, 2}, 2, "sig_xx", "sig_yy", "sig_zz", "sig_xy", "sig_yz", "sig_zx")
+	writeTestDatabase(t, writer, "/elout/beam", []int32{7}, 1, "axial", "shear_s", "shear_t", "moment_s", "moment_t", "torsion")
+	if !assert.Nil(t, writer.Close()) {
+		return
+	}
+
+	binFile, err := BinoutOpen(fileName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer binFile.Close()
+
+	assert.Equal(t, []string{EloutBeam, EloutSolid}, binFile.EloutElementTypes())
+
+	solids, err := binFile.Elout(EloutSolid)
+	if assert.Nil(t, err) {
+		assert.Equal(t, []int64{1, 2}, solids.IDs())
+
+		stress, err := solids.Stress(2)
+		if assert.Nil(t, err) && assert.Len(t, stress, 3) {
+			assert.Len(t, stress[2], 2)
+			assert.Equal(t, [6]float64{2002, 2102, 2202, 2302, 2402, 2502}, stress[2][0])
+			assert.Equal(t, [6]float64{2003, 2103, 2203, 2303, 2403, 2503}, stress[2][1])
+		}
+
+		sigYY, err := solids.Read("sig_yy", 1)
+		assert.Nil(t, err)
+		assert.Equal(t, [][]float64{{100, 101}, {1100, 1101}, {2100, 2101}}, sigYY)
+
+		_, err = solids.Strain(1)
+		assert.ErrorIs(t, err, ErrPathNotFound)
+		_, err = solids.Stress(3)
+		assert.NotNil(t, err)
+	}
+
+	beams, err := binFile.Elout(EloutBeam)
+	if assert.Nil(t, err) {
+		resultants, err := beams.BeamResultants(7)
+		assert.Nil(t, err)
+		assert.Equal(t, [][6]float64{
+			{0, 100, 200, 300, 400, 500},
+			{1000, 1100, 1200, 1300, 1400, 1500},
+			{2000, 2100, 2200, 2300, 2400, 2500},
+		}, resultants)
+	}
+
+	_, err = binFile.Elout(EloutShell)
+	assert.ErrorIs(t, err, ErrPathNotFound)
+}
+
+func TestEloutTruncatedBeam(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "binout")
+	writer, err := BinoutCreate(fileName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	writeTestDatabase(t, writer, "/elout/beam", []int32{7}, 1, "axial", "shear_s", "shear_t", "moment_s", "moment_t", "torsion")
+	// The last timestep has only been written partially
+	_, err = writer.BeginTimestep("/elout/beam", 3.0)
+	assert.Nil(t, err)
+	assert.Nil(t, writer.WriteFloat32("axial", []float32{3000}))
+	if !assert.Nil(t, writer.Close()) {
+		return
+	}
+
+	binFile, err := BinoutOpen(fileName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer binFile.Close()
+
+	beams, err := binFile.Elout(EloutBeam)
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, err = beams.BeamResultants(7)
+	assert.NotNil(t, err)
+
+	// The columns could also differ if the family grows between the reads
+	variables := [6]string{"axial", "shear_s", "shear_t", "moment_s", "moment_t", "torsion"}
+	components := [6][]float64{{1, 2}, {1, 2}, {1, 2}, {1, 2}, {1, 2}, {1, 2, 3}}
+	_, err = beams.combineResultants(variables, components)
+	assert.ErrorContains(t, err, "\"/elout/beam/torsion\" contains 3 timesteps instead of 2")
+	components[5] = components[5][:2]
+	resultants, err := beams.combineResultants(variables, components)
+	assert.Nil(t, err)
+	assert.Equal(t, [][6]float64{{1, 1, 1, 1, 1, 1}, {2, 2, 2, 2, 2, 2}}, resultants)
+}
+
+func TestForcDatabases(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "binout")
+	writer, err := BinoutCreate(fileName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	writeTestDatabase(t, writer, "/deforc", []int32{5, 6}, 1, "x_force", "y_force", "z_force", "resultant_force", "displacement")
+	// Newer versions of LS-Dyna write the joints into a sub folder
+	writeTestDatabase(t, writer, "/jntforc/joints", []int32{3}, 1, "x_force", "y_force", "z_force", "x_moment", "y_moment", "z_moment", "resultant_force", "resultant_moment")
+	writeTestDatabase(t, writer, "/secforc", []int32{8, 9}, 1, "x_force", "y_force", "z_force", "x_moment", "y_moment", "z_moment", "x_centroid", "y_centroid", "z_centroid", "area")
+	if !assert.Nil(t, writer.Close()) {
+		return
+	}
+
+	binFile, err := BinoutOpen(fileName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer binFile.Close()
+
+	deforc, err := binFile.Deforc()
+	if assert.Nil(t, err) {
+		force, err := deforc.Force(6)
+		assert.Nil(t, err)
+		assert.Equal(t, testDatabaseVector(0, 1), force)
+		resultant, err := deforc.ResultantForce(5)
+		assert.Nil(t, err)
+		assert.Equal(t, testDatabaseColumn(3, 0), resultant)
+		displacement, err := deforc.Displacement(6)
+		assert.Nil(t, err)
+		assert.Equal(t, testDatabaseColumn(4, 1), displacement)
+
+		_, err = deforc.Force(7)
+		assert.NotNil(t, err)
+	}
+
+	jntforc, err := binFile.Jntforc()
+	if assert.Nil(t, err) {
+		assert.Equal(t, "/jntforc/joints", jntforc.Path())
+		force, err := jntforc.Force(3)
+		assert.Nil(t, err)
+		assert.Equal(t, testDatabaseVector(0, 0), force)
+		moment, err := jntforc.Moment(3)
+		assert.Nil(t, err)
+		assert.Equal(t, testDatabaseVector(3, 0), moment)
+		resultantForce, err := jntforc.ResultantForce(3)
+		assert.Nil(t, err)
+		assert.Equal(t, testDatabaseColumn(6, 0), resultantForce)
+		resultantMoment, err := jntforc.ResultantMoment(3)
+		assert.Nil(t, err)
+		assert.Equal(t, testDatabaseColumn(7, 0), resultantMoment)
+	}
+
+	secforc, err := binFile.Secforc()
+	if assert.Nil(t, err) {
+		force, err := secforc.Force(9)
+		assert.Nil(t, err)
+		assert.Equal(t, testDatabaseVector(0, 1), force)
+		moment, err := secforc.Moment(9)
+		assert.Nil(t, err)
+		assert.Equal(t, testDatabaseVector(3, 1), moment)
+		centroid, err := secforc.Centroid(8)
+		assert.Nil(t, err)
+		assert.Equal(t, testDatabaseVector(6, 0), centroid)
+		area, err := secforc.Area(8)
+		assert.Nil(t, err)
+		assert.Equal(t, testDatabaseColumn(9, 0), area)
+
+		resultant, err := secforc.ResultantForce(9)
+		if assert.Nil(t, err) && assert.Len(t, resultant, 3) {
+			f := testDatabaseVector(0, 1)[2]
+			assert.InDelta(t, math.Sqrt(f[0]*f[0]+f[1]*f[1]+f[2]*f[2]), resultant[2], 1e-9)
+		}
+	}
+}
+
+func TestRcforcSides(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "binout")
+	writer, err := BinoutCreate(fileName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Nil(t, writer.Cd("/rcforc/metadata"))
+	assert.Nil(t, writer.WriteInt32("side", []int32{RcforcSideSlave, RcforcSideMaster}))
+	writeTestDatabase(t, writer, "/rcforc", []int32{4, 4}, 1, "x_force", "y_force", "z_force", "mass")
+	if !assert.Nil(t, writer.Close()) {
+		return
+	}
+
+	binFile, err := BinoutOpen(fileName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer binFile.Close()
+
+	rcforc, err := binFile.Rcforc()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, []int64{4}, rcforc.InterfaceIDs())
+
+	index, ok := rcforc.Index(4, RcforcSideSlave)
+	assert.True(t, ok)
+	assert.Equal(t, 0, index)
+	index, ok = rcforc.Index(4, RcforcSideMaster)
+	assert.True(t, ok)
+	assert.Equal(t, 1, index)
+	_, ok = rcforc.Index(5, RcforcSideSlave)
+	assert.False(t, ok)
+
+	mass, err := rcforc.ReadID("mass", 4, RcforcSideMaster)
+	assert.Nil(t, err)
+	assert.Equal(t, testDatabaseColumn(3, 1), mass)
+	force, err := rcforc.Force(4, RcforcSideSlave)
+	assert.Nil(t, err)
+	assert.Equal(t, testDatabaseVector(0, 0), force)
+
+	_, err = rcforc.ReadID("mass", 5, RcforcSideMaster)
+	assert.NotNil(t, err)
+}
+
+func TestEnergyDatabases(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "binout")
+	writer, err := BinoutCreate(fileName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	writeTestDatabase(t, writer, "/matsum", []int32{1, 2}, 1,
+		"kinetic_energy", "internal_energy", "hourglass_energy", "mass", "added_mass",
+		"x_momentum", "y_momentum", "z_momentum", "x_rbvelocity", "y_rbvelocity", "z_rbvelocity")
+	writeTestDatabase(t, writer, "/rbdout", []int32{4}, 1,
+		"global_x", "global_y", "global_z", "global_dx", "global_dy", "global_dz",
+		"global_vx", "global_vy", "global_vz", "global_ax", "global_ay", "global_az",
+		"global_rdx", "global_rdy", "global_rdz", "global_rvx", "global_rvy", "global_rvz",
+		"global_rax", "global_ray", "global_raz")
+	writeTestDatabase(t, writer, "/spcforc", []int32{10, 20, 30}, 1, "x_force", "y_force", "z_force", "x_moment", "y_moment", "z_moment")
+	if !assert.Nil(t, writer.Close()) {
+		return
+	}
+
+	binFile, err := BinoutOpen(fileName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer binFile.Close()
+
+	matsum, err := binFile.Matsum()
+	if assert.Nil(t, err) {
+		assert.Equal(t, []int64{1, 2}, matsum.IDs())
+
+		columns := []func(int64) ([]float64, error){
+			matsum.KineticEnergy,
+			matsum.InternalEnergy,
+			matsum.HourglassEnergy,
+			matsum.Mass,
+			matsum.AddedMass,
+		}
+		for v, read := range columns {
+			column, err := read(2)
+			assert.Nil(t, err)
+			assert.Equal(t, testDatabaseColumn(v, 1), column)
+		}
+
+		momentum, err := matsum.Momentum(1)
+		assert.Nil(t, err)
+		assert.Equal(t, testDatabaseVector(5, 0), momentum)
+		velocity, err := matsum.RigidBodyVelocity(2)
+		assert.Nil(t, err)
+		assert.Equal(t, testDatabaseVector(8, 1), velocity)
+
+		_, err = matsum.Mass(3)
+		assert.NotNil(t, err)
+	}
+
+	rbdout, err := binFile.Rbdout()
+	if assert.Nil(t, err) {
+		vectors := []func(int64) ([][3]float64, error){
+			rbdout.Coordinates,
+			rbdout.Displacement,
+			rbdout.Velocity,
+			rbdout.Acceleration,
+			rbdout.RotationalDisplacement,
+			rbdout.RotationalVelocity,
+			rbdout.RotationalAcceleration,
+		}
+		for v, read := range vectors {
+			vec, err := read(4)
+			assert.Nil(t, err)
+			assert.Equal(t, testDatabaseVector(3*v, 0), vec)
+		}
+	}
+
+	spcforc, err := binFile.Spcforc()
+	if assert.Nil(t, err) {
+		force, err := spcforc.Force(30)
+		assert.Nil(t, err)
+		assert.Equal(t, testDatabaseVector(0, 2), force)
+		moment, err := spcforc.Moment(20)
+		assert.Nil(t, err)
+		assert.Equal(t, testDatabaseVector(3, 1), moment)
+	}
+
+	_, err = binFile.Sleout()
+	assert.ErrorIs(t, err, ErrPathNotFound)
+}
+
+func TestCompareBinouts(t *testing.T) {
+	dir := t.TempDir()
+	fileNameA, fileNameB := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	if !writeTestBinout(t, fileNameA) {
+		return
+	}
+
+	writer, err := BinoutCreate(fileNameB)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Nil(t, writer.Cd("/nodout/metadata"))
+	assert.Nil(t, writer.WriteInt64("ids", []int64{10, 20, 30}))
+	assert.Nil(t, writer.WriteInt32("legend_ids", []int32{10, 20, 30}))
+	assert.Nil(t, writer.WriteString("legend", fmt.Sprintf("%-80s%-80s%-80s", "Node 10", "Node 20", "Node 30")))
+	assert.Nil(t, writer.Cd("/matsum/metadata"))
+	assert.Nil(t, writer.WriteInt32("ids", []int32{1}))
+	for i := 0; i < 3; i++ {
+		_, err = writer.BeginTimestep("/nodout", float64(i)*0.5)
+		assert.Nil(t, err)
+		x := []float32{float32(i), float32(2 * i), float32(3 * i)}
+		x[2] *= 1.001
+		assert.Nil(t, writer.WriteFloat32("x_displacement", x))
+	}
+	for i := 0; i < 2; i++ {
+		_, err = writer.BeginTimestep("/glstat", float64(i)*0.5)
+		assert.Nil(t, err)
+		assert.Nil(t, writer.WriteFloat64("kinetic_energy", []float64{float64(i) * 5.0}))
+		assert.Nil(t, writer.WriteFloat64("internal_energy", []float64{float64(i) * 2.5}))
+	}
+	if !assert.Nil(t, writer.Close()) {
+		return
+	}
+
+	a, err := BinoutOpen(fileNameA)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer a.Close()
+	b, err := BinoutOpen(fileNameB)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer b.Close()
+
+	report, err := CompareBinouts(a, a, BinoutCompareOptions{})
+	assert.Nil(t, err)
+	assert.True(t, report.Equal())
+	assert.Equal(t, 9, report.NumCompared)
+
+	report, err = CompareBinouts(a, b, BinoutCompareOptions{})
+	if !assert.Nil(t, err) {
+		return
+	}
+	if !assert.Len(t, report.Differences, 5, report.String()) {
+		return
+	}
+
+	diffs := report.Differences
+	assert.Equal(t, BinoutDifference{Kind: BinoutDiffTimesteps, Path: "/glstat", TimestepsA: 3, TimestepsB: 2}, diffs[0])
+	assert.Equal(t, BinoutDiffMissing, diffs[1].Kind)
+	assert.Equal(t, "/glstat/metadata", diffs[1].Path)
+	assert.Equal(t, BinoutDifference{Kind: BinoutDiffExtra, Path: "/matsum"}, diffs[2])
+	assert.Equal(t, BinoutDifference{Kind: BinoutDiffType, Path: "/nodout/metadata/ids", TypeA: BinoutTypeInt32, TypeB: BinoutTypeInt64}, diffs[3])
+	assert.Equal(t, BinoutDiffValues, diffs[4].Kind)
+	assert.Equal(t, "/nodout/x_displacement", diffs[4].Path)
+	assert.Equal(t, 2, diffs[4].NumExceeded)
+	assert.Equal(t, 2, diffs[4].Timestep)
+	assert.Equal(t, 2, diffs[4].Index)
+
+	report, err = CompareBinouts(a, b, BinoutCompareOptions{RelativeTolerance: 0.01})
+	assert.Nil(t, err)
+	for _, diff := range report.Differences {
+		assert.NotEqual(t, "/nodout/x_displacement", diff.Path)
+	}
+}
+
+func TestBinoutOpenError(t *testing.T) {
+	// ": " inside of the path must not confuse the file errors
+	dir := filepath.Join(t.TempDir(), "a: b")
+	if !assert.Nil(t, os.Mkdir(dir, 0o755)) {
+		return
+	}
+	if !writeTestBinout(t, filepath.Join(dir, "binout0000")) {
+		return
+	}
+
+	// Invalid endianess
+	err := os.WriteFile(filepath.Join(dir, "binout0001"), []byte{8, 8, 8, 1, 1, 2, 0, 0}, 0o644)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	truncatedName := filepath.Join(dir, "binout0002")
+	writer, err := BinoutCreate(truncatedName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Nil(t, writer.Cd("/rcforc/metadata"))
+	assert.Nil(t, writer.WriteInt32("ids", []int32{1, 2}))
+	assert.Nil(t, writer.WriteFloat64("truncated", []float64{1.0, 2.0}))
+	assert.Nil(t, writer.Close())
+	// Cut off the symbol table and the end of the last DATA record
+	truncated, err := os.ReadFile(truncatedName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	symbolTableOffset := binary.LittleEndian.Uint64(truncated[17:25])
+	assert.Nil(t, os.Truncate(truncatedName, int64(symbolTableOffset)-3))
+
+	binFile, err := BinoutOpen(filepath.Join(dir, "binout*"))
+	defer binFile.Close()
+
+	var openErr *BinoutOpenError
+	if !assert.ErrorAs(t, err, &openErr) {
+		return
+	}
+	assert.False(t, openErr.Fatal)
+	assert.Equal(t, []BinoutFileError{
+		{filepath.Join(dir, "binout0001"), BinoutFileErrorHeader, "Unsupported Endianess"},
+		{truncatedName, BinoutFileErrorTruncated, "The DATA record is truncated"},
+	}, openErr.FileErrors)
+
+	var fileErr BinoutFileError
+	assert.ErrorAs(t, err, &fileErr)
+
+	assert.Equal(t, []string{filepath.Join(dir, "binout0000"), truncatedName}, binFile.FileNames())
+
+	ids, err := binFile.ReadInt32("/rcforc/metadata/ids")
+	assert.Nil(t, err)
+	assert.Equal(t, []int32{1, 2}, ids)
+	assert.False(t, binFile.VariableExists("/rcforc/metadata/truncated"))
+
+	nodout, err := binFile.Nodout()
+	if assert.Nil(t, err) {
+		assert.Equal(t, []int64{10, 20, 30}, nodout.IDs())
+	}
+
+	_, err = BinoutOpen(filepath.Join(dir, "does_not_exist*"))
+	if assert.ErrorAs(t, err, &openErr) {
+		assert.True(t, openErr.Fatal)
+		assert.Len(t, openErr.FileErrors, 1)
+	}
+}
+
+func TestBinoutRefresh(t *testing.T) {
+	dir := t.TempDir()
+	fullName := filepath.Join(dir, "full")
+	if !writeTestBinout(t, fullName) {
+		return
+	}
+	full, err := os.ReadFile(fullName)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	// The file as it has been written after the first timestep. 9 bytes are the
+	// length and the command of the CD record.
+	firstTimestep := bytes.Index(full, []byte("/nodout/d000002")) - 9
+	if !assert.Greater(t, firstTimestep, 0) {
+		return
+	}
+
+	fileName := filepath.Join(dir, "binout0000")
+	assert.Nil(t, os.WriteFile(fileName, full[:firstTimestep], 0o644))
+
+	binFile, err := BinoutOpen(filepath.Join(dir, "binout*"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	// Refresh changes binFile, so the deferred call must not copy it now
+	defer func() { binFile.Close() }()
+
+	numTimesteps, err := binFile.GetNumTimesteps("/nodout")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), numTimesteps)
+
+	// A partially written record is not an error
+	assert.Nil(t, os.WriteFile(fileName, full[:firstTimestep+12], 0o644))
+	assert.Nil(t, binFile.Refresh())
+	numTimesteps, err = binFile.GetNumTimesteps("/nodout")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), numTimesteps)
+
+	assert.Nil(t, os.WriteFile(fileName, full, 0o644))
+	assert.Nil(t, binFile.Refresh())
+	numTimesteps, err = binFile.GetNumTimesteps("/nodout")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), numTimesteps)
+
+	nodout, err := binFile.Nodout()
+	if assert.Nil(t, err) {
+		x, err := nodout.ReadVariable("x_displacement")
+		assert.Nil(t, err)
+		assert.Equal(t, [][]float64{{0.0, 0.0, 0.0}, {1.0, 2.0, 3.0}, {2.0, 4.0, 6.0}}, x)
+	}
+
+	// A new file of the family whose header has not been written completely is
+	// ignored until the next refresh
+	newName := filepath.Join(dir, "binout0001")
+	assert.Nil(t, os.WriteFile(newName, []byte{8, 8, 8}, 0o644))
+	assert.Nil(t, binFile.Refresh())
+	assert.Equal(t, []string{fileName}, binFile.FileNames())
+
+	writer, err := BinoutCreate(newName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Nil(t, writer.Cd("/rcforc/metadata"))
+	assert.Nil(t, writer.WriteInt32("ids", []int32{1, 2}))
+	assert.Nil(t, writer.Close())
+
+	assert.Nil(t, binFile.Refresh())
+	assert.Equal(t, []string{fileName, newName}, binFile.FileNames())
+	ids, err := binFile.ReadInt32("/rcforc/metadata/ids")
+	assert.Nil(t, err)
+	assert.Equal(t, []int32{1, 2}, ids)
+}
+
+func TestBinoutStat(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "binout")
+	if !writeTestBinout(t, fileName) {
+		return
+	}
+
+	binFile, err := BinoutOpen(fileName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer binFile.Close()
+
+	files, err := binFile.Files()
+	if !assert.Nil(t, err) || !assert.Len(t, files, 1) {
+		return
+	}
+	assert.Equal(t, fileName, files[0].FileName)
+	assert.Equal(t, uint8(8), files[0].HeaderSize)
+	assert.Equal(t, uint8(8), files[0].RecordLengthFieldSize)
+	assert.Equal(t, uint8(1), files[0].RecordCommandFieldSize)
+	assert.Equal(t, uint8(1), files[0].RecordTypeIDFieldSize)
+	assert.False(t, files[0].BigEndian)
+
+	stat, err := binFile.Stat("nodout/d000002/x_displacement")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "/nodout/d000002/x_displacement", stat.Path)
+	assert.Equal(t, uint64(BinoutTypeFloat32), stat.TypeID)
+	assert.Equal(t, uint64(12), stat.Size)
+	assert.Equal(t, uint64(3), stat.NumElements)
+	assert.Equal(t, 0, stat.FileIndex)
+	assert.Equal(t, fileName, stat.FileName)
+	assert.Greater(t, stat.Offset, int64(8))
+	assert.Less(t, stat.Offset+int64(stat.Size), files[0].Size+1)
+
+	_, err = binFile.Stat("/nodout/d000002")
+	assert.NotNil(t, err)
+	_, err = binFile.Stat("/nodout/d000004/x_displacement")
+	assert.NotNil(t, err)
+}
+
+func TestBinoutByteOrder(t *testing.T) {
+	dir := t.TempDir()
+
+	layouts := map[string]BinoutWriterOptions{
+		"little_endian": DefaultBinoutWriterOptions(),
+		"big_endian": {
+			ByteOrder:              binary.BigEndian,
+			RecordLengthFieldSize:  8,
+			RecordOffsetFieldSize:  8,
+			RecordCommandFieldSize: 1,
+			RecordTypeIDFieldSize:  1,
+		},
+		"big_endian_small_fields": {
+			ByteOrder:              binary.BigEndian,
+			RecordLengthFieldSize:  4,
+			RecordOffsetFieldSize:  4,
+			RecordCommandFieldSize: 2,
+			RecordTypeIDFieldSize:  4,
+		},
+		"little_endian_small_fields": {
+			ByteOrder:              binary.LittleEndian,
+			RecordLengthFieldSize:  3,
+			RecordOffsetFieldSize:  8,
+			RecordCommandFieldSize: 4,
+			RecordTypeIDFieldSize:  2,
+		},
+	}
+
+	for name, options := range layouts {
+		t.Run(name, func(t *testing.T) {
+			fileName := filepath.Join(dir, name)
+			if !writeTestBinoutWithOptions(t, fileName, options) {
+				return
+			}
+
+			binFile, err := BinoutOpen(fileName)
+			if !assert.Nil(t, err) {
+				return
+			}
+			defer binFile.Close()
+
+			files, err := binFile.Files()
+			if assert.Nil(t, err) && assert.Len(t, files, 1) {
+				assert.Equal(t, options.ByteOrder == binary.BigEndian, files[0].BigEndian)
+				assert.Equal(t, options.RecordLengthFieldSize, files[0].RecordLengthFieldSize)
+				assert.Equal(t, options.RecordTypeIDFieldSize, files[0].RecordTypeIDFieldSize)
+			}
+
+			ids, err := binFile.ReadInt32("/nodout/metadata/ids")
+			assert.Nil(t, err)
+			assert.Equal(t, []int32{10, 20, 30}, ids)
+
+			title, err := binFile.ReadString("/glstat/metadata/title")
+			assert.Nil(t, err)
+			assert.Equal(t, "Test", title)
+
+			x, err := binFile.ReadTimedFloat32("/nodout/x_displacement")
+			assert.Nil(t, err)
+			assert.Equal(t, [][]float32{{0, 0, 0}, {1, 2, 3}, {2, 4, 6}}, x)
+
+			ke, err := binFile.ReadTimedFloat64("/glstat/kinetic_energy")
+			assert.Nil(t, err)
+			assert.Equal(t, [][]float64{{0.0}, {5.0}, {10.0}}, ke)
+		})
+	}
+
+	options := DefaultBinoutWriterOptions()
+	options.RecordTypeIDFieldSize = 9
+	_, err := NewBinoutWriterWithOptions(&bytes.Buffer{}, options)
+	assert.NotNil(t, err)
+}
+
+func TestGlstatEnergyBalance(t *testing.T) {
+	glstat := Glstat{
+		Time:                   []float64{0.0, 1.0, 2.0, 3.0},
+		InternalEnergy:         []float64{0.0, 10.0, 20.0, 20.0},
+		TotalEnergy:            []float64{100.0, 100.0, 100.0, 130.0},
+		HourglassEnergy:        []float64{0.0, 0.5, 4.0, 3.0},
+		SlidingInterfaceEnergy: []float64{0.0, -1.0, -2.0, -3.0},
+		EnergyRatio:            []float64{1.0, 1.0, 1.0, 1.3},
+	}
+
+	warnings := glstat.CheckEnergyBalance(DefaultGlstatEnergyLimits())
+	if !assert.Len(t, warnings, 2) {
+		return
+	}
+
+	assert.Equal(t, GlstatCheckHourglassRatio, warnings[0].Check)
+	assert.Equal(t, 2.0, warnings[0].FirstTime)
+	assert.Equal(t, 2.0, warnings[0].Time)
+	assert.InDelta(t, 0.2, warnings[0].Value, 1e-12)
+
+	assert.Equal(t, GlstatCheckEnergyRatio, warnings[1].Check)
+	assert.Equal(t, 3.0, warnings[1].Time)
+	assert.Equal(t, 1.1, warnings[1].Limit)
+
+	assert.Empty(t, glstat.CheckEnergyBalance(GlstatEnergyLimits{}))
+
+	fileName := filepath.Join(t.TempDir(), "binout")
+	if !writeTestBinout(t, fileName) {
+		return
+	}
+	binFile, err := BinoutOpen(fileName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer binFile.Close()
+
+	glstat, err = binFile.Glstat()
+	if assert.Nil(t, err) {
+		assert.Equal(t, []float64{0.0, 0.5, 1.0}, glstat.Time)
+		assert.Equal(t, []float64{0.0, 5.0, 10.0}, glstat.KineticEnergy)
+		assert.Equal(t, []float64{0.0, 2.5, 5.0}, glstat.InternalEnergy)
+		assert.Nil(t, glstat.TotalEnergy)
+	}
+}
+
+func TestTimeSeries(t *testing.T) {
+	ts := TimeSeries{
+		Time:   []float64{0.0, 1.0, 3.0},
+		Values: []float64{0.0, 2.0, 6.0},
+		Label:  "x_displacement 1",
+		Unit:   "mm",
+	}
+
+	assert.Equal(t, 1.0, ts.At(0.5, InterpolationLinear))
+	assert.Equal(t, 0.0, ts.At(0.5, InterpolationZeroOrderHold))
+	assert.Equal(t, 4.0, ts.At(2.0, InterpolationLinear))
+	assert.Equal(t, 6.0, ts.At(10.0, InterpolationLinear))
+
+	resampled := ts.ResampleUniform(1.0, InterpolationLinear)
+	assert.Equal(t, []float64{0.0, 1.0, 2.0, 3.0}, resampled.Time)
+	assert.Equal(t, []float64{0.0, 2.0, 4.0, 6.0}, resampled.Values)
+	assert.Equal(t, ts.Label, resampled.Label)
+	assert.Equal(t, ts.Unit, resampled.Unit)
+
+	window := ts.Window(0.5, 3.0)
+	assert.Equal(t, []float64{1.0, 3.0}, window.Time)
+	assert.Equal(t, []float64{2.0, 6.0}, window.Values)
+	assert.Equal(t, ts.Unit, window.Unit)
+
+	other := TimeSeries{
+		Time:   []float64{0.5, 2.0, 4.0},
+		Values: []float64{1.0, 1.0, 1.0},
+	}
+	aligned := AlignSeries(InterpolationLinear, ts, other)
+	if assert.Len(t, aligned, 2) {
+		assert.Equal(t, []float64{0.5, 1.0, 2.0, 3.0}, aligned[0].Time)
+		assert.Equal(t, aligned[0].Time, aligned[1].Time)
+		assert.Equal(t, []float64{1.0, 2.0, 4.0, 6.0}, aligned[0].Values)
+		assert.Equal(t, ts.Unit, aligned[0].Unit)
+	}
+
+	fileName := filepath.Join(t.TempDir(), "binout")
+	if !writeTestBinout(t, fileName) {
+		return
+	}
+	binFile, err := BinoutOpen(fileName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer binFile.Close()
+
+	series, err := binFile.ReadSeries("nodout/x_displacement")
+	if assert.Nil(t, err) && assert.Len(t, series, 3) {
+		assert.Equal(t, "x_displacement 20 (Node 20)", series[1].Label)
+		assert.Equal(t, []float64{0.0, 0.5, 1.0}, series[1].Time)
+		assert.Equal(t, []float64{0.0, 2.0, 4.0}, series[1].Values)
+		assert.Empty(t, series[1].Unit)
+	}
+
+	series, err = binFile.ReadSeries("glstat/kinetic_energy")
+	if assert.Nil(t, err) && assert.Len(t, series, 1) {
+		assert.Equal(t, "kinetic_energy", series[0].Label)
+		assert.Equal(t, []float64{0.0, 5.0, 10.0}, series[0].Values)
+	}
+
+	_, err = binFile.ReadSeries("nodout/metadata/ids")
+	assert.NotNil(t, err)
+	_, err = binFile.ReadSeries("nodout/does_not_exist")
+	assert.ErrorIs(t, err, ErrPathNotFound)
+
+	unitsName := filepath.Join(t.TempDir(), "binout")
+	writer, err := BinoutCreate(unitsName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	writeTestDatabase(t, writer, "/nodout", []int32{10}, 1, "x_displacement")
+	assert.Nil(t, writer.Cd("/nodout/metadata"))
+	assert.Nil(t, writer.WriteString("units", "mm"))
+	if !assert.Nil(t, writer.Close()) {
+		return
+	}
+	unitsFile, err := BinoutOpen(unitsName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer unitsFile.Close()
+
+	series, err = unitsFile.ReadSeries("nodout/x_displacement")
+	if assert.Nil(t, err) && assert.Len(t, series, 1) {
+		assert.Equal(t, "mm", series[0].Unit)
+	}
+}
+
 func TestD3plot(t *testing.T) {
 	plotFile, err := D3plotOpen("test_data/d3plot_files/d3plot")
 	if !assert.Nil(t, err) {
@@ -181,6 +1390,773 @@ func TestD3plot(t *testing.T) {
 	}
 }
 
+// d3plotTestWords encodes int32 and float32 values as the words of a single
+// precision d3plot
+func d3plotTestWords(values ...any) []byte {
+	var buffer bytes.Buffer
+	for _, value := range values {
+		binary.Write(&buffer, binary.LittleEndian, value)
+	}
+	return buffer.Bytes()
+}
+
+// d3plotTestState returns a state of the d3plot written by
+// writeTestD3plotRoot, which only contains the time, the global variables and
+// the node coordinates
+func d3plotTestState(time float32) []byte {
+	return d3plotTestWords(time, [6]float32{}, [6]float32{time, 0, 0, time, 1, 0})
+}
+
+// writeTestD3plotRoot writes the root file of a single precision d3plot with
+// two nodes and no elements
+func writeTestD3plotRoot(t *testing.T, fileName string) bool {
+	var control [64]int32
+	control[11] = 1 // FILETYPE
+	control[15] = 4 // NDIM
+	control[16] = 2 // NUMNP
+	control[17] = 6 // ICODE
+	control[18] = 6 // NGLBV
+	control[20] = 1 // IU
+
+	root := d3plotTestWords(control, [6]float32{0, 0, 0, 0, 1, 0}, float32(-999999.0), float32(-999999.0))
+	return assert.Nil(t, os.WriteFile(fileName, root, 0o644))
+}
+
+// writeTestD3plotRootWithIDs is the same as writeTestD3plotRoot, but also
+// writes the user IDs of the two nodes and of one part
+func writeTestD3plotRootWithIDs(t *testing.T, fileName string, nodeIDs [2]int32, partID int32) bool {
+	var control [64]int32
+	control[11] = 1  // FILETYPE
+	control[15] = 4  // NDIM
+	control[16] = 2  // NUMNP
+	control[17] = 6  // ICODE
+	control[18] = 6  // NGLBV
+	control[20] = 1  // IU
+	control[39] = 21 // NARBS
+	control[51] = 1  // NMMAT
+
+	userIDs := [16]int32{-1} // NSORT
+	userIDs[5] = 2           // NSORTD
+	userIDs[15] = 1          // NMMAT
+
+	root := d3plotTestWords(control, [6]float32{0, 0, 0, 0, 1, 0}, userIDs, nodeIDs, [3]int32{partID, 1, 1}, float32(-999999.0), float32(-999999.0))
+	return assert.Nil(t, os.WriteFile(fileName, root, 0o644))
+}
+
+func TestD3plotRefresh(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "d3plot")
+	if !writeTestD3plotRoot(t, root) {
+		return
+	}
+	eof := d3plotTestWords(float32(-999999.0))
+
+	// No state has been written yet
+	plotFile, err := D3plotOpen(root)
+	if !assert.Nil(t, err) {
+		return
+	}
+	// Refresh changes plotFile, so the deferred call must not copy it now
+	defer func() { plotFile.Close() }()
+	assert.Equal(t, uint64(0), plotFile.NumTimeSteps())
+
+	// A state is only complete once the word after it has been written
+	state01 := d3plotTestState(0.0)
+	assert.Nil(t, os.WriteFile(root+"01", state01, 0o644))
+	assert.Nil(t, plotFile.Refresh())
+	assert.Equal(t, uint64(0), plotFile.NumTimeSteps())
+
+	state01 = append(state01, d3plotTestState(0.5)[:20]...)
+	assert.Nil(t, os.WriteFile(root+"01", state01, 0o644))
+	assert.Nil(t, plotFile.Refresh())
+	assert.Equal(t, uint64(1), plotFile.NumTimeSteps())
+
+	state01 = append(d3plotTestState(0.0), d3plotTestState(0.5)...)
+	state01 = append(state01, eof...)
+	assert.Nil(t, os.WriteFile(root+"01", state01, 0o644))
+	assert.Nil(t, os.WriteFile(root+"02", append(d3plotTestState(1.0), eof...), 0o644))
+	assert.Nil(t, plotFile.Refresh())
+	assert.Equal(t, uint64(3), plotFile.NumTimeSteps())
+
+	times, err := plotFile.ReadAllTime()
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{0.0, 0.5, 1.0}, times)
+
+	coords, err := plotFile.ReadNodeCoordinates(2)
+	assert.Nil(t, err)
+	assert.Equal(t, [][3]float64{{1.0, 0.0, 0.0}, {1.0, 1.0, 0.0}}, coords)
+
+	// Watch sends the states of new files
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	states, errs := plotFile.WatchInterval(ctx, 5*time.Millisecond)
+
+	state03 := append(d3plotTestState(1.5), d3plotTestState(2.0)...)
+	assert.Nil(t, os.WriteFile(root+"03", append(state03, eof...), 0o644))
+
+	for _, expected := range []uint64{3, 4} {
+		select {
+		case state := <-states:
+			assert.Equal(t, expected, state)
+		case err := <-errs:
+			assert.Fail(t, "Watch failed", err)
+			return
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "Watch did not send the new states")
+			return
+		}
+	}
+
+	// The states can be read without calling Refresh
+	stateTime, err := plotFile.ReadTime(4)
+	assert.Nil(t, err)
+	assert.Equal(t, 2.0, stateTime)
+
+	cancel()
+	for range states {
+	}
+
+	// Watching stops with an error once the d3plot has been closed
+	watched, err := D3plotOpen(root)
+	if !assert.Nil(t, err) {
+		return
+	}
+	states, errs = watched.WatchInterval(context.Background(), 5*time.Millisecond)
+	watched.Close()
+	select {
+	case err := <-errs:
+		assert.ErrorIs(t, err, ErrClosed)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "Watch did not stop")
+	}
+	for range states {
+	}
+}
+
+func TestD3plotWithoutEOFMarker(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "d3plot")
+	if !writeTestD3plotRoot(t, root) {
+		return
+	}
+	// The last state ends exactly at the end of the files
+	assert.Nil(t, os.WriteFile(root+"01", append(d3plotTestState(0.0), d3plotTestState(0.5)...), 0o644))
+	assert.Nil(t, os.WriteFile(root+"02", d3plotTestState(1.0), 0o644))
+
+	plotFile, err := D3plotOpen(root)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer func() { plotFile.Close() }()
+
+	times, err := plotFile.ReadAllTime()
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{0.0, 0.5, 1.0}, times)
+
+	assert.Nil(t, plotFile.Refresh())
+	assert.Equal(t, uint64(3), plotFile.NumTimeSteps())
+}
+
+func TestD3plotStates(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "d3plot")
+	if !writeTestD3plotRoot(t, root) {
+		return
+	}
+	states := append(d3plotTestState(0.0), d3plotTestState(0.5)...)
+	states = append(states, d3plotTestState(1.0)...)
+	states = append(states, d3plotTestWords(float32(-999999.0))...)
+	assert.Nil(t, os.WriteFile(root+"01", states, 0o644))
+
+	plotFile, err := D3plotOpen(root)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer plotFile.Close()
+
+	allCoords, err := plotFile.ReadAllNodeCoordinates()
+	assert.Nil(t, err)
+	assert.Len(t, allCoords, 3)
+
+	var read []D3plotState
+	err = plotFile.States(context.Background(), func(state D3plotState) error {
+		read = append(read, state)
+		return nil
+	}, D3plotFieldCoordinates)
+	assert.Nil(t, err)
+	if assert.Len(t, read, 3) {
+		for i, state := range read {
+			assert.Equal(t, uint64(i), state.Index)
+			assert.Equal(t, float64(i)*0.5, state.Time)
+			assert.Equal(t, allCoords[i], state.Coordinates)
+			assert.Nil(t, state.Solids)
+		}
+	}
+
+	stop := fmt.Errorf("stop")
+	numCalls := 0
+	err = plotFile.States(context.Background(), func(state D3plotState) error {
+		numCalls++
+		return stop
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 1, numCalls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = plotFile.States(ctx, func(state D3plotState) error {
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	err = plotFile.States(context.Background(), func(state D3plotState) error {
+		return nil
+	}, 100)
+	assert.NotNil(t, err)
+}
+
+func TestD3plotReadStatesParallel(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "d3plot")
+	if !writeTestD3plotRoot(t, root) {
+		return
+	}
+
+	// Spread the states across two files
+	const numStates = 40
+	eof := d3plotTestWords(float32(-999999.0))
+	for f := 0; f < 2; f++ {
+		var states []byte
+		for i := 0; i < numStates/2; i++ {
+			states = append(states, d3plotTestState(float32(f*numStates/2+i))...)
+		}
+		assert.Nil(t, os.WriteFile(fmt.Sprintf("%s%02d", root, f+1), append(states, eof...), 0o644))
+	}
+
+	plotFile, err := D3plotOpen(root)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer plotFile.Close()
+	if !assert.Equal(t, uint64(numStates), plotFile.NumTimeSteps()) {
+		return
+	}
+
+	indices := make([]uint64, numStates)
+	for i := range indices {
+		indices[i] = uint64(i)
+	}
+
+	var mtx sync.Mutex
+	read := make(map[uint64]D3plotState)
+	err = plotFile.ReadStatesParallel(indices, 8, func(state D3plotState) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		read[state.Index] = state
+		return nil
+	}, D3plotFieldCoordinates)
+	assert.Nil(t, err)
+	assert.Len(t, read, numStates)
+
+	for i := uint64(0); i < numStates; i++ {
+		expected, err := plotFile.ReadState(i, D3plotFieldCoordinates)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, read[i])
+		assert.Equal(t, float64(i), read[i].Time)
+	}
+
+	// The methods can also be used directly from multiple goroutines
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := uint64(0); i < numStates; i++ {
+				coords, err := plotFile.ReadNodeCoordinates(i)
+				assert.Nil(t, err)
+				assert.Equal(t, read[i].Coordinates, coords)
+			}
+		}()
+	}
+	wg.Wait()
+
+	err = plotFile.ReadStatesParallel([]uint64{0, numStates + 10, 1}, 2, func(state D3plotState) error {
+		return nil
+	})
+	assert.NotNil(t, err)
+}
+
+func TestContextReads(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	dir := t.TempDir()
+	binoutName := filepath.Join(dir, "binout")
+	if writeTestBinout(t, binoutName) {
+		binFile, err := BinoutOpen(binoutName)
+		if assert.Nil(t, err) {
+			defer binFile.Close()
+
+			expected, err := binFile.ReadTimedFloat32("/nodout/x_displacement")
+			assert.Nil(t, err)
+			x, err := binFile.ReadTimedFloat32Context(context.Background(), "/nodout/x_displacement")
+			assert.Nil(t, err)
+			assert.Equal(t, expected, x)
+
+			_, err = binFile.ReadTimedFloat64Context(canceled, "/glstat/kinetic_energy")
+			assert.ErrorIs(t, err, context.Canceled)
+			_, err = binFile.ReadTimedFloat64Context(context.Background(), "/glstat/does_not_exist")
+			assert.NotNil(t, err)
+		}
+	}
+
+	root := filepath.Join(dir, "d3plot")
+	if writeTestD3plotRoot(t, root) {
+		states := append(d3plotTestState(0.0), d3plotTestState(0.5)...)
+		assert.Nil(t, os.WriteFile(root+"01", append(states, d3plotTestWords(float32(-999999.0))...), 0o644))
+
+		plotFile, err := D3plotOpen(root)
+		if assert.Nil(t, err) {
+			defer plotFile.Close()
+
+			expected, err := plotFile.ReadAllNodeCoordinates()
+			assert.Nil(t, err)
+			coords, err := plotFile.ReadAllNodeCoordinatesContext(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, expected, coords)
+
+			_, err = plotFile.ReadAllNodeCoordinatesContext(canceled)
+			assert.ErrorIs(t, err, context.Canceled)
+		}
+	}
+
+	keyName := filepath.Join(dir, "main.k")
+	includeName := filepath.Join(dir, "include.k")
+	assert.Nil(t, os.WriteFile(keyName, []byte("*KEYWORD\n*INCLUDE\ninclude.k\n*PART\npart\n1,1,1\n*END\n"), 0o644))
+	assert.Nil(t, os.WriteFile(includeName, []byte("*NODE\n1,0.0,0.0,0.0\n2,1.0,0.0,0.0\n*END\n"), 0o644))
+
+	cfg := DefaultKeyFileParseConfig()
+	cfg.ExtraIncludePaths = []string{dir}
+
+	keywords, warn, err := KeyFileParseContext(context.Background(), keyName, cfg)
+	assert.Nil(t, warn)
+	if assert.Nil(t, err) {
+		_, err = keywords.Get("NODE", 0)
+		assert.Nil(t, err)
+		keywords.Free()
+	}
+
+	_, _, err = KeyFileParseContext(canceled, keyName, cfg)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Cancel while parsing. The callback is not called anymore afterwards.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var keywordNames []string
+	_, err = KeyFileParseWithCallbackContext(ctx, keyName, func(_ KeyParseInfo, keywordName string, _ *Card, _ int) {
+		keywordNames = append(keywordNames, keywordName)
+		cancel()
+	}, cfg)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []string{"KEYWORD"}, keywordNames)
+}
+
+func TestErrors(t *testing.T) {
+	dir := t.TempDir()
+	binoutName := filepath.Join(dir, "binout")
+	if writeTestBinout(t, binoutName) {
+		binFile, err := BinoutOpen(binoutName)
+		if assert.Nil(t, err) {
+			defer binFile.Close()
+
+			_, err = binFile.ReadFloat32("/nodout/metadata/does_not_exist")
+			assert.ErrorIs(t, err, ErrPathNotFound)
+			assert.Equal(t, "\"/nodout/metadata/does_not_exist\" has not been found", err.Error())
+			_, err = binFile.ReadTimedFloat64("/glstat/does_not_exist")
+			assert.ErrorIs(t, err, ErrPathNotFound)
+			_, err = binFile.GetNumTimesteps("/does_not_exist")
+			assert.ErrorIs(t, err, ErrPathNotFound)
+			_, err = binFile.ReadTimedFloat32("/nodout/metadata")
+			assert.ErrorIs(t, err, ErrPathNotFound)
+			assert.Contains(t, err.Error(), "is either metadata (not timed) or does not exist")
+
+			_, err = binFile.ReadFloat64("/nodout/metadata/ids")
+			assert.ErrorIs(t, err, ErrTypeMismatch)
+			assert.NotErrorIs(t, err, ErrPathNotFound)
+			_, err = binFile.ReadString("/nodout/metadata/ids")
+			assert.ErrorIs(t, err, ErrTypeMismatch)
+		}
+	}
+
+	root := filepath.Join(dir, "d3plot")
+	if writeTestD3plotRoot(t, root) {
+		assert.Nil(t, os.WriteFile(root+"01", append(d3plotTestState(0.0), d3plotTestWords(float32(-999999.0))...), 0o644))
+
+		plotFile, err := D3plotOpen(root)
+		if assert.Nil(t, err) {
+			defer plotFile.Close()
+
+			_, err = plotFile.ReadTime(1)
+			assert.ErrorIs(t, err, ErrStateOutOfRange)
+			_, err = plotFile.ReadNodeCoordinates(1)
+			assert.ErrorIs(t, err, ErrStateOutOfRange)
+			_, err = plotFile.ReadNodeCoordinates(0)
+			assert.Nil(t, err)
+		}
+	}
+
+	// The sections depend on NDIM
+	for ndim, section := range map[int32]string{
+		3: "Packed Element Connectivity",
+		5: "MATERIAL TYPE DATA",
+		2: "",
+	} {
+		if !writeTestD3plotRoot(t, root) {
+			break
+		}
+		rootData, err := os.ReadFile(root)
+		if !assert.Nil(t, err) {
+			break
+		}
+		binary.LittleEndian.PutUint32(rootData[15*4:], uint32(ndim))
+		assert.Nil(t, os.WriteFile(root, rootData, 0o644))
+
+		_, err = D3plotOpen(root)
+		var sectionErr *UnsupportedSectionError
+		if section == "" {
+			assert.NotErrorIs(t, err, ErrUnsupportedSection)
+			assert.NotNil(t, err)
+		} else if assert.ErrorAs(t, err, &sectionErr) {
+			assert.ErrorIs(t, err, ErrUnsupportedSection)
+			assert.Equal(t, section, sectionErr.Section)
+			assert.Contains(t, sectionErr.Error(), section+" is not ")
+		}
+	}
+
+	keyName := filepath.Join(dir, "main.k")
+	assert.Nil(t, os.WriteFile(keyName, []byte("*KEYWORD\n*INCLUDE\nmissing.k\n*INCLUDE\nalso_missing.k\n*END\n"), 0o644))
+
+	_, _, err := KeyFileParse(keyName, DefaultKeyFileParseConfig())
+	assert.ErrorIs(t, err, ErrIncludeNotFound)
+	var includeErr *IncludeNotFoundError
+	if assert.ErrorAs(t, err, &includeErr) {
+		assert.Equal(t, keyName, includeErr.File)
+		assert.Equal(t, 3, includeErr.Line)
+		assert.Equal(t, "missing.k", includeErr.Include)
+	}
+	assert.Equal(t, fmt.Sprintf("%[1]s:3: \"missing.k\" could not be found\n%[1]s:5: \"also_missing.k\" could not be found", keyName), err.Error())
+
+	_, err = KeyFileParseWithCallback(keyName, func(KeyParseInfo, string, *Card, int) {}, DefaultKeyFileParseConfig())
+	assert.ErrorIs(t, err, ErrIncludeNotFound)
+
+	// The error of a nested include refers to the include file and the
+	// include name may contain anything
+	nestedName := filepath.Join(dir, "nested.k")
+	assert.Nil(t, os.WriteFile(nestedName, []byte("*KEYWORD\n*INCLUDE\nmissing: \"1\".k\n*END\n"), 0o644))
+	assert.Nil(t, os.WriteFile(keyName, []byte("*KEYWORD\n*INCLUDE\nnested.k\n*END\n"), 0o644))
+
+	parseConfig := DefaultKeyFileParseConfig()
+	parseConfig.ExtraIncludePaths = []string{dir}
+	_, err = KeyFileParseWithCallback(keyName, func(KeyParseInfo, string, *Card, int) {}, parseConfig)
+	if assert.ErrorAs(t, err, &includeErr) {
+		assert.Equal(t, nestedName, includeErr.File)
+		assert.Equal(t, 3, includeErr.Line)
+		assert.Equal(t, "missing: \"1\".k", includeErr.Include)
+	}
+}
+
+func TestHandleLifetime(t *testing.T) {
+	dir := t.TempDir()
+	binoutName := filepath.Join(dir, "binout")
+	if writeTestBinout(t, binoutName) {
+		binFile, err := BinoutOpen(binoutName)
+		if assert.Nil(t, err) {
+			// The calls of multiple goroutines are serialized
+			var wg sync.WaitGroup
+			for g := 0; g < 4; g++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					x, err := binFile.ReadTimedFloat32("/nodout/x_displacement")
+					assert.Nil(t, err)
+					assert.Len(t, x, 3)
+					_, err = binFile.ReadFloat32("/nodout/does_not_exist")
+					assert.ErrorIs(t, err, ErrPathNotFound)
+				}()
+			}
+			wg.Wait()
+
+			copied := binFile
+			copied.Close()
+			copied.Close()
+
+			_, err = binFile.ReadFloat32("/nodout/metadata/ids")
+			assert.ErrorIs(t, err, ErrClosed)
+			_, err = binFile.GetNumTimesteps("/nodout")
+			assert.ErrorIs(t, err, ErrClosed)
+			assert.ErrorIs(t, binFile.Refresh(), ErrClosed)
+			assert.Equal(t, uint64(BinoutTypeInvalid), binFile.GetTypeID("/nodout/metadata/ids"))
+			assert.Empty(t, binFile.GetChildren("/"))
+			assert.False(t, binFile.VariableExists("/nodout/metadata/ids"))
+			binFile.Close()
+		}
+	}
+
+	root := filepath.Join(dir, "d3plot")
+	if writeTestD3plotRoot(t, root) {
+		assert.Nil(t, os.WriteFile(root+"01", append(d3plotTestState(0.0), d3plotTestWords(float32(-999999.0))...), 0o644))
+
+		plotFile, err := D3plotOpen(root)
+		if assert.Nil(t, err) {
+			copied := plotFile
+			assert.Nil(t, copied.Refresh())
+			assert.Equal(t, uint64(1), plotFile.NumTimeSteps())
+
+			copied.Close()
+			copied.Close()
+
+			_, err = plotFile.ReadTime(0)
+			assert.ErrorIs(t, err, ErrClosed)
+			_, err = plotFile.ReadNodeIDs()
+			assert.ErrorIs(t, err, ErrClosed)
+			assert.ErrorIs(t, plotFile.Refresh(), ErrClosed)
+			assert.Equal(t, uint64(0), plotFile.NumTimeSteps())
+			plotFile.Close()
+		}
+	}
+
+	plotFile, err := D3plotOpen(filepath.Join(dir, "does_not_exist"))
+	assert.NotNil(t, err)
+	_, err = plotFile.ReadTime(0)
+	assert.ErrorIs(t, err, ErrClosed)
+	plotFile.Close()
+
+	keyName := filepath.Join(dir, "main.k")
+	assert.Nil(t, os.WriteFile(keyName, []byte("*KEYWORD\n*NODE\n1,0.0,0.0,0.0\n*END\n"), 0o644))
+	keywords, _, err := KeyFileParse(keyName, DefaultKeyFileParseConfig())
+	if assert.Nil(t, err) {
+		assert.Equal(t, 2, keywords.Len())
+		node, err := keywords.Get("NODE", 0)
+		assert.Nil(t, err)
+		card, err := node.Get(0)
+		assert.Nil(t, err)
+		assert.Equal(t, "1,0.0,0.0,0.0", card.ParseWhole())
+		_, err = node.Get(1)
+		assert.NotNil(t, err)
+		assert.NotErrorIs(t, err, ErrClosed)
+
+		copied := keywords
+		copied.Free()
+		copied.Free()
+
+		// The keywords and cards can not be used after the memory has been freed
+		_, err = card.ParseInt()
+		assert.ErrorIs(t, err, ErrClosed)
+		assert.Equal(t, "", card.ParseWhole())
+		assert.True(t, card.ParseDone())
+		assert.Equal(t, 0, node.Len())
+		_, err = node.Get(0)
+		assert.ErrorIs(t, err, ErrClosed)
+		_, err = node.GetSlice()
+		assert.ErrorIs(t, err, ErrClosed)
+
+		_, err = keywords.Get("NODE", 0)
+		assert.ErrorIs(t, err, ErrClosed)
+		_, err = keywords.GetSlice("NODE")
+		assert.ErrorIs(t, err, ErrClosed)
+		assert.Equal(t, 0, keywords.Len())
+		keywords.Free()
+	}
+}
+
+func TestLeakHandler(t *testing.T) {
+	leaks := make(chan string, 3)
+	SetLeakHandler(func(leak string) {
+		leaks <- leak
+	})
+	defer SetLeakHandler(nil)
+
+	dir := t.TempDir()
+	root := filepath.Join(dir, "d3plot")
+	if !writeTestD3plotRoot(t, root) {
+		return
+	}
+
+	func() {
+		_, err := D3plotOpen(root)
+		assert.Nil(t, err)
+	}()
+
+	plotFile, err := D3plotOpen(root)
+	assert.Nil(t, err)
+	plotFile.Close()
+
+	var leaked []string
+	for i := 0; i < 100 && len(leaked) == 0; i++ {
+		runtime.GC()
+		select {
+		case leak := <-leaks:
+			leaked = append(leaked, leak)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	assert.Equal(t, []string{fmt.Sprintf("D3plot \"%s\" has not been closed", root)}, leaked)
+}
+
+func TestD3plotIndex(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "d3plot")
+	if !writeTestD3plotRootWithIDs(t, root, [2]int32{42, 7}, 100) {
+		return
+	}
+
+	plotFile, err := D3plotOpen(root)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer plotFile.Close()
+
+	index, err := plotFile.Index()
+	if !assert.Nil(t, err) {
+		return
+	}
+	again, err := plotFile.Index()
+	assert.Nil(t, err)
+	assert.Same(t, index, again)
+
+	assert.Equal(t, 2, index.Nodes.Len())
+	assert.Equal(t, []uint64{42, 7}, index.Nodes.IDs())
+	nodeIndex, ok := index.Nodes.Index(7)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), nodeIndex)
+	_, ok = index.Nodes.Index(8)
+	assert.False(t, ok)
+	id, ok := index.Nodes.ID(0)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(42), id)
+	_, ok = index.Nodes.ID(2)
+	assert.False(t, ok)
+
+	assert.Equal(t, []uint64{1, D3plotNotFound, 0}, index.Nodes.Indices([]uint64{7, 8, 42}))
+	assert.Equal(t, []uint64{7, 42, D3plotNotFound}, index.Nodes.IDsAt([]uint64{1, 0, 5}))
+
+	assert.Equal(t, []uint64{100}, index.Parts.IDs())
+	assert.Equal(t, 0, index.Solids.Len())
+	assert.Equal(t, 0, index.Shells.Len())
+	assert.Equal(t, []uint64{D3plotNotFound}, index.Shells.Indices([]uint64{1}))
+
+	assert.Equal(t, uint64(D3plotNotFound), D3plotIndexForID(1, nil))
+	assert.Equal(t, uint64(1), D3plotIndexForID(7, []uint64{5, 7, 9}))
+
+	plotFile.Close()
+	_, err = plotFile.Index()
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+// writeTestD3plotShells writes a single precision d3plot with three nodes and
+// two shells. The first shell connects the first two nodes and belongs to the
+// first part, the second one connects the last two nodes and belongs to the
+// second part. Every value of a shell in the state is its index plus one.
+func writeTestD3plotShells(t *testing.T, fileName string) bool {
+	const nv2d = 44
+
+	var control [64]int32
+	control[11] = 1    // FILETYPE
+	control[15] = 4    // NDIM
+	control[16] = 3    // NUMNP
+	control[17] = 6    // ICODE
+	control[18] = 6    // NGLBV
+	control[20] = 1    // IU
+	control[31] = 2    // NEL4
+	control[32] = 2    // NUMMAT4
+	control[33] = nv2d // NV2D
+	control[39] = 27   // NARBS
+	control[51] = 2    // NMMAT
+
+	coords := [9]float32{0, 0, 0, 1, 0, 0, 2, 0, 0}
+	shells := [10]int32{1, 2, 2, 1, 1, 2, 3, 3, 2, 2}
+
+	userIDs := [16]int32{-1} // NSORT
+	userIDs[5] = 3           // NSORTD
+	userIDs[8] = 2           // NSRSD
+	userIDs[15] = 2          // NMMAT
+
+	root := d3plotTestWords(control, coords, shells, userIDs, [3]int32{1, 2, 3}, [2]int32{10, 20}, [6]int32{100, 200, 1, 2, 1, 2}, float32(-999999.0), float32(-999999.0))
+	if !assert.Nil(t, os.WriteFile(fileName, root, 0o644)) {
+		return false
+	}
+
+	var shellValues [2 * nv2d]float32
+	for i := range shellValues {
+		shellValues[i] = float32(i/nv2d + 1)
+	}
+	state := d3plotTestWords(float32(0.0), [6]float32{}, [9]float32{0, 0, 0, 1, 1, 0, 2, 2, 0}, shellValues, float32(-999999.0))
+	return assert.Nil(t, os.WriteFile(fileName+"01", state, 0o644))
+}
+
+func TestD3plotPartReaders(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "d3plot")
+	if !writeTestD3plotShells(t, root) {
+		return
+	}
+
+	plotFile, err := D3plotOpen(root)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer plotFile.Close()
+
+	part, err := plotFile.ReadPartByID(200, nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer part.Free()
+
+	assert.Equal(t, []uint64{1}, part.ShellIndices())
+	assert.Empty(t, part.SolidIndices())
+	assert.Empty(t, part.BeamIndices())
+	assert.Empty(t, part.ThickShellIndices())
+
+	coords, err := part.ReadNodeCoordinates(plotFile, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, [][3]float64{{1, 1, 0}, {2, 2, 0}}, coords)
+	// Copies of the part share the node indices
+	partCopy := part
+	coords, err = partCopy.ReadNodeCoordinates(plotFile, 0)
+	assert.Nil(t, err)
+	allCoords, err := plotFile.ReadNodeCoordinates(0)
+	if assert.Nil(t, err) && assert.Len(t, allCoords, 3) {
+		assert.Equal(t, allCoords[1:], coords)
+	}
+
+	shells, err := part.ReadShellStates(plotFile, 0)
+	if assert.Nil(t, err) && assert.Len(t, shells, 1) {
+		assert.Equal(t, 2.0, shells[0].Mid.Stress[0])
+		assert.Equal(t, 2.0, shells[0].Thickness)
+	}
+	allShells, err := plotFile.ReadShellStates(0)
+	if assert.Nil(t, err) && assert.Len(t, allShells, 2) {
+		assert.Equal(t, allShells[1:], shells)
+	}
+
+	solids, err := part.ReadSolidStates(plotFile, 0)
+	assert.Nil(t, err)
+	assert.Empty(t, solids)
+
+	otherPart, err := plotFile.ReadPartByID(100, nil)
+	if assert.Nil(t, err) {
+		shells, err = otherPart.ReadShellStates(plotFile, 0)
+		if assert.Nil(t, err) && assert.Len(t, shells, 1) {
+			assert.Equal(t, 1.0, shells[0].Mid.Stress[0])
+		}
+		otherPart.Free()
+	}
+
+	_, err = part.ReadShellsState(plotFile, 1)
+	assert.ErrorIs(t, err, ErrStateOutOfRange)
+	_, err = part.ReadNodeCoordinates(plotFile, 1)
+	assert.ErrorIs(t, err, ErrStateOutOfRange)
+}
+
 func TestKeyFile(t *testing.T) {
 	keywords, warn, err := KeyFileParse("test_data/key_file.k", DefaultKeyFileParseConfig())
 	assert.Nil(t, warn)
@@ -198,13 +2174,20 @@ func TestKeyFile(t *testing.T) {
 		return
 	}
 
-	title := parts[0].GetSlice()[0]
-	assert.Equal(t, "Cube", title.ParseWhole())
+	cards, err := parts[0].GetSlice()
+	if assert.Nil(t, err) {
+		assert.Equal(t, "Cube", cards[0].ParseWhole())
+	}
 
-	title = parts[1].GetSlice()[0]
-	assert.Equal(t, "Ground", title.ParseWhole())
+	cards, err = parts[1].GetSlice()
+	if assert.Nil(t, err) {
+		assert.Equal(t, "Ground", cards[0].ParseWhole())
+	}
 
-	card := parts[0].Get(1)
+	card, err := parts[0].Get(1)
+	if !assert.Nil(t, err) {
+		return
+	}
 
 	card.ParseBegin(DefaultValueWidth)
 	v, err := card.ParseInt()
@@ -245,7 +2228,10 @@ func TestKeyFile(t *testing.T) {
 		return
 	}
 
-	card = keyword.Get(1)
+	card, err = keyword.Get(1)
+	if !assert.Nil(t, err) {
+		return
+	}
 
 	card.ParseBegin(DefaultValueWidth)
 	assert.Equal(t, CardParseInt, card.ParseGetType())
@@ -0,0 +1,186 @@
+package dynareadout
+
+/*
+#cgo CFLAGS: -ansi
+#include "dynareadout/src/binout.h"
+#include "dynareadout/src/binout_defines.h"
+#include "dynareadout/src/d3plot.h"
+#include "dynareadout/src/key.h"
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+var (
+	// ErrPathNotFound is returned if a variable or folder of a binout does not exist
+	ErrPathNotFound = errors.New("The path has not been found")
+	// ErrTypeMismatch is returned if a binout variable is read as the wrong type
+	ErrTypeMismatch = errors.New("The variable is of a different type")
+	// ErrStateOutOfRange is returned if a d3plot state does not exist
+	ErrStateOutOfRange = errors.New("The state is out of range")
+	// ErrUnsupportedSection is matched by every *UnsupportedSectionError
+	ErrUnsupportedSection = errors.New("The section is not supported")
+	// ErrIncludeNotFound is matched by every *IncludeNotFoundError
+	ErrIncludeNotFound = errors.New("The include file could not be found")
+	// ErrClosed is returned by the methods of a Binout, D3plot or Keywords
+	// which has already been closed (or freed)
+	ErrClosed = errors.New("The file has already been closed")
+)
+
+// UnsupportedSectionError is returned by D3plotOpen if the d3plot contains a
+// section (e.g. "PARTICLE DATA") which can not be read
+type UnsupportedSectionError struct {
+	Section string
+	Message string
+}
+
+// IncludeNotFoundError is returned by the key file parsing if the file of an
+// *INCLUDE could not be found. File and Line are the position of the
+// *INCLUDE.
+type IncludeNotFoundError struct {
+	File    string
+	Line    int
+	Include string
+	Message string
+}
+
+// kindError keeps the message of the C library, but can be matched with
+// errors.Is against one of the sentinel errors
+type kindError struct {
+	kind    error
+	message string
+}
+
+// keyFileErrors contains all errors of a key file parse
+type keyFileErrors struct {
+	message string
+	errs    []error
+}
+
+func newKindError(kind error, format string, a ...any) error {
+	return &kindError{kind: kind, message: fmt.Sprintf(format, a...)}
+}
+
+// binoutError converts the error_string and error_kind of bin_file
+func binoutError(bin_file *C.binout_file) error {
+	str := C.GoString(bin_file.error_string)
+	switch bin_file.error_kind {
+	case C.BINOUT_ERROR_PATH_NOT_FOUND:
+		return &kindError{kind: ErrPathNotFound, message: str}
+	case C.BINOUT_ERROR_TYPE_MISMATCH:
+		return &kindError{kind: ErrTypeMismatch, message: str}
+	default:
+		return errors.New(str)
+	}
+}
+
+// d3plotError converts the error_string, error_kind and error_section of
+// plot_file
+func d3plotError(plot_file *C.d3plot_file) error {
+	str := C.GoString(plot_file.error_string)
+	switch plot_file.error_kind {
+	case C.D3PLOT_ERROR_STATE_OUT_OF_RANGE:
+		return &kindError{kind: ErrStateOutOfRange, message: str}
+	case C.D3PLOT_ERROR_UNSUPPORTED_SECTION:
+		return &UnsupportedSectionError{Section: C.GoString(plot_file.error_section), Message: str}
+	default:
+		return errors.New(str)
+	}
+}
+
+// keyFileError converts the errors of the key file parsing and deallocates
+// them. Returns nil if there are no errors.
+func keyFileError(cErrors *C.key_parse_error_t, numErrors C.size_t) error {
+	if numErrors == 0 {
+		return nil
+	}
+	defer C.key_free_parse_errors(cErrors, numErrors)
+
+	cErrs := unsafe.Slice(cErrors, numErrors)
+	errs := make([]error, len(cErrs))
+	messages := make([]string, len(cErrs))
+	for i, cErr := range cErrs {
+		messages[i] = C.GoString(cErr.message)
+		switch cErr.kind {
+		case C.KEY_ERROR_INCLUDE_NOT_FOUND:
+			errs[i] = &IncludeNotFoundError{
+				File:    C.GoString(cErr.file_name),
+				Line:    int(cErr.line_number),
+				Include: C.GoString(cErr.include_name),
+				Message: messages[i],
+			}
+		default:
+			errs[i] = errors.New(messages[i])
+		}
+	}
+
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return &keyFileErrors{message: strings.Join(messages, "\n"), errs: errs}
+}
+
+func (e *UnsupportedSectionError) Error() string {
+	return e.Message
+}
+
+func (e *UnsupportedSectionError) Is(target error) bool {
+	return target == ErrUnsupportedSection
+}
+
+func (e *IncludeNotFoundError) Error() string {
+	return e.Message
+}
+
+func (e *IncludeNotFoundError) Is(target error) bool {
+	return target == ErrIncludeNotFound
+}
+
+func (e *kindError) Error() string {
+	return e.message
+}
+
+func (e *kindError) Unwrap() error {
+	return e.kind
+}
+
+func (e *keyFileErrors) Error() string {
+	return e.message
+}
+
+func (e *keyFileErrors) Unwrap() []error {
+	return e.errs
+}
+
+// Is and As are needed because errors.Is and errors.As only look at
+// Unwrap() []error since go 1.20
+func (e *keyFileErrors) Is(target error) bool {
+	return isAny(e.errs, target)
+}
+
+func (e *keyFileErrors) As(target any) bool {
+	return asAny(e.errs, target)
+}
+
+func isAny(errs []error, target error) bool {
+	for _, err := range errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func asAny(errs []error, target any) bool {
+	for _, err := range errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
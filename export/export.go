@@ -0,0 +1,393 @@
+// Package export converts binout and d3plot results into Apache Arrow record
+// batches and writes them as Parquet files so that they can be queried with
+// tools like DuckDB or Spark.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	dro "github.com/PucklaJ/dynareadout_go"
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+)
+
+// The element and node fields of a d3plot which can be exported
+const (
+	D3plotNodes = iota
+	D3plotSolids
+	D3plotThickShells
+	D3plotShells
+	D3plotBeams
+)
+
+// column is one column of a record batch. Exactly one of the slices is set.
+type column struct {
+	name   string
+	floats []float64
+	ints   []int64
+}
+
+// BinoutRecord converts timed variables of one binout database (as accepted
+// by SimplePathToReal, e.g. "nodout/x_displacement") into a record batch in
+// long format. The record has the columns "time", "id" and one column per
+// variable and contains one row per timestep and id. If the database does not
+// contain ids, the index of the value is used instead.
+func BinoutRecord(mem memory.Allocator, binFile dro.Binout, simplePaths ...string) (arrow.Record, error) {
+	if len(simplePaths) == 0 {
+		return nil, errors.New("No variables have been given")
+	}
+
+	var dbPath string
+	var time []float64
+	var ids []int64
+	columns := []column{{name: "time"}, {name: "id"}}
+
+	for i, simplePath := range simplePaths {
+		realPath, _, _, err := binFile.SimplePathToReal(simplePath)
+		if err != nil {
+			return nil, err
+		}
+
+		dir, variable := path.Split(realPath)
+		dir = path.Clean(dir)
+		if i == 0 {
+			dbPath = dir
+		} else if dir != dbPath {
+			return nil, fmt.Errorf("The variable \"%s\" is not part of \"%s\"", simplePath, dbPath)
+		}
+
+		series, err := binFile.ReadSeries(realPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if i == 0 {
+			if len(series) != 0 {
+				time = series[0].Time
+			}
+
+			ids, err = binFile.ReadIDs(dbPath)
+			if err != nil || len(ids) != len(series) {
+				ids = make([]int64, len(series))
+				for j := range ids {
+					ids[j] = int64(j)
+				}
+			}
+
+			columns[0].floats = make([]float64, 0, len(time)*len(ids))
+			columns[1].ints = make([]int64, 0, len(time)*len(ids))
+			for t := range time {
+				for _, id := range ids {
+					columns[0].floats = append(columns[0].floats, time[t])
+					columns[1].ints = append(columns[1].ints, id)
+				}
+			}
+		} else if len(series) != len(ids) {
+			return nil, fmt.Errorf("The variable \"%s\" has %d values instead of %d", simplePath, len(series), len(ids))
+		}
+
+		values := make([]float64, len(time)*len(ids))
+		for j, s := range series {
+			if len(s.Values) != len(time) {
+				return nil, fmt.Errorf("The variable \"%s\" has %d timesteps instead of %d", simplePath, len(s.Values), len(time))
+			}
+			for t, v := range s.Values {
+				values[t*len(ids)+j] = v
+			}
+		}
+		columns = append(columns, column{name: variable, floats: values})
+	}
+
+	return newRecord(mem, columns), nil
+}
+
+// D3plotRecord converts one field (D3plotNodes, D3plotSolids, ...) of one
+// state of a d3plot into a record batch. The record has the columns "state",
+// "time", "id" and one column per component of the field and contains one
+// row per node or element. The velocities and accelerations of the nodes are
+// only part of the record if the d3plot contains them (see
+// D3plot.HasNodeVelocity). A read error or a field with the wrong number of
+// values is returned as an error.
+func D3plotRecord(mem memory.Allocator, plotFile dro.D3plot, state uint64, field int) (arrow.Record, error) {
+	time, err := plotFile.ReadTime(state)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uint64
+	var columns []column
+
+	switch field {
+	case D3plotNodes:
+		ids, err = plotFile.ReadNodeIDs()
+		if err != nil {
+			return nil, err
+		}
+		coords, err := plotFile.ReadNodeCoordinates(state)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, vectorColumns(coords, "x", "y", "z")...)
+
+		// Velocities and accelerations are only exported if the d3plot contains them
+		if plotFile.HasNodeVelocity() {
+			vel, err := plotFile.ReadNodeVelocity(state)
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, vectorColumns(vel, "vx", "vy", "vz")...)
+		}
+		if plotFile.HasNodeAcceleration() {
+			acc, err := plotFile.ReadNodeAcceleration(state)
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, vectorColumns(acc, "ax", "ay", "az")...)
+		}
+	case D3plotSolids:
+		ids, err = plotFile.ReadSolidElementIDs()
+		if err != nil {
+			return nil, err
+		}
+		solids, err := plotFile.ReadSolidStates(state)
+		if err != nil {
+			return nil, err
+		}
+
+		stress := make([]dro.D3plotTensor, len(solids))
+		strain := make([]dro.D3plotTensor, len(solids))
+		plasticStrain := make([]float64, len(solids))
+		for i, s := range solids {
+			stress[i], strain[i], plasticStrain[i] = s.Stress, s.Strain, s.EffectivePlasticStrain
+		}
+		columns = append(columns, tensorColumns(stress, "sig")...)
+		columns = append(columns, column{name: "effective_plastic_strain", floats: plasticStrain})
+		columns = append(columns, tensorColumns(strain, "eps")...)
+	case D3plotThickShells:
+		ids, err = plotFile.ReadThickShellElementIDs()
+		if err != nil {
+			return nil, err
+		}
+		thickShells, err := plotFile.ReadThickShellStates(state)
+		if err != nil {
+			return nil, err
+		}
+
+		surfaces := make([][3]dro.D3plotSurfaceState, len(thickShells))
+		for i, s := range thickShells {
+			surfaces[i] = [3]dro.D3plotSurfaceState{s.Mid, s.Inner, s.Outer}
+		}
+		columns = append(columns, surfaceColumns(surfaces)...)
+	case D3plotShells:
+		ids, err = plotFile.ReadShellElementIDs()
+		if err != nil {
+			return nil, err
+		}
+		shells, err := plotFile.ReadShellStates(state)
+		if err != nil {
+			return nil, err
+		}
+
+		surfaces := make([][3]dro.D3plotSurfaceState, len(shells))
+		thickness := make([]float64, len(shells))
+		internalEnergy := make([]float64, len(shells))
+		for i, s := range shells {
+			surfaces[i] = [3]dro.D3plotSurfaceState{s.Mid, s.Inner, s.Outer}
+			thickness[i], internalEnergy[i] = s.Thickness, s.InternalEnergy
+		}
+		columns = append(columns, surfaceColumns(surfaces)...)
+		columns = append(columns,
+			column{name: "thickness", floats: thickness},
+			column{name: "internal_energy", floats: internalEnergy},
+		)
+	case D3plotBeams:
+		ids, err = plotFile.ReadBeamElementIDs()
+		if err != nil {
+			return nil, err
+		}
+		beams, err := plotFile.ReadBeamStates(state)
+		if err != nil {
+			return nil, err
+		}
+
+		names := [6]string{"axial_force", "s_shear_resultant", "t_shear_resultant", "s_bending_moment", "t_bending_moment", "torsional_resultant"}
+		for c, name := range names {
+			values := make([]float64, len(beams))
+			for i, b := range beams {
+				values[i] = [6]float64{b.AxialForce, b.SShearResultant, b.TShearResultant, b.SBendingMoment, b.TBendingMoment, b.TorsionalResultant}[c]
+			}
+			columns = append(columns, column{name: name, floats: values})
+		}
+	default:
+		return nil, fmt.Errorf("Invalid d3plot field %d", field)
+	}
+
+	numRows := len(ids)
+	for _, c := range columns {
+		if len(c.floats) != numRows {
+			return nil, fmt.Errorf("The column \"%s\" has %d rows instead of %d", c.name, len(c.floats), numRows)
+		}
+	}
+
+	header := []column{
+		{name: "state", ints: make([]int64, numRows)},
+		{name: "time", floats: make([]float64, numRows)},
+		{name: "id", ints: make([]int64, numRows)},
+	}
+	for i, id := range ids {
+		header[0].ints[i] = int64(state)
+		header[1].floats[i] = time
+		header[2].ints[i] = int64(id)
+	}
+
+	return newRecord(mem, append(header, columns...)), nil
+}
+
+// WriteParquet writes record batches which share the same schema as one
+// Parquet file. Every record is written as its own row group.
+func WriteParquet(w io.Writer, records ...arrow.Record) error {
+	if len(records) == 0 {
+		return errors.New("No records have been given")
+	}
+
+	writer, err := newParquetWriter(w, records[0].Schema())
+	if err != nil {
+		return err
+	}
+
+	for _, rec := range records {
+		if err := writer.Write(rec); err != nil {
+			writer.Close()
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+// BinoutToParquet is the same as BinoutRecord, but writes the record as a
+// Parquet file into w
+func BinoutToParquet(w io.Writer, binFile dro.Binout, simplePaths ...string) error {
+	rec, err := BinoutRecord(memory.DefaultAllocator, binFile, simplePaths...)
+	if err != nil {
+		return err
+	}
+	defer rec.Release()
+
+	return WriteParquet(w, rec)
+}
+
+// D3plotToParquet writes one field of all states of a d3plot as a Parquet file
+// into w. The states are converted one at a time so that only one state needs
+// to fit into memory.
+func D3plotToParquet(w io.Writer, plotFile dro.D3plot, field int) error {
+	var writer *pqarrow.FileWriter
+
+	for state := uint64(0); state < plotFile.NumTimeSteps(); state++ {
+		rec, err := D3plotRecord(memory.DefaultAllocator, plotFile, state, field)
+		if err != nil {
+			if writer != nil {
+				writer.Close()
+			}
+			return err
+		}
+
+		if writer == nil {
+			writer, err = newParquetWriter(w, rec.Schema())
+			if err != nil {
+				rec.Release()
+				return err
+			}
+		}
+
+		err = writer.Write(rec)
+		rec.Release()
+		if err != nil {
+			writer.Close()
+			return err
+		}
+	}
+
+	if writer == nil {
+		return errors.New("The d3plot does not contain any states")
+	}
+	return writer.Close()
+}
+
+func newParquetWriter(w io.Writer, schema *arrow.Schema) (*pqarrow.FileWriter, error) {
+	props := parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Snappy))
+	return pqarrow.NewFileWriter(schema, w, props, pqarrow.DefaultWriterProps())
+}
+
+func newRecord(mem memory.Allocator, columns []column) arrow.Record {
+	fields := make([]arrow.Field, len(columns))
+	for i, c := range columns {
+		if c.ints != nil {
+			fields[i] = arrow.Field{Name: c.name, Type: arrow.PrimitiveTypes.Int64}
+		} else {
+			fields[i] = arrow.Field{Name: c.name, Type: arrow.PrimitiveTypes.Float64}
+		}
+	}
+
+	builder := array.NewRecordBuilder(mem, arrow.NewSchema(fields, nil))
+	defer builder.Release()
+
+	for i, c := range columns {
+		if c.ints != nil {
+			builder.Field(i).(*array.Int64Builder).AppendValues(c.ints, nil)
+		} else {
+			builder.Field(i).(*array.Float64Builder).AppendValues(c.floats, nil)
+		}
+	}
+
+	return builder.NewRecord()
+}
+
+func vectorColumns(vec [][3]float64, x, y, z string) []column {
+	columns := []column{
+		{name: x, floats: make([]float64, len(vec))},
+		{name: y, floats: make([]float64, len(vec))},
+		{name: z, floats: make([]float64, len(vec))},
+	}
+	for i, v := range vec {
+		for c := range columns {
+			columns[c].floats[i] = v[c]
+		}
+	}
+	return columns
+}
+
+func tensorColumns(tensors []dro.D3plotTensor, prefix string) []column {
+	names := [6]string{"xx", "yy", "zz", "xy", "yz", "zx"}
+	columns := make([]column, len(names))
+	for c, name := range names {
+		columns[c] = column{name: prefix + "_" + name, floats: make([]float64, len(tensors))}
+		for i, t := range tensors {
+			columns[c].floats[i] = t[c]
+		}
+	}
+	return columns
+}
+
+func surfaceColumns(surfaces [][3]dro.D3plotSurfaceState) []column {
+	var columns []column
+	for s, name := range [3]string{"mid", "inner", "outer"} {
+		stress := make([]dro.D3plotTensor, len(surfaces))
+		plasticStrain := make([]float64, len(surfaces))
+		for i := range surfaces {
+			stress[i] = surfaces[i][s].Stress
+			plasticStrain[i] = surfaces[i][s].EffectivePlasticStrain
+		}
+
+		columns = append(columns, tensorColumns(stress, name+"_sig")...)
+		columns = append(columns, column{name: name + "_effective_plastic_strain", floats: plasticStrain})
+	}
+	return columns
+}
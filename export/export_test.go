@@ -0,0 +1,290 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dro "github.com/PucklaJ/dynareadout_go"
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteParquet(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	rec := newRecord(mem, []column{
+		{name: "time", floats: []float64{0.0, 0.0, 1.0, 1.0}},
+		{name: "id", ints: []int64{10, 20, 10, 20}},
+		{name: "x_displacement", floats: []float64{0.0, 0.0, 0.5, 0.25}},
+	})
+	defer rec.Release()
+
+	var buffer bytes.Buffer
+	if !assert.Nil(t, WriteParquet(&buffer, rec, rec)) {
+		return
+	}
+
+	reader, err := file.NewParquetReader(bytes.NewReader(buffer.Bytes()))
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer reader.Close()
+
+	assert.Equal(t, 2, reader.NumRowGroups())
+	assert.Equal(t, int64(8), reader.NumRows())
+
+	fileReader, err := pqarrow.NewFileReader(reader, pqarrow.ArrowReadProperties{}, mem)
+	if !assert.Nil(t, err) {
+		return
+	}
+	table, err := fileReader.ReadTable(context.Background())
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer table.Release()
+
+	schema := table.Schema()
+	if assert.Equal(t, 3, len(schema.Fields())) {
+		assert.Equal(t, "id", schema.Field(1).Name)
+		assert.Equal(t, arrow.PrimitiveTypes.Int64, schema.Field(1).Type)
+	}
+}
+
+func TestBinoutRecord(t *testing.T) {
+	binFile, ok := openTestBinout(t)
+	if !ok {
+		return
+	}
+	defer binFile.Close()
+
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	rec, err := BinoutRecord(mem, binFile, "nodout/x_displacement", "nodout/y_displacement")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer rec.Release()
+
+	assert.Equal(t, []string{"time", "id", "x_displacement", "y_displacement"}, fieldNames(rec.Schema()))
+	assert.Equal(t, []float64{0, 0, 0, 0.5, 0.5, 0.5, 1, 1, 1}, rec.Column(0).(*array.Float64).Float64Values())
+	assert.Equal(t, []int64{10, 20, 30, 10, 20, 30, 10, 20, 30}, rec.Column(1).(*array.Int64).Int64Values())
+	assert.Equal(t, []float64{0, 0, 0, 1, 2, 3, 2, 4, 6}, rec.Column(2).(*array.Float64).Float64Values())
+	assert.Equal(t, []float64{0, 0, 0, -1, -2, -3, -2, -4, -6}, rec.Column(3).(*array.Float64).Float64Values())
+
+	_, err = BinoutRecord(mem, binFile, "nodout/x_displacement", "glstat/kinetic_energy")
+	assert.NotNil(t, err)
+	_, err = BinoutRecord(mem, binFile)
+	assert.NotNil(t, err)
+}
+
+func TestBinoutToParquet(t *testing.T) {
+	binFile, ok := openTestBinout(t)
+	if !ok {
+		return
+	}
+	defer binFile.Close()
+
+	var buffer bytes.Buffer
+	if !assert.Nil(t, BinoutToParquet(&buffer, binFile, "nodout/x_displacement")) {
+		return
+	}
+
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	table, ok := readTestParquet(t, mem, buffer.Bytes())
+	if !ok {
+		return
+	}
+	defer table.Release()
+
+	assert.Equal(t, []string{"time", "id", "x_displacement"}, fieldNames(table.Schema()))
+	assert.Equal(t, []float64{0, 0, 0, 0.5, 0.5, 0.5, 1, 1, 1}, float64Column(table, 0))
+	assert.Equal(t, []int64{10, 20, 30, 10, 20, 30, 10, 20, 30}, int64Column(table, 1))
+	assert.Equal(t, []float64{0, 0, 0, 1, 2, 3, 2, 4, 6}, float64Column(table, 2))
+}
+
+func TestD3plotRecord(t *testing.T) {
+	plotFile, ok := openTestD3plot(t)
+	if !ok {
+		return
+	}
+	defer plotFile.Close()
+
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	rec, err := D3plotRecord(mem, plotFile, 1, D3plotNodes)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer rec.Release()
+
+	assert.Equal(t, []string{"state", "time", "id", "x", "y", "z", "vx", "vy", "vz"}, fieldNames(rec.Schema()))
+	assert.Equal(t, []int64{1, 1}, rec.Column(0).(*array.Int64).Int64Values())
+	assert.Equal(t, []float64{0.5, 0.5}, rec.Column(1).(*array.Float64).Float64Values())
+	assert.Equal(t, []int64{101, 102}, rec.Column(2).(*array.Int64).Int64Values())
+	assert.Equal(t, []float64{0.5, 1.5}, rec.Column(3).(*array.Float64).Float64Values())
+	assert.Equal(t, []float64{1, 2}, rec.Column(6).(*array.Float64).Float64Values())
+
+	_, err = D3plotRecord(mem, plotFile, 2, D3plotNodes)
+	assert.ErrorIs(t, err, dro.ErrStateOutOfRange)
+	_, err = D3plotRecord(mem, plotFile, 0, D3plotBeams+1)
+	assert.NotNil(t, err)
+}
+
+func TestD3plotToParquet(t *testing.T) {
+	plotFile, ok := openTestD3plot(t)
+	if !ok {
+		return
+	}
+	defer plotFile.Close()
+
+	var buffer bytes.Buffer
+	if !assert.Nil(t, D3plotToParquet(&buffer, plotFile, D3plotNodes)) {
+		return
+	}
+
+	reader, err := file.NewParquetReader(bytes.NewReader(buffer.Bytes()))
+	if !assert.Nil(t, err) {
+		return
+	}
+	// One row group per state
+	assert.Equal(t, 2, reader.NumRowGroups())
+	reader.Close()
+
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	table, ok := readTestParquet(t, mem, buffer.Bytes())
+	if !ok {
+		return
+	}
+	defer table.Release()
+
+	assert.Equal(t, []int64{0, 0, 1, 1}, int64Column(table, 0))
+	assert.Equal(t, []float64{0, 0, 0.5, 0.5}, float64Column(table, 1))
+	assert.Equal(t, []int64{101, 102, 101, 102}, int64Column(table, 2))
+	assert.Equal(t, []float64{0, 1, 0.5, 1.5}, float64Column(table, 3))
+	assert.Equal(t, []float64{0, 0, 1, 2}, float64Column(table, 6))
+}
+
+// openTestBinout writes and opens a binout with the three nodes 10, 20 and 30
+// and three timesteps of nodout and glstat
+func openTestBinout(t *testing.T) (dro.Binout, bool) {
+	fileName := filepath.Join(t.TempDir(), "binout")
+
+	writer, err := dro.BinoutCreate(fileName)
+	if !assert.Nil(t, err) {
+		return dro.Binout{}, false
+	}
+
+	assert.Nil(t, writer.Cd("/nodout/metadata"))
+	assert.Nil(t, writer.WriteInt32("ids", []int32{10, 20, 30}))
+	for i := 0; i < 3; i++ {
+		time := float64(i) * 0.5
+		_, err = writer.BeginTimestep("/nodout", time)
+		assert.Nil(t, err)
+		assert.Nil(t, writer.WriteFloat32("x_displacement", []float32{float32(i), float32(2 * i), float32(3 * i)}))
+		assert.Nil(t, writer.WriteFloat32("y_displacement", []float32{float32(-i), float32(-2 * i), float32(-3 * i)}))
+
+		_, err = writer.BeginTimestep("/glstat", time)
+		assert.Nil(t, err)
+		assert.Nil(t, writer.WriteFloat64("kinetic_energy", []float64{time * 10.0}))
+	}
+	if !assert.Nil(t, writer.Close()) {
+		return dro.Binout{}, false
+	}
+
+	binFile, err := dro.BinoutOpen(fileName)
+	return binFile, assert.Nil(t, err)
+}
+
+// openTestD3plot writes and opens a single precision d3plot with the two nodes
+// 101 and 102 and two states containing coordinates and velocities
+func openTestD3plot(t *testing.T) (dro.D3plot, bool) {
+	var control [64]int32
+	control[11] = 1  // FILETYPE
+	control[15] = 4  // NDIM
+	control[16] = 2  // NUMNP
+	control[17] = 6  // ICODE
+	control[18] = 6  // NGLBV
+	control[20] = 1  // IU
+	control[21] = 1  // IV
+	control[39] = 18 // NARBS
+
+	userIDs := [16]int32{-1} // NSORT
+	userIDs[5] = 2           // NSORTD
+
+	var root, states bytes.Buffer
+	for _, value := range []any{control, [6]float32{0, 0, 0, 1, 0, 0}, userIDs, [2]int32{101, 102}, float32(-999999.0), float32(-999999.0)} {
+		binary.Write(&root, binary.LittleEndian, value)
+	}
+	for i := 0; i < 2; i++ {
+		time := float32(i) * 0.5
+		coords := [6]float32{time, 0, 0, 1 + time, 0, 0}
+		vel := [6]float32{float32(i), 0, 0, float32(2 * i), 0, 0}
+		for _, value := range []any{time, [6]float32{}, coords, vel} {
+			binary.Write(&states, binary.LittleEndian, value)
+		}
+	}
+	binary.Write(&states, binary.LittleEndian, float32(-999999.0))
+
+	fileName := filepath.Join(t.TempDir(), "d3plot")
+	if !assert.Nil(t, os.WriteFile(fileName, root.Bytes(), 0o644)) ||
+		!assert.Nil(t, os.WriteFile(fileName+"01", states.Bytes(), 0o644)) {
+		return dro.D3plot{}, false
+	}
+
+	plotFile, err := dro.D3plotOpen(fileName)
+	return plotFile, assert.Nil(t, err)
+}
+
+func readTestParquet(t *testing.T, mem memory.Allocator, data []byte) (arrow.Table, bool) {
+	reader, err := file.NewParquetReader(bytes.NewReader(data))
+	if !assert.Nil(t, err) {
+		return nil, false
+	}
+	defer reader.Close()
+
+	fileReader, err := pqarrow.NewFileReader(reader, pqarrow.ArrowReadProperties{}, mem)
+	if !assert.Nil(t, err) {
+		return nil, false
+	}
+	table, err := fileReader.ReadTable(context.Background())
+	return table, assert.Nil(t, err)
+}
+
+func fieldNames(schema *arrow.Schema) []string {
+	names := make([]string, len(schema.Fields()))
+	for i, field := range schema.Fields() {
+		names[i] = field.Name
+	}
+	return names
+}
+
+func float64Column(table arrow.Table, i int) []float64 {
+	var values []float64
+	for _, chunk := range table.Column(i).Data().Chunks() {
+		values = append(values, chunk.(*array.Float64).Float64Values()...)
+	}
+	return values
+}
+
+func int64Column(table arrow.Table, i int) []int64 {
+	var values []int64
+	for _, chunk := range table.Column(i).Data().Chunks() {
+		values = append(values, chunk.(*array.Int64).Int64Values()...)
+	}
+	return values
+}
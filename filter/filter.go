@@ -0,0 +1,153 @@
+// Package filter implements the channel frequency class (CFC) filter of SAE
+// J211-1 / ISO 6487 for time series read from binout and d3plot files.
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	dro "github.com/PucklaJ/dynareadout_go"
+)
+
+// The standard channel frequency classes
+const (
+	CFC60   = 60.0
+	CFC180  = 180.0
+	CFC600  = 600.0
+	CFC1000 = 1000.0
+)
+
+// The relative deviation of a time step from the mean time step above which a
+// series is considered to be irregularly sampled
+const irregularTolerance = 1e-3
+
+// The duration of the padding in seconds which is added to both ends of a
+// series to let the filter settle (SAE J211 recommends at least 10ms)
+const defaultPaddingDuration = 0.01
+
+type Options struct {
+	// The length of one time unit of the series in seconds (e.g. 1e-3 if the
+	// time is given in milliseconds). Zero means seconds.
+	TimeUnit float64
+	// The number of samples which are mirrored at both ends of the series.
+	// Zero means 10ms worth of samples.
+	Padding int
+}
+
+// CFC filters the series with the phaseless 4-pole Butterworth filter of SAE
+// J211-1 Appendix C. Series with an irregular time step are resampled onto a
+// uniform time step before filtering, which means that the returned series
+// may have a different time base than ts.
+func CFC(ts dro.TimeSeries, cfc float64, opts Options) (dro.TimeSeries, error) {
+	if cfc <= 0 {
+		return dro.TimeSeries{}, fmt.Errorf("Invalid channel frequency class %g", cfc)
+	}
+	if len(ts.Time) != len(ts.Values) {
+		return dro.TimeSeries{}, fmt.Errorf("The series \"%s\" has %d time points but %d values", ts.Label, len(ts.Time), len(ts.Values))
+	}
+	if len(ts.Time) < 3 {
+		return dro.TimeSeries{}, fmt.Errorf("The series \"%s\" needs at least 3 samples to be filtered", ts.Label)
+	}
+
+	timeUnit := opts.TimeUnit
+	if timeUnit == 0 {
+		timeUnit = 1
+	}
+
+	uniform, err := Uniform(ts)
+	if err != nil {
+		return dro.TimeSeries{}, err
+	}
+
+	step := (uniform.Time[1] - uniform.Time[0]) * timeUnit
+
+	padding := opts.Padding
+	if padding == 0 {
+		padding = int(math.Ceil(defaultPaddingDuration / step))
+	}
+
+	uniform.Values = Butterworth(uniform.Values, step, cfc, padding)
+	return uniform, nil
+}
+
+// Uniform returns ts unchanged if its time step is constant and otherwise
+// linearly resamples it onto a uniform time step, which is the mean time step
+// of ts
+func Uniform(ts dro.TimeSeries) (dro.TimeSeries, error) {
+	n := len(ts.Time)
+	if n < 2 {
+		return ts, nil
+	}
+
+	meanStep := (ts.Time[n-1] - ts.Time[0]) / float64(n-1)
+	if meanStep <= 0 {
+		return dro.TimeSeries{}, errors.New("The time of the series needs to be ascending")
+	}
+
+	for i := 1; i < n; i++ {
+		step := ts.Time[i] - ts.Time[i-1]
+		if math.Abs(step-meanStep) > irregularTolerance*meanStep {
+			return ts.ResampleUniform(meanStep, dro.InterpolationLinear), nil
+		}
+	}
+
+	return ts, nil
+}
+
+// Butterworth applies the 2-pole Butterworth filter of SAE J211-1 forward and
+// backward onto uniformly sampled values. step is the sample time step in
+// seconds. padding samples are mirrored at both ends to reduce the start up
+// transients of the filter.
+func Butterworth(values []float64, step, cfc float64, padding int) []float64 {
+	n := len(values)
+	if n < 3 {
+		return append([]float64(nil), values...)
+	}
+
+	if padding > n-1 {
+		padding = n - 1
+	}
+	if padding < 0 {
+		padding = 0
+	}
+
+	// Mirror the ends around the first and last value
+	padded := make([]float64, n+2*padding)
+	for i := 0; i < padding; i++ {
+		padded[i] = 2*values[0] - values[padding-i]
+		padded[n+padding+i] = 2*values[n-1] - values[n-2-i]
+	}
+	copy(padded[padding:], values)
+
+	wd := 2.0 * math.Pi * cfc * 2.0775
+	wa := math.Tan(wd * step / 2.0)
+	denom := 1.0 + math.Sqrt2*wa + wa*wa
+	a0 := wa * wa / denom
+	a1 := 2.0 * a0
+	a2 := a0
+	b1 := -2.0 * (wa*wa - 1.0) / denom
+	b2 := (-1.0 + math.Sqrt2*wa - wa*wa) / denom
+
+	pass := func(x []float64) []float64 {
+		y := make([]float64, len(x))
+		y[0], y[1] = x[0], x[1]
+		for i := 2; i < len(x); i++ {
+			y[i] = a0*x[i] + a1*x[i-1] + a2*x[i-2] + b1*y[i-1] + b2*y[i-2]
+		}
+		return y
+	}
+
+	forward := pass(padded)
+	reverse(forward)
+	backward := pass(forward)
+	reverse(backward)
+
+	return backward[padding : padding+n]
+}
+
+func reverse(s []float64) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
@@ -0,0 +1,70 @@
+package filter
+
+import (
+	"math"
+	"testing"
+
+	dro "github.com/PucklaJ/dynareadout_go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCFC(t *testing.T) {
+	// 100ms sampled at 10kHz with the time given in milliseconds
+	time := dro.UniformTime(0.0, 100.0, 0.1)
+
+	constant := dro.TimeSeries{
+		Time:   time,
+		Values: make([]float64, len(time)),
+	}
+	for i := range constant.Values {
+		constant.Values[i] = 5.0
+	}
+
+	filtered, err := CFC(constant, CFC60, Options{TimeUnit: 1e-3})
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, v := range filtered.Values {
+		assert.InDelta(t, 5.0, v, 1e-9)
+	}
+
+	// A 10Hz signal passes CFC180 while a 2kHz signal is attenuated
+	signal := dro.TimeSeries{
+		Time:   time,
+		Values: make([]float64, len(time)),
+		Unit:   "g",
+	}
+	for i, ti := range time {
+		s := ti * 1e-3
+		signal.Values[i] = math.Sin(2*math.Pi*10*s) + math.Sin(2*math.Pi*2000*s)
+	}
+
+	filtered, err = CFC(signal, CFC180, Options{TimeUnit: 1e-3})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "g", filtered.Unit)
+	for i := 100; i < len(time)-100; i++ {
+		s := time[i] * 1e-3
+		assert.InDelta(t, math.Sin(2*math.Pi*10*s), filtered.Values[i], 0.02)
+	}
+
+	_, err = CFC(signal, 0, Options{})
+	assert.NotNil(t, err)
+}
+
+func TestUniform(t *testing.T) {
+	ts := dro.TimeSeries{
+		Time:   []float64{0.0, 1.0, 1.5, 3.0},
+		Values: []float64{0.0, 1.0, 1.5, 3.0},
+		Unit:   "mm",
+	}
+
+	uniform, err := Uniform(ts)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, []float64{0.0, 1.0, 2.0, 3.0}, uniform.Time)
+	assert.Equal(t, []float64{0.0, 1.0, 2.0, 3.0}, uniform.Values)
+	assert.Equal(t, "mm", uniform.Unit)
+}
@@ -0,0 +1,33 @@
+package dynareadout
+
+import (
+	"fmt"
+	"runtime"
+	"sync/atomic"
+)
+
+var leakHandler atomic.Pointer[func(string)]
+
+// SetLeakHandler sets a function which is called whenever a Binout, D3plot or
+// Keywords is garbage collected without having been closed (or freed). The
+// leaked resources are freed afterwards either way. This is meant to find
+// missing calls to Close in tests. A nil handler disables the reporting.
+func SetLeakHandler(handler func(leak string)) {
+	if handler == nil {
+		leakHandler.Store(nil)
+		return
+	}
+	leakHandler.Store(&handler)
+}
+
+// setLeakFinalizer makes sure that free is called if obj is garbage collected
+// and reports the leak. It needs to be removed with runtime.SetFinalizer(obj,
+// nil) once the resources have been freed.
+func setLeakFinalizer[T any](obj *T, description string, free func(*T)) {
+	runtime.SetFinalizer(obj, func(obj *T) {
+		if handler := leakHandler.Load(); handler != nil {
+			(*handler)(fmt.Sprintf("%s has not been closed", description))
+		}
+		free(obj)
+	})
+}
@@ -0,0 +1,298 @@
+// Package injury computes occupant injury criteria (HIC, 3ms clip, chest
+// deflection, Nij and BrIC) from time series read from binout and d3plot
+// files.
+package injury
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	dro "github.com/PucklaJ/dynareadout_go"
+)
+
+type Options struct {
+	// The length of one time unit of the series in seconds (e.g. 1e-3 if the
+	// time is given in milliseconds). Zero means seconds.
+	TimeUnit float64
+}
+
+// Result is the value of a criterion together with the time window in which
+// it occurs. The times are given in the time unit of the series. For peak
+// based criteria Start and End are equal.
+type Result struct {
+	Value float64
+	Start float64
+	End   float64
+}
+
+// NijLimits are the critical intercepts of the Nij criterion
+type NijLimits struct {
+	TensionForce     float64
+	CompressionForce float64
+	FlexionMoment    float64
+	ExtensionMoment  float64
+}
+
+// The load cases of Nij
+const (
+	NijTensionExtension = iota
+	NijTensionFlexion
+	NijCompressionExtension
+	NijCompressionFlexion
+)
+
+type NijResult struct {
+	Result
+	// One of NijTensionExtension, NijTensionFlexion, ...
+	LoadCase int
+}
+
+// BrICLimits are the critical angular velocities of BrIC in rad/s
+type BrICLimits struct {
+	X float64
+	Y float64
+	Z float64
+}
+
+type BrICResult struct {
+	Value float64
+	// The time of the maximum absolute angular velocity of every axis
+	PeakTimes [3]float64
+}
+
+// The Nij intercepts of the Hybrid III 50th percentile male dummy in N and Nm
+func HybridIII50thNijLimits() NijLimits {
+	return NijLimits{
+		TensionForce:     6806.0,
+		CompressionForce: 6160.0,
+		FlexionMoment:    310.0,
+		ExtensionMoment:  135.0,
+	}
+}
+
+// The critical angular velocities of BrIC for the THOR dummy in rad/s
+func DefaultBrICLimits() BrICLimits {
+	return BrICLimits{
+		X: 66.25,
+		Y: 56.45,
+		Z: 42.87,
+	}
+}
+
+// Resultant returns the magnitude of the three components, which need to share
+// the same time base
+func Resultant(x, y, z dro.TimeSeries) (dro.TimeSeries, error) {
+	if len(x.Values) != len(y.Values) || len(x.Values) != len(z.Values) || len(x.Time) != len(x.Values) {
+		return dro.TimeSeries{}, errors.New("The components need to have the same number of values")
+	}
+
+	rv := dro.TimeSeries{
+		Time:   x.Time,
+		Values: make([]float64, len(x.Values)),
+		Unit:   x.Unit,
+	}
+	for i := range rv.Values {
+		rv.Values[i] = math.Sqrt(x.Values[i]*x.Values[i] + y.Values[i]*y.Values[i] + z.Values[i]*z.Values[i])
+	}
+	return rv, nil
+}
+
+// HIC computes the head injury criterion of the resultant acceleration in g.
+// maxWindow is the maximum length of the window in seconds (e.g. 0.015 for
+// HIC15)
+func HIC(acc dro.TimeSeries, maxWindow float64, opts Options) (Result, error) {
+	var result Result
+
+	if err := checkSeries(acc); err != nil {
+		return result, err
+	}
+	timeUnit := opts.timeUnit()
+
+	// The integral of the acceleration from the first time point
+	n := len(acc.Time)
+	integral := make([]float64, n)
+	for i := 1; i < n; i++ {
+		dt := (acc.Time[i] - acc.Time[i-1]) * timeUnit
+		integral[i] = integral[i-1] + 0.5*(acc.Values[i]+acc.Values[i-1])*dt
+	}
+
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			dt := (acc.Time[j] - acc.Time[i]) * timeUnit
+			if dt > maxWindow*(1+1e-9) {
+				break
+			}
+			if dt <= 0 {
+				continue
+			}
+
+			mean := (integral[j] - integral[i]) / dt
+			if mean <= 0 {
+				continue
+			}
+
+			hic := dt * math.Pow(mean, 2.5)
+			if hic > result.Value {
+				result.Value = hic
+				result.Start = acc.Time[i]
+				result.End = acc.Time[j]
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func HIC15(acc dro.TimeSeries, opts Options) (Result, error) {
+	return HIC(acc, 0.015, opts)
+}
+
+func HIC36(acc dro.TimeSeries, opts Options) (Result, error) {
+	return HIC(acc, 0.036, opts)
+}
+
+// Clip3ms returns the highest acceleration which is continuously exceeded
+// for at least 3ms
+func Clip3ms(acc dro.TimeSeries, opts Options) (Result, error) {
+	return Clip(acc, 0.003, opts)
+}
+
+// Clip returns the highest value which is continuously exceeded for at least
+// duration seconds
+func Clip(acc dro.TimeSeries, duration float64, opts Options) (Result, error) {
+	result := Result{Value: math.Inf(-1)}
+
+	if err := checkSeries(acc); err != nil {
+		return Result{}, err
+	}
+	timeUnit := opts.timeUnit()
+
+	found := false
+	for i := range acc.Time {
+		minimum := math.Inf(1)
+		for j := i; j < len(acc.Time); j++ {
+			minimum = math.Min(minimum, acc.Values[j])
+			if (acc.Time[j]-acc.Time[i])*timeUnit >= duration*(1-1e-9) {
+				if minimum > result.Value {
+					result.Value = minimum
+					result.Start = acc.Time[i]
+					result.End = acc.Time[j]
+				}
+				found = true
+				break
+			}
+		}
+	}
+
+	if !found {
+		return Result{}, fmt.Errorf("The series \"%s\" is shorter than %gs", acc.Label, duration)
+	}
+
+	return result, nil
+}
+
+// ChestDeflection returns the maximum deflection
+func ChestDeflection(deflection dro.TimeSeries) (Result, error) {
+	if err := checkSeries(deflection); err != nil {
+		return Result{}, err
+	}
+
+	result := Result{Value: math.Inf(-1)}
+	for i, v := range deflection.Values {
+		if v > result.Value {
+			result.Value = v
+			result.Start = deflection.Time[i]
+			result.End = deflection.Time[i]
+		}
+	}
+	return result, nil
+}
+
+// Nij computes the neck injury criterion of the axial force fz (tension
+// positive) and the moment my at the occipital condyle (flexion positive).
+// Both series need to share the same time base.
+func Nij(fz, my dro.TimeSeries, limits NijLimits) (NijResult, error) {
+	var result NijResult
+
+	if err := checkSeries(fz); err != nil {
+		return result, err
+	}
+	if len(my.Values) != len(fz.Values) {
+		return result, errors.New("The force and moment need to have the same number of values")
+	}
+
+	for i := range fz.Values {
+		f, m := fz.Values[i], my.Values[i]
+
+		var nf, nm float64
+		var loadCase int
+		if f >= 0 {
+			nf = f / limits.TensionForce
+			if m >= 0 {
+				nm, loadCase = m/limits.FlexionMoment, NijTensionFlexion
+			} else {
+				nm, loadCase = -m/limits.ExtensionMoment, NijTensionExtension
+			}
+		} else {
+			nf = -f / limits.CompressionForce
+			if m >= 0 {
+				nm, loadCase = m/limits.FlexionMoment, NijCompressionFlexion
+			} else {
+				nm, loadCase = -m/limits.ExtensionMoment, NijCompressionExtension
+			}
+		}
+
+		if nij := nf + nm; nij > result.Value {
+			result.Value = nij
+			result.Start = fz.Time[i]
+			result.End = fz.Time[i]
+			result.LoadCase = loadCase
+		}
+	}
+
+	return result, nil
+}
+
+// BrIC computes the brain injury criterion of the angular velocities in rad/s
+func BrIC(wx, wy, wz dro.TimeSeries, limits BrICLimits) (BrICResult, error) {
+	var result BrICResult
+
+	var sum float64
+	for i, w := range [3]dro.TimeSeries{wx, wy, wz} {
+		if err := checkSeries(w); err != nil {
+			return result, err
+		}
+
+		var peak float64
+		for j, v := range w.Values {
+			if math.Abs(v) > peak {
+				peak = math.Abs(v)
+				result.PeakTimes[i] = w.Time[j]
+			}
+		}
+
+		critical := [3]float64{limits.X, limits.Y, limits.Z}[i]
+		sum += (peak / critical) * (peak / critical)
+	}
+
+	result.Value = math.Sqrt(sum)
+	return result, nil
+}
+
+func (o Options) timeUnit() float64 {
+	if o.TimeUnit == 0 {
+		return 1
+	}
+	return o.TimeUnit
+}
+
+func checkSeries(ts dro.TimeSeries) error {
+	if len(ts.Time) != len(ts.Values) {
+		return fmt.Errorf("The series \"%s\" has %d time points but %d values", ts.Label, len(ts.Time), len(ts.Values))
+	}
+	if len(ts.Time) == 0 {
+		return fmt.Errorf("The series \"%s\" is empty", ts.Label)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package injury
+
+import (
+	"math"
+	"testing"
+
+	dro "github.com/PucklaJ/dynareadout_go"
+	"github.com/stretchr/testify/assert"
+)
+
+func constantPulse(level, duration float64) dro.TimeSeries {
+	// 100ms in milliseconds with a pulse starting at 10ms
+	time := dro.UniformTime(0.0, 100.0, 0.1)
+	ts := dro.TimeSeries{
+		Time:   time,
+		Values: make([]float64, len(time)),
+	}
+	for i, t := range time {
+		if t >= 10.0 && t <= 10.0+duration {
+			ts.Values[i] = level
+		}
+	}
+	return ts
+}
+
+func TestHIC(t *testing.T) {
+	// A constant pulse of 60g for 10ms has HIC = 0.01 * 60^2.5
+	acc := constantPulse(60.0, 10.0)
+
+	hic, err := HIC15(acc, Options{TimeUnit: 1e-3})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.InDelta(t, 0.01*math.Pow(60.0, 2.5), hic.Value, 1.0)
+	assert.InDelta(t, 10.0, hic.Start, 0.2)
+	assert.InDelta(t, 20.0, hic.End, 0.2)
+
+	_, err = HIC36(dro.TimeSeries{}, Options{})
+	assert.NotNil(t, err)
+}
+
+func TestResultant(t *testing.T) {
+	time := []float64{0.0, 1.0}
+	x := dro.TimeSeries{Time: time, Values: []float64{3.0, 0.0}, Unit: "g"}
+	y := dro.TimeSeries{Time: time, Values: []float64{4.0, 0.0}, Unit: "g"}
+	z := dro.TimeSeries{Time: time, Values: []float64{0.0, 2.0}, Unit: "g"}
+
+	resultant, err := Resultant(x, y, z)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, []float64{5.0, 2.0}, resultant.Values)
+	assert.Equal(t, "g", resultant.Unit)
+
+	_, err = Resultant(x, y, dro.TimeSeries{})
+	assert.NotNil(t, err)
+}
+
+func TestClip3ms(t *testing.T) {
+	acc := constantPulse(40.0, 5.0)
+	acc.Values[200] = 80.0
+
+	clip, err := Clip3ms(acc, Options{TimeUnit: 1e-3})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 40.0, clip.Value)
+	assert.InDelta(t, 3.0, clip.End-clip.Start, 1e-9)
+}
+
+func TestNijBrIC(t *testing.T) {
+	time := []float64{0.0, 1.0, 2.0}
+	fz := dro.TimeSeries{Time: time, Values: []float64{0.0, 3403.0, -6160.0}}
+	my := dro.TimeSeries{Time: time, Values: []float64{0.0, -67.5, 0.0}}
+
+	nij, err := Nij(fz, my, HybridIII50thNijLimits())
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.InDelta(t, 1.0, nij.Value, 1e-9)
+	assert.Equal(t, 1.0, nij.Start)
+	assert.Equal(t, NijTensionExtension, nij.LoadCase)
+
+	limits := DefaultBrICLimits()
+	wx := dro.TimeSeries{Time: time, Values: []float64{0.0, -limits.X, 0.0}}
+	wy := dro.TimeSeries{Time: time, Values: []float64{0.0, 0.0, 0.0}}
+	wz := dro.TimeSeries{Time: time, Values: []float64{0.0, 0.0, 0.0}}
+
+	bric, err := BrIC(wx, wy, wz, limits)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.InDelta(t, 1.0, bric.Value, 1e-9)
+	assert.Equal(t, 1.0, bric.PeakTimes[0])
+}
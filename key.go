@@ -12,10 +12,12 @@ import "C"
 
 import (
 	"bytes"
-	"errors"
+	"context"
 	"fmt"
 	"math"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"unsafe"
 )
 
@@ -27,16 +29,29 @@ const (
 	DefaultValueWidth = 10
 )
 
+// Keywords are the keywords of a parsed key file. Copies of Keywords share the
+// same memory, so freeing one of them frees all of them. The Keyword and Card
+// values which have been retrieved from them keep the memory alive and return
+// ErrClosed (or zero values) once it has been freed.
 type Keywords struct {
+	h *keywordsHandle
+}
+
+type keywordsHandle struct {
 	handle      *C.keyword_t
 	numKeywords C.size_t
+	freed       bool
 }
 
 type Keyword struct {
+	h      *keywordsHandle
 	handle *C.keyword_t
 }
 
+// Card is one card of a Keyword. The cards passed to a KeyFileParseCallback
+// are only valid during the call.
 type Card struct {
+	h      *keywordsHandle
 	handle *C.card_t
 }
 
@@ -81,16 +96,26 @@ func DefaultKeyFileParseConfig() KeyFileParseConfig {
 }
 
 func KeyFileParse(fileName string, parseConfig KeyFileParseConfig) (Keywords, *KeyFileWarning, error) {
-	var keywords Keywords
+	return KeyFileParseContext(context.Background(), fileName, parseConfig)
+}
+
+// KeyFileParseContext is the same as KeyFileParse, but stops parsing at the
+// next keyword or include file as soon as ctx is done and returns ctx.Err()
+func KeyFileParseContext(ctx context.Context, fileName string, parseConfig KeyFileParseConfig) (Keywords, *KeyFileWarning, error) {
 	var warning *KeyFileWarning
-	var errorString *C.char
+	var cErrors *C.key_parse_error_t
+	var numErrors C.size_t
 	var warningString *C.char
 
 	cParseConfig := parseConfig.toC()
 	fileNameC := C.CString(fileName)
 
-	keywords.handle = C.key_file_parse(fileNameC, &keywords.numKeywords, &cParseConfig, &errorString, &warningString)
+	keywords := Keywords{h: new(keywordsHandle)}
+	interrupt := newKeyFileInterrupt(ctx, &cParseConfig)
+	keywords.h.handle = C.key_file_parse2(fileNameC, &keywords.h.numKeywords, &cParseConfig, &cErrors, &numErrors, &warningString)
+	interrupt.stop()
 	C.free(unsafe.Pointer(fileNameC))
+	setLeakFinalizer(keywords.h, fmt.Sprintf("Keywords of \"%s\"", fileName), (*keywordsHandle).free)
 
 	if cParseConfig.extra_include_paths != nil {
 		for i := C.size_t(0); i < cParseConfig.num_extra_include_paths; i++ {
@@ -106,9 +131,11 @@ func KeyFileParse(fileName string, parseConfig KeyFileParseConfig) (Keywords, *K
 		C.free(unsafe.Pointer(warningString))
 	}
 
-	if errorString != nil {
-		err := errors.New(C.GoString(errorString))
-		C.free(unsafe.Pointer(errorString))
+	if err := keyFileError(cErrors, numErrors); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			keywords.Free()
+			return Keywords{}, warning, ctxErr
+		}
 
 		return keywords, warning, err
 	}
@@ -116,19 +143,98 @@ func KeyFileParse(fileName string, parseConfig KeyFileParseConfig) (Keywords, *K
 	return keywords, warning, nil
 }
 
+// keyFileInterrupt sets the interrupt flag of a parse config as soon as a
+// context is done
+type keyFileInterrupt struct {
+	flag *C.int
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newKeyFileInterrupt(ctx context.Context, cfg *C.key_parse_config_t) *keyFileInterrupt {
+	i := &keyFileInterrupt{}
+	if ctx.Done() == nil {
+		return i
+	}
+
+	i.flag = (*C.int)(C.calloc(1, C.size_t(unsafe.Sizeof(C.int(0)))))
+	cfg.interrupt = i.flag
+	if ctx.Err() != nil {
+		i.set()
+		return i
+	}
+
+	i.done = make(chan struct{})
+	i.wg.Add(1)
+	go func() {
+		defer i.wg.Done()
+		select {
+		case <-ctx.Done():
+			i.set()
+		case <-i.done:
+		}
+	}()
+
+	return i
+}
+
+func (i *keyFileInterrupt) set() {
+	atomic.StoreInt32((*int32)(unsafe.Pointer(i.flag)), 1)
+}
+
+// stop needs to be called after parsing
+func (i *keyFileInterrupt) stop() {
+	if i.done != nil {
+		close(i.done)
+		i.wg.Wait()
+	}
+	C.free(unsafe.Pointer(i.flag))
+}
+
+// Free frees the keywords of k and all of its copies. It can be called
+// multiple times. Get and GetSlice return ErrClosed afterwards.
 func (k *Keywords) Free() {
-	C.key_file_free(k.handle, k.numKeywords)
+	if k.h != nil {
+		k.h.free()
+	}
+}
+
+func (h *keywordsHandle) free() {
+	if !h.freed {
+		C.key_file_free(h.handle, h.numKeywords)
+		h.handle = nil
+		h.numKeywords = 0
+		h.freed = true
+	}
+	runtime.SetFinalizer(h, nil)
+}
+
+// cHandle returns the C keywords or ErrClosed if k has already been freed
+func (k Keywords) cHandle() (*keywordsHandle, error) {
+	if k.h == nil || k.h.freed {
+		return nil, ErrClosed
+	}
+	return k.h, nil
 }
 
 func (k Keywords) Len() int {
-	return int(k.numKeywords)
+	if k.h == nil {
+		return 0
+	}
+	return int(k.h.numKeywords)
 }
 
 func (k *Keywords) Get(name string, index int) (Keyword, error) {
 	var keyword Keyword
+	h, err := k.cHandle()
+	if err != nil {
+		return keyword, err
+	}
+	keyword.h = h
+
 	nameC := C.CString(name)
 
-	keyword.handle = C.key_file_get(k.handle, k.numKeywords, nameC, C.size_t(index))
+	keyword.handle = C.key_file_get(h.handle, h.numKeywords, nameC, C.size_t(index))
 	C.free(unsafe.Pointer(nameC))
 	if keyword.handle == nil {
 		return keyword, fmt.Errorf("Could not find keyword \"%s\" with index %d", name, index)
@@ -138,11 +244,16 @@ func (k *Keywords) Get(name string, index int) (Keyword, error) {
 }
 
 func (k *Keywords) GetSlice(name string) ([]Keyword, error) {
+	h, err := k.cHandle()
+	if err != nil {
+		return nil, err
+	}
+
 	var sliceSize C.size_t
 	var keywordC *C.keyword_t
 	nameC := C.CString(name)
 
-	keywordC = C.key_file_get_slice(k.handle, k.numKeywords, nameC, &sliceSize)
+	keywordC = C.key_file_get_slice(h.handle, h.numKeywords, nameC, &sliceSize)
 	C.free(unsafe.Pointer(nameC))
 	if keywordC == nil {
 		return nil, fmt.Errorf("Could not find keyword \"%s\"", name)
@@ -152,76 +263,136 @@ func (k *Keywords) GetSlice(name string) ([]Keyword, error) {
 
 	for i := 0; i < int(sliceSize); i++ {
 		sliceElement := (*C.keyword_t)(unsafe.Pointer(uintptr(unsafe.Pointer(keywordC)) + (uintptr(i) * unsafe.Sizeof(*keywordC))))
+		keywords[i].h = h
 		keywords[i].handle = sliceElement
 	}
 
 	return keywords, nil
 }
 
+// cHandle returns the C keyword or ErrClosed if the keywords have been freed
+func (k Keyword) cHandle() (*C.keyword_t, error) {
+	if k.handle == nil || (k.h != nil && k.h.freed) {
+		return nil, ErrClosed
+	}
+	return k.handle, nil
+}
+
 func (k Keyword) Len() int {
-	return int(k.handle.num_cards)
+	handle, err := k.cHandle()
+	if err != nil {
+		return 0
+	}
+	return int(handle.num_cards)
 }
 
-func (k *Keyword) Get(index int) Card {
-	if index < 0 || index >= k.Len() {
-		panic("Index out of range")
+// Get returns the card at index. Returns ErrClosed if the keywords have been
+// freed.
+func (k *Keyword) Get(index int) (Card, error) {
+	card := Card{h: k.h}
+	handle, err := k.cHandle()
+	if err != nil {
+		return card, err
 	}
 
-	var card Card
-	card.handle = (*C.card_t)(unsafe.Pointer(uintptr(unsafe.Pointer(k.handle.cards)) + (uintptr(index) * unsafe.Sizeof(*k.handle.cards))))
-	return card
+	if index < 0 || index >= int(handle.num_cards) {
+		return card, fmt.Errorf("The card index %d is out of range [0, %d)", index, handle.num_cards)
+	}
+
+	card.handle = (*C.card_t)(unsafe.Pointer(uintptr(unsafe.Pointer(handle.cards)) + (uintptr(index) * unsafe.Sizeof(*handle.cards))))
+	return card, nil
 }
 
-func (k *Keyword) GetSlice() []Card {
-	cards := make([]Card, k.Len())
+// GetSlice returns all cards of the keyword. Returns ErrClosed if the keywords
+// have been freed.
+func (k *Keyword) GetSlice() ([]Card, error) {
+	handle, err := k.cHandle()
+	if err != nil {
+		return nil, err
+	}
+
+	cards := make([]Card, handle.num_cards)
 
 	for i := 0; i < len(cards); i++ {
-		cards[i].handle = (*C.card_t)(unsafe.Pointer(uintptr(unsafe.Pointer(k.handle.cards)) + (uintptr(i) * unsafe.Sizeof(*k.handle.cards))))
+		cards[i].h = k.h
+		cards[i].handle = (*C.card_t)(unsafe.Pointer(uintptr(unsafe.Pointer(handle.cards)) + (uintptr(i) * unsafe.Sizeof(*handle.cards))))
 	}
 
-	return cards
+	return cards, nil
+}
+
+// cHandle returns the C card or ErrClosed if the keywords have been freed.
+// The keywords need to be kept alive with runtime.KeepAlive(c.h) until the
+// C card is not used anymore.
+func (c Card) cHandle() (*C.card_t, error) {
+	if c.handle == nil || (c.h != nil && c.h.freed) {
+		return nil, ErrClosed
+	}
+	return c.handle, nil
 }
 
 func (c *Card) ParseBegin(valueWidth int) {
-	C.card_parse_begin(c.handle, C.uint8_t(valueWidth))
+	if handle, err := c.cHandle(); err == nil {
+		C.card_parse_begin(handle, C.uint8_t(valueWidth))
+		runtime.KeepAlive(c.h)
+	}
 }
 
 func (c *Card) ParseNext() {
-	C.card_parse_next(c.handle)
+	if handle, err := c.cHandle(); err == nil {
+		C.card_parse_next(handle)
+		runtime.KeepAlive(c.h)
+	}
 }
 
 func (c *Card) ParseNextWidth(valueWidth int) {
-	C.card_parse_next_width(c.handle, C.uint8_t(valueWidth))
+	if handle, err := c.cHandle(); err == nil {
+		C.card_parse_next_width(handle, C.uint8_t(valueWidth))
+		runtime.KeepAlive(c.h)
+	}
 }
 
 func (c Card) ParseDone() bool {
-	return C.card_parse_done(c.handle) != 0
+	handle, err := c.cHandle()
+	if err != nil {
+		return true
+	}
+	defer runtime.KeepAlive(c.h)
+	return C.card_parse_done(handle) != 0
 }
 
-func (c Card) currentString() string {
+// cardCurrentString returns the current value of handle, which has been
+// returned by Card.cHandle
+func cardCurrentString(handle *C.card_t) string {
 	var buf []byte
 	buffer := bytes.NewBuffer(buf)
-	for i := c.handle.current_index; i < c.handle.current_index+c.handle.value_width; i++ {
-		b := *(*C.char)(unsafe.Pointer(uintptr(unsafe.Pointer(c.handle.string)) + uintptr(i)))
+	for i := handle.current_index; i < handle.current_index+handle.value_width; i++ {
+		b := *(*C.char)(unsafe.Pointer(uintptr(unsafe.Pointer(handle.string)) + uintptr(i)))
 		buffer.WriteByte(byte(b))
 	}
 	return buffer.String()
 }
 
-func (c Card) currentStringWidth(valueWidth int) string {
+func cardCurrentStringWidth(handle *C.card_t, valueWidth int) string {
 	var buf []byte
 	buffer := bytes.NewBuffer(buf)
-	for i := c.handle.current_index; i < c.handle.current_index+C.uint8_t(valueWidth); i++ {
-		b := *(*C.char)(unsafe.Pointer(uintptr(unsafe.Pointer(c.handle.string)) + uintptr(i)))
+	for i := handle.current_index; i < handle.current_index+C.uint8_t(valueWidth); i++ {
+		b := *(*C.char)(unsafe.Pointer(uintptr(unsafe.Pointer(handle.string)) + uintptr(i)))
 		buffer.WriteByte(byte(b))
 	}
 	return buffer.String()
 }
 
 func (c Card) ParseInt() (int, error) {
-	intC := C.card_parse_int(c.handle)
+	handle, err := c.cHandle()
+	if err != nil {
+		return 0, err
+	}
+	defer runtime.KeepAlive(c.h)
+
+	intC := C.card_parse_int(handle)
 	if C.get_errno() != 0 {
-		errStr := c.currentString()
+		errStr := cardCurrentString(handle)
 
 		return 0, fmt.Errorf("Failed to parse \"%s\" as int", errStr)
 	}
@@ -229,9 +400,15 @@ func (c Card) ParseInt() (int, error) {
 }
 
 func (c Card) ParseIntWidth(valueWidth int) (int, error) {
-	intC := C.card_parse_int_width(c.handle, C.uint8_t(valueWidth))
+	handle, err := c.cHandle()
+	if err != nil {
+		return 0, err
+	}
+	defer runtime.KeepAlive(c.h)
+
+	intC := C.card_parse_int_width(handle, C.uint8_t(valueWidth))
 	if C.get_errno() != 0 {
-		errStr := c.currentStringWidth(valueWidth)
+		errStr := cardCurrentStringWidth(handle, valueWidth)
 
 		return 0, fmt.Errorf("Failed to parse \"%s\" as int", errStr)
 	}
@@ -239,9 +416,15 @@ func (c Card) ParseIntWidth(valueWidth int) (int, error) {
 }
 
 func (c Card) ParseFloat32() (float32, error) {
-	floatC := C.card_parse_float32(c.handle)
+	handle, err := c.cHandle()
+	if err != nil {
+		return 0, err
+	}
+	defer runtime.KeepAlive(c.h)
+
+	floatC := C.card_parse_float32(handle)
 	if C.get_errno() != 0 {
-		errStr := c.currentString()
+		errStr := cardCurrentString(handle)
 
 		return 0, fmt.Errorf("Failed to parse \"%s\" as float32", errStr)
 	}
@@ -249,9 +432,15 @@ func (c Card) ParseFloat32() (float32, error) {
 }
 
 func (c Card) ParseFloat32Width(valueWidth int) (float32, error) {
-	floatC := C.card_parse_float32_width(c.handle, C.uint8_t(valueWidth))
+	handle, err := c.cHandle()
+	if err != nil {
+		return 0, err
+	}
+	defer runtime.KeepAlive(c.h)
+
+	floatC := C.card_parse_float32_width(handle, C.uint8_t(valueWidth))
 	if C.get_errno() != 0 {
-		errStr := c.currentStringWidth(valueWidth)
+		errStr := cardCurrentStringWidth(handle, valueWidth)
 
 		return 0, fmt.Errorf("Failed to parse \"%s\" as float32", errStr)
 	}
@@ -259,9 +448,15 @@ func (c Card) ParseFloat32Width(valueWidth int) (float32, error) {
 }
 
 func (c Card) ParseFloat64() (float64, error) {
-	floatC := C.card_parse_float64(c.handle)
+	handle, err := c.cHandle()
+	if err != nil {
+		return 0, err
+	}
+	defer runtime.KeepAlive(c.h)
+
+	floatC := C.card_parse_float64(handle)
 	if C.get_errno() != 0 {
-		errStr := c.currentString()
+		errStr := cardCurrentString(handle)
 
 		return 0, fmt.Errorf("Failed to parse \"%s\" as float64", errStr)
 	}
@@ -269,9 +464,15 @@ func (c Card) ParseFloat64() (float64, error) {
 }
 
 func (c Card) ParseFloat64Width(valueWidth int) (float64, error) {
-	floatC := C.card_parse_float64_width(c.handle, C.uint8_t(valueWidth))
+	handle, err := c.cHandle()
+	if err != nil {
+		return 0, err
+	}
+	defer runtime.KeepAlive(c.h)
+
+	floatC := C.card_parse_float64_width(handle, C.uint8_t(valueWidth))
 	if C.get_errno() != 0 {
-		errStr := c.currentStringWidth(valueWidth)
+		errStr := cardCurrentStringWidth(handle, valueWidth)
 
 		return 0, fmt.Errorf("Failed to parse \"%s\" as float64", errStr)
 	}
@@ -279,64 +480,95 @@ func (c Card) ParseFloat64Width(valueWidth int) (float64, error) {
 }
 
 func (c Card) ParseString() string {
-	strC := C.card_parse_string(c.handle)
-	str := C.GoString(strC)
-	C.free(unsafe.Pointer(strC))
-	return str
+	return c.parseString(func(handle *C.card_t) *C.char {
+		return C.card_parse_string(handle)
+	})
 }
 
 func (c Card) ParseStringWidth(valueWidth int) string {
-	strC := C.card_parse_string_width(c.handle, C.uint8_t(valueWidth))
-	str := C.GoString(strC)
-	C.free(unsafe.Pointer(strC))
-	return str
+	return c.parseString(func(handle *C.card_t) *C.char {
+		return C.card_parse_string_width(handle, C.uint8_t(valueWidth))
+	})
 }
 
 func (c Card) ParseStringNoTrim() string {
-	strC := C.card_parse_string_no_trim(c.handle)
-	str := C.GoString(strC)
-	C.free(unsafe.Pointer(strC))
-	return str
+	return c.parseString(func(handle *C.card_t) *C.char {
+		return C.card_parse_string_no_trim(handle)
+	})
 }
 
 func (c Card) ParseStringWidthNoTrim(valueWidth int) string {
-	strC := C.card_parse_string_width_no_trim(c.handle, C.uint8_t(valueWidth))
-	str := C.GoString(strC)
-	C.free(unsafe.Pointer(strC))
-	return str
+	return c.parseString(func(handle *C.card_t) *C.char {
+		return C.card_parse_string_width_no_trim(handle, C.uint8_t(valueWidth))
+	})
 }
 
 func (c Card) ParseWhole() string {
-	strC := C.card_parse_whole(c.handle)
-	str := C.GoString(strC)
-	C.free(unsafe.Pointer(strC))
-	return str
+	return c.parseString(func(handle *C.card_t) *C.char {
+		return C.card_parse_whole(handle)
+	})
 }
 
 func (c Card) ParseWholeNoTrim() string {
-	strC := C.card_parse_whole_no_trim(c.handle)
+	return c.parseString(func(handle *C.card_t) *C.char {
+		return C.card_parse_whole_no_trim(handle)
+	})
+}
+
+// parseString converts and frees the string returned by parse. It returns ""
+// if the keywords have been freed.
+func (c Card) parseString(parse func(*C.card_t) *C.char) string {
+	handle, err := c.cHandle()
+	if err != nil {
+		return ""
+	}
+	defer runtime.KeepAlive(c.h)
+
+	strC := parse(handle)
 	str := C.GoString(strC)
 	C.free(unsafe.Pointer(strC))
 	return str
 }
 
 func (c Card) ParseGetType() int {
-	return int(C.card_parse_get_type(c.handle))
+	handle, err := c.cHandle()
+	if err != nil {
+		return CardParseString
+	}
+	defer runtime.KeepAlive(c.h)
+	return int(C.card_parse_get_type(handle))
 }
 
 func (c Card) ParseGetTypeWidth(valueWidth int) int {
-	return int(C.card_parse_get_type_width(c.handle, C.uint8_t(valueWidth)))
+	handle, err := c.cHandle()
+	if err != nil {
+		return CardParseString
+	}
+	defer runtime.KeepAlive(c.h)
+	return int(C.card_parse_get_type_width(handle, C.uint8_t(valueWidth)))
 }
 
 func (c Card) TryParseInt(value *int) {
+	handle, err := c.cHandle()
+	if err != nil {
+		return
+	}
+	defer runtime.KeepAlive(c.h)
+
 	valueC := C.int64_t(*value)
-	C._card_try_parse_int(c.handle, &valueC)
+	C._card_try_parse_int(handle, &valueC)
 	*value = int(valueC)
 }
 
 func (c Card) TryParseFloat64(value *float64) {
+	handle, err := c.cHandle()
+	if err != nil {
+		return
+	}
+	defer runtime.KeepAlive(c.h)
+
 	valueC := C.double(*value)
-	C._card_try_parse_float64(c.handle, &valueC)
+	C._card_try_parse_float64(handle, &valueC)
 	*value = float64(valueC)
 }
 
@@ -385,12 +617,33 @@ var keyFileCallbacks map[uintptr]KeyFileParseCallback
 var keyFileCallbacksMtx sync.Mutex
 
 func KeyFileParseWithCallback(fileName string, callback KeyFileParseCallback, parseConfig KeyFileParseConfig) (*KeyFileWarning, error) {
+	return KeyFileParseWithCallbackContext(context.Background(), fileName, callback, parseConfig)
+}
+
+// KeyFileParseWithCallbackContext is the same as KeyFileParseWithCallback, but
+// stops parsing at the next keyword or include file as soon as ctx is done and
+// returns ctx.Err(). callback is not called anymore once ctx is done.
+func KeyFileParseWithCallbackContext(ctx context.Context, fileName string, callback KeyFileParseCallback, parseConfig KeyFileParseConfig) (*KeyFileWarning, error) {
 	fileNameC := C.CString(fileName)
-	var errorString *C.char
+	var cErrors *C.key_parse_error_t
+	var numErrors C.size_t
 	var warningString *C.char
 	var warning *KeyFileWarning
 
 	cParseConfig := parseConfig.toC()
+	interrupt := newKeyFileInterrupt(ctx, &cParseConfig)
+	defer interrupt.stop()
+
+	if interrupt.flag != nil {
+		userCallback := callback
+		callback = func(info KeyParseInfo, keywordName string, card *Card, cardIndex int) {
+			if ctx.Err() != nil {
+				interrupt.set()
+				return
+			}
+			userCallback(info, keywordName, card, cardIndex)
+		}
+	}
 
 	keyFileCallbacksMtx.Lock()
 	if keyFileCallbacks == nil {
@@ -410,13 +663,13 @@ func KeyFileParseWithCallback(fileName string, callback KeyFileParseCallback, pa
 		delete(keyFileCallbacks, callbackIndex)
 	}()
 
-	C.key_file_parse_with_callback(fileNameC,
+	C.key_file_parse_with_callback2(fileNameC,
 		C.key_file_callback(C.keyFileParseGoCallback),
 		&cParseConfig,
-		&errorString,
+		&cErrors,
+		&numErrors,
 		&warningString,
 		unsafe.Pointer(callbackIndex),
-		nil,
 	)
 	C.free(unsafe.Pointer(fileNameC))
 
@@ -434,10 +687,11 @@ func KeyFileParseWithCallback(fileName string, callback KeyFileParseCallback, pa
 		C.free(unsafe.Pointer(warningString))
 	}
 
-	if errorString != nil {
-		errStr := C.GoString(errorString)
-		C.free(unsafe.Pointer(errorString))
-		return warning, errors.New(errStr)
+	if err := keyFileError(cErrors, numErrors); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return warning, ctxErr
+		}
+		return warning, err
 	}
 
 	return warning, nil
@@ -468,7 +722,13 @@ func keyFileParseGoCallback(infoC C.key_parse_info_t, keywordNameC *C.char, card
 }
 
 func KeyParseIncludeTransform(kw Keyword) IncludeTransform {
-	handle := C.key_parse_include_transform(kw.handle)
+	kwHandle, err := kw.cHandle()
+	if err != nil {
+		return IncludeTransform{}
+	}
+	defer runtime.KeepAlive(kw.h)
+
+	handle := C.key_parse_include_transform(kwHandle)
 	return IncludeTransform{handle}
 }
 
@@ -477,7 +737,10 @@ func (it *IncludeTransform) Free() {
 }
 
 func (it *IncludeTransform) ParseCard(card Card, cardIndex int) {
-	C.key_parse_include_transform_card(&it.handle, card.handle, C.size_t(cardIndex))
+	if cardHandle, err := card.cHandle(); err == nil {
+		C.key_parse_include_transform_card(&it.handle, cardHandle, C.size_t(cardIndex))
+		runtime.KeepAlive(card.h)
+	}
 }
 
 func (it IncludeTransform) FileName() string {
@@ -550,7 +813,13 @@ func KeyParseDefineTransformation(kw Keyword, isTitle bool) DefineTransformation
 		isTitleC = 1
 	}
 
-	handle := C.key_parse_define_transformation(kw.handle, isTitleC)
+	kwHandle, err := kw.cHandle()
+	if err != nil {
+		return DefineTransformation{}
+	}
+	defer runtime.KeepAlive(kw.h)
+
+	handle := C.key_parse_define_transformation(kwHandle, isTitleC)
 	return DefineTransformation{handle}
 }
 
@@ -563,7 +832,10 @@ func (dt *DefineTransformation) ParseCard(card Card, cardIndex int, isTitle bool
 	if isTitle {
 		isTitleC = 1
 	}
-	C.key_parse_define_transformation_card(&dt.handle, card.handle, C.size_t(cardIndex), isTitleC)
+	if cardHandle, err := card.cHandle(); err == nil {
+		C.key_parse_define_transformation_card(&dt.handle, cardHandle, C.size_t(cardIndex), isTitleC)
+		runtime.KeepAlive(card.h)
+	}
 }
 
 func (dt DefineTransformation) Tranid() int {
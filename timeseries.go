@@ -0,0 +1,258 @@
+package dynareadout
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"sort"
+)
+
+const (
+	InterpolationLinear = iota
+	InterpolationZeroOrderHold
+)
+
+const (
+	D3plotNodeCoordinates = iota
+	D3plotNodeVelocity
+	D3plotNodeAcceleration
+)
+
+// TimeSeries holds the values of one channel over time. Time needs to be
+// sorted in ascending order
+type TimeSeries struct {
+	Time   []float64
+	Values []float64
+	Label  string
+	// Unit is the unit of the values or empty if it is unknown
+	Unit string
+}
+
+// ReadSeries reads a timed variable (as accepted by SimplePathToReal) together
+// with the time variable of its database. One series is returned for every
+// value of the variable (e.g. for every node of nodout). The series are
+// labeled with the ids and titles of the metadata if they exist. The unit is
+// read from metadata/units of the database if it exists.
+func (bin_file Binout) ReadSeries(simplePath string) ([]TimeSeries, error) {
+	realPath, _, timed, err := bin_file.SimplePathToReal(simplePath)
+	if err != nil {
+		return nil, err
+	}
+	if !timed {
+		return nil, fmt.Errorf("The variable \"%s\" is not timed", simplePath)
+	}
+
+	dbPath, variable := path.Split(realPath)
+	db, err := bin_file.openDatabase(path.Clean(dbPath))
+	if err != nil {
+		return nil, err
+	}
+
+	time, err := db.Time()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := db.ReadVariable(variable)
+	if err != nil {
+		return nil, err
+	}
+
+	var numValues int
+	if len(data) != 0 {
+		numValues = len(data[0])
+	}
+
+	unit, err := db.unit()
+	if err != nil {
+		return nil, err
+	}
+
+	labels := db.valueLabels(variable, numValues)
+	series := make([]TimeSeries, numValues)
+	for i := range series {
+		series[i].Time = time
+		series[i].Label = labels[i]
+		series[i].Unit = unit
+		series[i].Values, err = binoutColumn(data, i, realPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return series, nil
+}
+
+// ReadNodeSeries reads the history of one node over all states of the d3plot.
+// quantity is one of D3plotNodeCoordinates, D3plotNodeVelocity or
+// D3plotNodeAcceleration. One series is returned per component (x, y, z).
+func (plotFile D3plot) ReadNodeSeries(nodeIndex uint64, quantity int) ([3]TimeSeries, error) {
+	var series [3]TimeSeries
+
+	var read func(uint64) ([][3]float64, error)
+	var name string
+	switch quantity {
+	case D3plotNodeCoordinates:
+		read, name = plotFile.ReadNodeCoordinates, "coordinate"
+	case D3plotNodeVelocity:
+		read, name = plotFile.ReadNodeVelocity, "velocity"
+	case D3plotNodeAcceleration:
+		read, name = plotFile.ReadNodeAcceleration, "acceleration"
+	default:
+		return series, fmt.Errorf("Invalid node quantity %d", quantity)
+	}
+
+	time, err := plotFile.ReadAllTime()
+	if err != nil {
+		return series, err
+	}
+
+	for i, axis := range [3]string{"x", "y", "z"} {
+		series[i].Time = time
+		series[i].Values = make([]float64, len(time))
+		series[i].Label = fmt.Sprintf("%s_%s %d", axis, name, nodeIndex)
+	}
+
+	for state := range time {
+		values, err := read(uint64(state))
+		if err != nil {
+			return series, err
+		}
+		if nodeIndex >= uint64(len(values)) {
+			return series, fmt.Errorf("The node index %d is out of range (%d nodes)", nodeIndex, len(values))
+		}
+
+		for i := range series {
+			series[i].Values[state] = values[nodeIndex][i]
+		}
+	}
+
+	return series, nil
+}
+
+// UniformTime returns the time points start, start+step, ... up to and
+// including end
+func UniformTime(start, end, step float64) []float64 {
+	if step <= 0 || end < start {
+		return []float64{}
+	}
+
+	n := int(math.Floor((end-start)/step+1e-9)) + 1
+	time := make([]float64, n)
+	for i := range time {
+		time[i] = start + float64(i)*step
+	}
+	return time
+}
+
+func (ts TimeSeries) Len() int {
+	return len(ts.Time)
+}
+
+// At returns the value at time t. Values outside of the time range are clamped
+// to the first or last value.
+func (ts TimeSeries) At(t float64, interpolation int) float64 {
+	if len(ts.Time) == 0 {
+		return math.NaN()
+	}
+	if t <= ts.Time[0] {
+		return ts.Values[0]
+	}
+	if t >= ts.Time[len(ts.Time)-1] {
+		return ts.Values[len(ts.Values)-1]
+	}
+
+	// The index of the first time point which is greater than t
+	i := sort.Search(len(ts.Time), func(i int) bool { return ts.Time[i] > t })
+	t0, t1 := ts.Time[i-1], ts.Time[i]
+	v0, v1 := ts.Values[i-1], ts.Values[i]
+
+	if interpolation == InterpolationZeroOrderHold || t1 == t0 {
+		return v0
+	}
+
+	return v0 + (v1-v0)*(t-t0)/(t1-t0)
+}
+
+// Resample returns the series evaluated at the given time points
+func (ts TimeSeries) Resample(time []float64, interpolation int) TimeSeries {
+	rv := TimeSeries{
+		Time:   append([]float64(nil), time...),
+		Values: make([]float64, len(time)),
+		Label:  ts.Label,
+		Unit:   ts.Unit,
+	}
+
+	for i, t := range time {
+		rv.Values[i] = ts.At(t, interpolation)
+	}
+
+	return rv
+}
+
+// ResampleUniform resamples the series onto a uniform time base with the given
+// step covering the time range of the series
+func (ts TimeSeries) ResampleUniform(step float64, interpolation int) TimeSeries {
+	if len(ts.Time) == 0 {
+		return ts.Resample(nil, interpolation)
+	}
+	return ts.Resample(UniformTime(ts.Time[0], ts.Time[len(ts.Time)-1], step), interpolation)
+}
+
+// Window returns the part of the series with start <= time <= end
+func (ts TimeSeries) Window(start, end float64) TimeSeries {
+	first := sort.SearchFloat64s(ts.Time, start)
+	last := sort.Search(len(ts.Time), func(i int) bool { return ts.Time[i] > end })
+	if last < first {
+		last = first
+	}
+
+	return TimeSeries{
+		Time:   append([]float64(nil), ts.Time[first:last]...),
+		Values: append([]float64(nil), ts.Values[first:last]...),
+		Label:  ts.Label,
+		Unit:   ts.Unit,
+	}
+}
+
+// AlignSeries resamples all series onto a common time base. The time base
+// consists of all time points of all series inside of the time range which is
+// covered by every series.
+func AlignSeries(interpolation int, series ...TimeSeries) []TimeSeries {
+	if len(series) == 0 {
+		return []TimeSeries{}
+	}
+
+	start, end := math.Inf(-1), math.Inf(1)
+	for _, ts := range series {
+		if len(ts.Time) == 0 {
+			start, end = 0, -1
+			break
+		}
+		start = math.Max(start, ts.Time[0])
+		end = math.Min(end, ts.Time[len(ts.Time)-1])
+	}
+
+	var time []float64
+	for _, ts := range series {
+		for _, t := range ts.Time {
+			if t >= start && t <= end {
+				time = append(time, t)
+			}
+		}
+	}
+	sort.Float64s(time)
+
+	unique := time[:0]
+	for i, t := range time {
+		if i == 0 || t != time[i-1] {
+			unique = append(unique, t)
+		}
+	}
+
+	aligned := make([]TimeSeries, len(series))
+	for i, ts := range series {
+		aligned[i] = ts.Resample(unique, interpolation)
+	}
+	return aligned
+}